@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+
 	"github.com/itmosha/vk-internship-2024/internal/app"
 	"github.com/itmosha/vk-internship-2024/internal/config"
 )
@@ -12,5 +14,8 @@ import (
 // @SecurityScheme JWT http bearer Your JWT token
 func main() {
 	cfg := config.NewConfig()
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Exit(app.RunImport(cfg, os.Args[2:]))
+	}
 	app.Run(cfg)
 }
@@ -2,15 +2,18 @@ module github.com/itmosha/vk-internship-2024
 
 go 1.21
 
-require github.com/joho/godotenv v1.5.1
+require (
+	github.com/andybalholm/brotli v1.1.1
+	github.com/golang-jwt/jwt v3.2.2+incompatible
+	github.com/joho/godotenv v1.5.1
+	github.com/lib/pq v1.10.9
+)
 
 require (
 	github.com/cpuguy83/go-md2man/v2 v2.0.0-20190314233015-f79a8a8ca69d // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/ghodss/yaml v1.0.0 // indirect
-	github.com/golang-jwt/jwt v3.2.2+incompatible // indirect
 	github.com/iancoleman/orderedmap v0.2.0 // indirect
-	github.com/lib/pq v1.10.9 // indirect
 	github.com/parvez3019/go-swagger3 v0.0.0-20231114170428-c3110bd25acf // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/russross/blackfriday/v2 v2.0.1 // indirect
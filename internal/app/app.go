@@ -7,8 +7,11 @@ import (
 	"github.com/itmosha/vk-internship-2024/internal/config"
 	"github.com/itmosha/vk-internship-2024/internal/handler"
 	"github.com/itmosha/vk-internship-2024/internal/http_server"
+	"github.com/itmosha/vk-internship-2024/internal/http_server/middleware"
+	"github.com/itmosha/vk-internship-2024/internal/repo/cache"
 	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
 	"github.com/itmosha/vk-internship-2024/internal/usecase"
+	"github.com/itmosha/vk-internship-2024/pkg/blobstorage"
 	"github.com/itmosha/vk-internship-2024/pkg/logger"
 	"github.com/itmosha/vk-internship-2024/pkg/postgres"
 )
@@ -24,29 +27,80 @@ func Run(cfg *config.Config) {
 	// Setup logger
 	logger := logger.NewLogger("logs/logs.txt", cfg.Env)
 
-	// Create repos
-	filmRepo := repo.NewFilmRepoPostgres(pg)
-	actorRepo := repo.NewActorRepoPostgres(pg)
-	filmsActorsRepo := repo.NewFilmsActorsRepoPostgres(pg)
+	// Create repos, film and actor lists are read through the cache
+	readCache := cache.NewReadCache(cfg.Cache.Enabled, cfg.Cache.Size, cfg.Cache.TTL)
+	filmRepo := cache.NewFilmRepoCache(repo.NewFilmRepoPostgres(pg), readCache)
+	actorRepo := cache.NewActorRepoCache(repo.NewActorRepoPostgres(pg), readCache)
+	filmsActorsRepo := cache.NewFilmsActorsRepoCache(repo.NewFilmsActorsRepoPostgres(pg), readCache)
 	userRepo := repo.NewUserRepoPostgres(pg)
+	reviewRepo := repo.NewReviewRepoPostgres(pg)
+	watchlistRepo := repo.NewWatchlistRepoPostgres(pg)
+	collectionRepo := repo.NewCollectionRepoPostgres(pg)
+	revisionRepo := repo.NewRevisionRepoPostgres(pg)
+	importRepo := cache.NewImportRepoCache(repo.NewImportRepoPostgres(pg), readCache)
+	exportRepo := repo.NewExportRepoPostgres(pg)
+	searchRepo := repo.NewSearchRepoPostgres(pg)
+	translationRepo := repo.NewTranslationRepoPostgres(pg)
+	releaseRepo := repo.NewReleaseRepoPostgres(pg)
+	relationRepo := repo.NewRelationRepoPostgres(pg)
+	franchiseRepo := repo.NewFranchiseRepoPostgres(pg)
+	costarRepo := repo.NewCostarRepoPostgres(pg)
+	recommendationRepo := repo.NewRecommendationRepoPostgres(pg)
+	statsRepo := repo.NewStatsRepoPostgres(pg)
 
 	// Create usecases
-	filmUsecase := usecase.NewFilmUsecase(filmRepo, actorRepo, filmsActorsRepo)
-	actorUsecase := usecase.NewActorUsecase(actorRepo, filmsActorsRepo)
+	filmUsecase := usecase.NewFilmUsecase(filmRepo, actorRepo, filmsActorsRepo, revisionRepo, translationRepo, releaseRepo, relationRepo, franchiseRepo)
+	actorUsecase := usecase.NewActorUsecase(actorRepo, filmsActorsRepo, revisionRepo, translationRepo)
 	userUsecase := usecase.NewUserUsecase(userRepo)
+	reviewUsecase := usecase.NewReviewUsecase(reviewRepo, filmRepo)
+	watchlistUsecase := usecase.NewWatchlistUsecase(watchlistRepo)
+	collectionUsecase := usecase.NewCollectionUsecase(collectionRepo, filmRepo)
+	importUsecase := usecase.NewImportUsecase(importRepo, filmRepo, actorRepo, revisionRepo)
+	exportUsecase := usecase.NewExportUsecase(exportRepo)
+	searchUsecase := usecase.NewSearchUsecase(searchRepo)
+	mediaUsecase := usecase.NewMediaUsecase(filmRepo, actorRepo, revisionRepo, newBlobStorage(cfg.Media), cfg.Media.MaxUploadSize)
+	translationUsecase := usecase.NewTranslationUsecase(translationRepo, filmRepo, actorRepo)
+	releaseUsecase := usecase.NewReleaseUsecase(releaseRepo, filmRepo)
+	relationUsecase := usecase.NewRelationUsecase(relationRepo, filmRepo)
+	franchiseUsecase := usecase.NewFranchiseUsecase(franchiseRepo, filmRepo)
+	costarUsecase := usecase.NewCostarUsecase(costarRepo, actorRepo, filmRepo)
+	recommendationUsecase := usecase.NewRecommendationUsecase(recommendationRepo, filmRepo)
+	statsUsecase := usecase.NewStatsUsecase(statsRepo)
+	cacheUsecase := usecase.NewCacheUsecase(readCache)
+
+	// Run background jobs
+	go runTrashPurgeJob(cfg.Trash, filmUsecase, actorUsecase)
+	go runSimilarityRefreshJob(cfg.Recommendations, recommendationUsecase)
+	go runStatsRefreshJob(cfg.Stats, statsUsecase)
 
 	// Create handlers
 	filmHandler := handler.NewFilmHander(filmUsecase, logger)
 	actorHandler := handler.NewActorHandler(actorUsecase, logger)
 	userHandler := handler.NewUserHandler(userUsecase, logger)
+	reviewHandler := handler.NewReviewHandler(reviewUsecase, logger)
+	watchlistHandler := handler.NewWatchlistHandler(watchlistUsecase, logger)
+	collectionHandler := handler.NewCollectionHandler(collectionUsecase, logger)
+	importHandler := handler.NewImportHandler(importUsecase, logger)
+	exportHandler := handler.NewExportHandler(exportUsecase, logger)
+	searchHandler := handler.NewSearchHandler(searchUsecase, logger)
+	mediaHandler := handler.NewMediaHandler(mediaUsecase, logger)
+	translationHandler := handler.NewTranslationHandler(translationUsecase, logger)
+	releaseHandler := handler.NewReleaseHandler(releaseUsecase, logger)
+	relationHandler := handler.NewRelationHandler(relationUsecase, logger)
+	franchiseHandler := handler.NewFranchiseHandler(franchiseUsecase, logger)
+	costarHandler := handler.NewCostarHandler(costarUsecase, logger)
+	recommendationHandler := handler.NewRecommendationHandler(recommendationUsecase, logger)
+	statsHandler := handler.NewStatsHandler(statsUsecase, logger)
+	cacheHandler := handler.NewCacheHandler(cacheUsecase, logger)
+	graphqlHandler := handler.NewGraphQLHandler(filmUsecase, actorUsecase, watchlistUsecase, logger)
 
 	// Setup router
-	router := http_server.NewRouter(filmHandler, actorHandler, userHandler)
+	router := http_server.NewRouter(filmHandler, actorHandler, userHandler, reviewHandler, watchlistHandler, collectionHandler, importHandler, exportHandler, searchHandler, mediaHandler, translationHandler, releaseHandler, relationHandler, franchiseHandler, costarHandler, recommendationHandler, statsHandler, cacheHandler, graphqlHandler, cfg.HTTPServer.CachePolicies)
 
 	// Run server
 	s := &http.Server{
 		Addr:           ":" + cfg.HTTPServer.RunPort,
-		Handler:        router,
+		Handler:        middleware.CompressionMiddleware(router),
 		ReadTimeout:    cfg.HTTPServer.Timeout,
 		WriteTimeout:   cfg.HTTPServer.Timeout,
 		IdleTimeout:    cfg.HTTPServer.IdleTimeout,
@@ -55,3 +109,17 @@ func Run(cfg *config.Config) {
 	log.Printf("starting http server on port %s", cfg.HTTPServer.RunPort)
 	log.Fatal(s.ListenAndServe())
 }
+
+// Create blob storage for uploaded images configured by MEDIA_STORAGE.
+func newBlobStorage(cfg config.Media) (storage usecase.BlobStorageInterface) {
+	var err error
+	if cfg.Storage == "s3" {
+		storage, err = blobstorage.NewS3Storage(cfg.S3.Endpoint, cfg.S3.Region, cfg.S3.Bucket, cfg.S3.AccessKey, cfg.S3.SecretKey, cfg.S3.PublicURL)
+	} else {
+		storage, err = blobstorage.NewLocalStorage(cfg.Dir, cfg.BaseURL)
+	}
+	if err != nil {
+		log.Fatalf("could not create blob storage: %s\n", err)
+	}
+	return
+}
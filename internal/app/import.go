@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/itmosha/vk-internship-2024/internal/config"
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
+	"github.com/itmosha/vk-internship-2024/internal/usecase"
+	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+)
+
+// Import films or actors from a file, print the report to stdout and return exit code.
+// Exit code is 1 if any row failed and 2 if arguments are invalid.
+func RunImport(cfg *config.Config, args []string) (exitCode int) {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	entityName := flags.String("entity", "", "entity to import: films or actors")
+	format := flags.String("format", "", "input format: csv or ndjson, detected from file extension if omitted")
+	path := flags.String("file", "-", "path to input file, - for standard input")
+	dryRun := flags.Bool("dry-run", false, "validate and report without saving anything")
+	ignoreIDs := flags.Bool("ignore-ids", false, "create every row as a new entity and match cast by actors' names")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *format == "" {
+		switch strings.ToLower(filepath.Ext(*path)) {
+		case ".csv":
+			*format = entity.ImportFormatCSV
+		case ".ndjson", ".jsonl":
+			*format = entity.ImportFormatNDJSON
+		}
+	}
+	if *format != entity.ImportFormatCSV && *format != entity.ImportFormatNDJSON {
+		fmt.Fprintln(os.Stderr, entity.ErrInvalidImportFormat)
+		return 2
+	}
+
+	var input io.Reader = os.Stdin
+	if *path != "-" {
+		file, err := os.Open(*path)
+		if err != nil {
+			log.Printf("could not open input file: %s\n", err)
+			return 2
+		}
+		defer file.Close()
+		input = file
+	}
+
+	pg, err := postgres.NewPostgres(cfg.DB.Address, cfg.DB.User, cfg.DB.Password, cfg.DB.Name)
+	if err != nil {
+		log.Fatalf("could not create postgres connection: %s\n", err)
+	}
+	defer pg.Close()
+	importUsecase := usecase.NewImportUsecase(
+		repo.NewImportRepoPostgres(pg),
+		repo.NewFilmRepoPostgres(pg),
+		repo.NewActorRepoPostgres(pg),
+		repo.NewRevisionRepoPostgres(pg),
+	)
+
+	ctx := context.Background()
+	params := &entity.ImportParams{Format: *format, DryRun: *dryRun, IgnoreIDs: *ignoreIDs}
+	var report *entity.ImportReport
+	switch *entityName {
+	case "actors":
+		report, err = importUsecase.ImportActors(&ctx, params, input, 0)
+	case "films":
+		report, err = importUsecase.ImportFilms(&ctx, params, input, 0)
+	default:
+		fmt.Fprintln(os.Stderr, "entity must be one of: films, actors")
+		return 2
+	}
+	if err != nil {
+		log.Printf("could not import %s: %s\n", *entityName, err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/itmosha/vk-internship-2024/internal/config"
+	"github.com/itmosha/vk-internship-2024/internal/usecase"
+)
+
+// Periodically purge films and actors that are in trash for longer than retention period.
+func runTrashPurgeJob(cfg config.Trash, filmUsecase *usecase.FilmUsecase, actorUsecase *usecase.ActorUsecase) {
+	ticker := time.NewTicker(cfg.PurgeInterval)
+	defer ticker.Stop()
+	for ; true; <-ticker.C {
+		ctx := context.Background()
+		cntFilms, err := filmUsecase.PurgeTrash(&ctx, cfg.RetentionPeriod)
+		if err != nil {
+			log.Printf("could not purge films from trash: %s\n", err)
+		}
+		cntActors, err := actorUsecase.PurgeTrash(&ctx, cfg.RetentionPeriod)
+		if err != nil {
+			log.Printf("could not purge actors from trash: %s\n", err)
+		}
+		if cntFilms > 0 || cntActors > 0 {
+			log.Printf("purged %d films and %d actors from trash\n", cntFilms, cntActors)
+		}
+	}
+}
+
+// Periodically recompute rating-based similarity of films used for personal recommendations.
+func runSimilarityRefreshJob(cfg config.Recommendations, recommendationUsecase *usecase.RecommendationUsecase) {
+	ticker := time.NewTicker(cfg.RefreshInterval)
+	defer ticker.Stop()
+	for ; true; <-ticker.C {
+		ctx := context.Background()
+		cntPairs, err := recommendationUsecase.RefreshSimilarities(&ctx)
+		if err != nil {
+			log.Printf("could not refresh film similarities: %s\n", err)
+			continue
+		}
+		log.Printf("refreshed film similarities, %d pairs of similar films\n", cntPairs)
+	}
+}
+
+// Periodically refresh aggregates of catalog stats reports.
+func runStatsRefreshJob(cfg config.Stats, statsUsecase *usecase.StatsUsecase) {
+	ticker := time.NewTicker(cfg.RefreshInterval)
+	defer ticker.Stop()
+	for ; true; <-ticker.C {
+		ctx := context.Background()
+		if err := statsUsecase.Refresh(&ctx); err != nil {
+			log.Printf("could not refresh stats: %s\n", err)
+		}
+	}
+}
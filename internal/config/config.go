@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -13,11 +14,18 @@ type (
 		Env string `env:"ENV"`
 		HTTPServer
 		DB
+		Trash
+		Media
+		Recommendations
+		Stats
+		Cache
 	}
 	HTTPServer struct {
 		RunPort     string `env:"RUN_PORT"`
 		Timeout     time.Duration
 		IdleTimeout time.Duration
+		// Cache-Control of public reads by route.
+		CachePolicies map[string]string
 	}
 	DB struct {
 		Address  string `env:"POSTGRES_ADDRESS"`
@@ -25,6 +33,36 @@ type (
 		Name     string `env:"POSTGRES_NAME"`
 		Password string `env:"POSTGRES_PASSWORD"`
 	}
+	Trash struct {
+		RetentionPeriod time.Duration `env:"TRASH_RETENTION_DAYS"`
+		PurgeInterval   time.Duration
+	}
+	Media struct {
+		Storage       string `env:"MEDIA_STORAGE"`
+		Dir           string `env:"MEDIA_DIR"`
+		BaseURL       string `env:"MEDIA_BASE_URL"`
+		MaxUploadSize int64  `env:"MEDIA_MAX_UPLOAD_MB"`
+		S3
+	}
+	Recommendations struct {
+		RefreshInterval time.Duration `env:"RECOMMENDATIONS_REFRESH_MINUTES"`
+	}
+	Stats struct {
+		RefreshInterval time.Duration `env:"STATS_REFRESH_MINUTES"`
+	}
+	Cache struct {
+		Enabled bool          `env:"CACHE_ENABLED"`
+		Size    int           `env:"CACHE_SIZE"`
+		TTL     time.Duration `env:"CACHE_TTL_SECONDS"`
+	}
+	S3 struct {
+		Endpoint  string `env:"S3_ENDPOINT"`
+		Region    string `env:"S3_REGION"`
+		Bucket    string `env:"S3_BUCKET"`
+		AccessKey string `env:"S3_ACCESS_KEY"`
+		SecretKey string `env:"S3_SECRET_KEY"`
+		PublicURL string `env:"S3_PUBLIC_URL"`
+	}
 )
 
 // Create new app config.
@@ -39,10 +77,45 @@ func NewConfig() (cfg *Config) {
 	cfg.HTTPServer.RunPort = readEnvVar("RUN_PORT")
 	cfg.HTTPServer.Timeout = time.Second * 5
 	cfg.HTTPServer.IdleTimeout = time.Second * 60
+	cfg.HTTPServer.CachePolicies = map[string]string{
+		"/api/films":       readEnvVarOrDefault("HTTP_CACHE_FILMS", "private, max-age=60"),
+		"/api/films/{id}":  readEnvVarOrDefault("HTTP_CACHE_FILM", "private, max-age=60"),
+		"/api/actors":      readEnvVarOrDefault("HTTP_CACHE_ACTORS", "private, max-age=60"),
+		"/api/actors/{id}": readEnvVarOrDefault("HTTP_CACHE_ACTOR", "private, max-age=60"),
+	}
 	cfg.DB.Address = readEnvVar("POSTGRES_ADDRESS")
 	cfg.DB.User = readEnvVar("POSTGRES_USER")
 	cfg.DB.Name = readEnvVar("POSTGRES_NAME")
 	cfg.DB.Password = readEnvVar("POSTGRES_PASSWORD")
+	cfg.Trash.RetentionPeriod = time.Hour * 24 * time.Duration(readIntEnvVarOrDefault("TRASH_RETENTION_DAYS", 30))
+	cfg.Trash.PurgeInterval = time.Hour
+	cfg.Recommendations.RefreshInterval = time.Minute * time.Duration(readIntEnvVarOrDefault("RECOMMENDATIONS_REFRESH_MINUTES", 60))
+	if cfg.Recommendations.RefreshInterval == 0 {
+		log.Fatalf("env variable RECOMMENDATIONS_REFRESH_MINUTES must be positive\n")
+	}
+	cfg.Stats.RefreshInterval = time.Minute * time.Duration(readIntEnvVarOrDefault("STATS_REFRESH_MINUTES", 60))
+	if cfg.Stats.RefreshInterval == 0 {
+		log.Fatalf("env variable STATS_REFRESH_MINUTES must be positive\n")
+	}
+	cfg.Cache.Enabled = readEnvVarOrDefault("CACHE_ENABLED", "true") == "true"
+	cfg.Cache.Size = readIntEnvVarOrDefault("CACHE_SIZE", 1000)
+	cfg.Cache.TTL = time.Second * time.Duration(readIntEnvVarOrDefault("CACHE_TTL_SECONDS", 30))
+	cfg.Media.Storage = readEnvVarOrDefault("MEDIA_STORAGE", "local")
+	cfg.Media.MaxUploadSize = int64(readIntEnvVarOrDefault("MEDIA_MAX_UPLOAD_MB", 10)) << 20
+	switch cfg.Media.Storage {
+	case "local":
+		cfg.Media.Dir = readEnvVarOrDefault("MEDIA_DIR", "media")
+		cfg.Media.BaseURL = readEnvVarOrDefault("MEDIA_BASE_URL", "/media")
+	case "s3":
+		cfg.Media.S3.Endpoint = readEnvVar("S3_ENDPOINT")
+		cfg.Media.S3.Region = readEnvVarOrDefault("S3_REGION", "us-east-1")
+		cfg.Media.S3.Bucket = readEnvVar("S3_BUCKET")
+		cfg.Media.S3.AccessKey = readEnvVar("S3_ACCESS_KEY")
+		cfg.Media.S3.SecretKey = readEnvVar("S3_SECRET_KEY")
+		cfg.Media.S3.PublicURL = os.Getenv("S3_PUBLIC_URL")
+	default:
+		log.Fatalf("env variable MEDIA_STORAGE must be one of: local, s3\n")
+	}
 	return
 }
 
@@ -54,3 +127,25 @@ func readEnvVar(name string) (value string) {
 	}
 	return
 }
+
+// Read optional .env variable with provided name.
+func readEnvVarOrDefault(name string, defaultValue string) (value string) {
+	value = os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	return
+}
+
+// Read optional integer .env variable with provided name.
+func readIntEnvVarOrDefault(name string, defaultValue int) (value int) {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return defaultValue
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		log.Fatalf("env variable %s must be a non-negative integer\n", name)
+	}
+	return
+}
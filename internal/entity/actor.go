@@ -1,30 +1,135 @@
 package entity
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+	"unicode/utf8"
+)
 
 // Actor entity.
 type Actor struct {
 	ID        int    `json:"id"`
 	Name      string `json:"name"`
-	Gender    bool   `json:"gender"`
-	BirthDate string `json:"birth_date"`
+	Gender    Gender `json:"gender"`
+	BirthDate Date   `json:"birth_date"`
+	// Null if the actor is alive or the date is unknown.
+	DeathDate   Date        `json:"death_date"`
+	Biography   string      `json:"biography"`
+	Birthplace  string      `json:"birthplace"`
+	Nationality string      `json:"nationality"`
+	Aliases     []string    `json:"aliases"`
+	ExternalIDs ExternalIDs `json:"external_ids"`
+	Photo       *Image      `json:"photo"`
+	Version     int         `json:"version"`
+	// Locale of translated name, absent if it is original.
+	Locale string `json:"locale,omitempty"`
 }
 
 // Actor with all films' ids where actor is present.
 // This struct is used in the API response.
 type ActorWithFilms struct {
-	ID        int    `json:"id"`
-	Name      string `json:"name"`
-	Gender    bool   `json:"gender"`
-	BirthDate string `json:"birth_date"`
-	FilmsIDs  []int  `json:"films_ids"`
+	Actor
+	FilmsIDs  []int      `json:"films_ids"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Gender of an Actor.
+type Gender string
+
+const (
+	GenderMale      Gender = "male"
+	GenderFemale    Gender = "female"
+	GenderNonBinary Gender = "non_binary"
+	GenderUnknown   Gender = "unknown"
+)
+
+// Genders that Actors can have.
+var Genders = [...]Gender{GenderMale, GenderFemale, GenderNonBinary, GenderUnknown}
+
+// Parse a gender by its name. Boolean values are accepted for clients of the former boolean gender:
+// true stands for male and false for female.
+func ParseGender(value string) (gender Gender, err error) {
+	switch value {
+	case "true":
+		return GenderMale, nil
+	case "false":
+		return GenderFemale, nil
+	}
+	for _, g := range Genders {
+		if string(g) == value {
+			return g, nil
+		}
+	}
+	return "", ErrInvalidActorGender
+}
+
+// Unmarshal a gender from its name or from a boolean, see ParseGender.
+func (g *Gender) UnmarshalJSON(data []byte) (err error) {
+	var value interface{}
+	if err = json.Unmarshal(data, &value); err != nil {
+		return
+	}
+	switch value := value.(type) {
+	case string:
+		*g, err = ParseGender(value)
+	case bool:
+		*g, err = ParseGender(fmt.Sprint(value))
+	default:
+		err = ErrInvalidActorGender
+	}
+	return
+}
+
+// External ids of an Actor by source, e.g. imdb or wikidata.
+type ExternalIDs map[string]string
+
+// Store external ids as a JSON object, nil is stored as an empty one.
+func (ids ExternalIDs) Value() (driver.Value, error) {
+	if ids == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(ids)
+	return string(data), err
+}
+
+func (ids *ExternalIDs) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return json.Unmarshal(src, ids)
+	case string:
+		return json.Unmarshal([]byte(src), ids)
+	case nil:
+		*ids = nil
+		return nil
+	}
+	return fmt.Errorf("could not scan %T into ExternalIDs", src)
 }
 
+var (
+	countryCodeRegexp      = regexp.MustCompile(`^[A-Z]{2}$`)
+	externalIDSourceRegexp = regexp.MustCompile(`^[a-z0-9_]{1,30}$`)
+)
+
+// Max number of aliases and external ids of an Actor.
+const (
+	ActorMaxAliases     = 20
+	ActorMaxExternalIDs = 10
+)
+
 // Actor create body.
 type ActorCreateBody struct {
-	Name      string `json:"name"`
-	Gender    *bool  `json:"gender"`
-	BirthDate string `json:"birth_date"`
+	Name        string            `json:"name"`
+	Gender      *Gender           `json:"gender"`
+	BirthDate   string            `json:"birth_date"`
+	DeathDate   string            `json:"death_date"`
+	Biography   string            `json:"biography"`
+	Birthplace  string            `json:"birthplace"`
+	Nationality string            `json:"nationality"`
+	Aliases     []string          `json:"aliases"`
+	ExternalIDs map[string]string `json:"external_ids"`
 }
 
 func ValidateActorCreateBody(body *ActorCreateBody) (err error) {
@@ -34,18 +139,24 @@ func ValidateActorCreateBody(body *ActorCreateBody) (err error) {
 	if body.Gender == nil {
 		return ErrInvalidActorGender
 	}
-	_, err = time.Parse("01.02.2006", body.BirthDate)
+	_, err = ParseFullDate(body.BirthDate)
 	if err != nil {
 		return ErrInvalidActorBirthDate
 	}
-	return
+	return validateActorProfile(body.BirthDate, body.DeathDate, body.Biography, body.Birthplace, body.Nationality, body.Aliases, body.ExternalIDs)
 }
 
-// Actor update body.
+// Actor update body. Empty fields are left unchanged.
 type ActorUpdateBody struct {
-	Name      string `json:"name"`
-	Gender    *bool  `json:"gender"`
-	BirthDate string `json:"birth_date"`
+	Name        string            `json:"name"`
+	Gender      *Gender           `json:"gender"`
+	BirthDate   string            `json:"birth_date"`
+	DeathDate   string            `json:"death_date"`
+	Biography   string            `json:"biography"`
+	Birthplace  string            `json:"birthplace"`
+	Nationality string            `json:"nationality"`
+	Aliases     []string          `json:"aliases"`
+	ExternalIDs map[string]string `json:"external_ids"`
 }
 
 func ValidateActorUpdateBody(body *ActorUpdateBody) (err error) {
@@ -53,19 +164,25 @@ func ValidateActorUpdateBody(body *ActorUpdateBody) (err error) {
 		return ErrInvalidActorNameLength
 	}
 	if len(body.BirthDate) > 0 {
-		_, err = time.Parse("01.02.2006", body.BirthDate)
+		_, err = ParseFullDate(body.BirthDate)
 		if err != nil {
 			return ErrInvalidActorBirthDate
 		}
 	}
-	return
+	return validateActorProfile(body.BirthDate, body.DeathDate, body.Biography, body.Birthplace, body.Nationality, body.Aliases, body.ExternalIDs)
 }
 
 // Acter replace body.
 type ActorReplaceBody struct {
-	Name      string `json:"name"`
-	Gender    *bool  `json:"gender"`
-	BirthDate string `json:"birth_date"`
+	Name        string            `json:"name"`
+	Gender      *Gender           `json:"gender"`
+	BirthDate   string            `json:"birth_date"`
+	DeathDate   string            `json:"death_date"`
+	Biography   string            `json:"biography"`
+	Birthplace  string            `json:"birthplace"`
+	Nationality string            `json:"nationality"`
+	Aliases     []string          `json:"aliases"`
+	ExternalIDs map[string]string `json:"external_ids"`
 }
 
 func ValidateActorReplaceBody(body *ActorReplaceBody) (err error) {
@@ -75,9 +192,58 @@ func ValidateActorReplaceBody(body *ActorReplaceBody) (err error) {
 	if body.Gender == nil {
 		return ErrInvalidActorGender
 	}
-	_, err = time.Parse("01.02.2006", body.BirthDate)
+	_, err = ParseFullDate(body.BirthDate)
 	if err != nil {
 		return ErrInvalidActorBirthDate
 	}
+	return validateActorProfile(body.BirthDate, body.DeathDate, body.Biography, body.Birthplace, body.Nationality, body.Aliases, body.ExternalIDs)
+}
+
+// Validate optional profile fields shared by actor bodies. Death date is compared to birth date only if both are provided.
+func validateActorProfile(birthDate, deathDate, biography, birthplace, nationality string, aliases []string, externalIDs map[string]string) (err error) {
+	if len(deathDate) > 0 {
+		death, err := ParseFullDate(deathDate)
+		if err != nil {
+			return ErrInvalidActorDeathDate
+		}
+		if birth, err := ParseFullDate(birthDate); err == nil {
+			if err = ValidateActorLifespan(birth, death); err != nil {
+				return err
+			}
+		}
+	}
+	if utf8.RuneCountInString(biography) > 5000 {
+		return ErrInvalidActorBiographyLength
+	}
+	if utf8.RuneCountInString(birthplace) > 150 {
+		return ErrInvalidActorBirthplaceLength
+	}
+	if len(nationality) > 0 && !countryCodeRegexp.MatchString(nationality) {
+		return ErrInvalidActorNationality
+	}
+	if len(aliases) > ActorMaxAliases {
+		return ErrInvalidActorAliases
+	}
+	for _, alias := range aliases {
+		if len(alias) == 0 || utf8.RuneCountInString(alias) > 100 {
+			return ErrInvalidActorAliases
+		}
+	}
+	if len(externalIDs) > ActorMaxExternalIDs {
+		return ErrInvalidActorExternalIDs
+	}
+	for source, id := range externalIDs {
+		if !externalIDSourceRegexp.MatchString(source) || len(id) == 0 || len(id) > 100 {
+			return ErrInvalidActorExternalIDs
+		}
+	}
+	return
+}
+
+// Check that an Actor did not die before being born. Unknown death date is always valid.
+func ValidateActorLifespan(birthDate, deathDate Date) (err error) {
+	if !deathDate.IsZero() && deathDate.Time.Before(birthDate.Time) {
+		return ErrActorDeathBeforeBirth
+	}
 	return
 }
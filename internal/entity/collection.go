@@ -0,0 +1,114 @@
+package entity
+
+import (
+	"regexp"
+	"time"
+)
+
+// Collection entity.
+type Collection struct {
+	ID          int       `json:"id"`
+	Slug        string    `json:"slug"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+	FilmsIDs    []int     `json:"films_ids"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Collection with all its films in collection order.
+// This struct is used in the API response.
+type CollectionWithFilms struct {
+	ID          int               `json:"id"`
+	Slug        string            `json:"slug"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Status      string            `json:"status"`
+	Films       []*FilmWithActors `json:"films"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// Collection statuses.
+const (
+	CollectionStatusDraft     = "draft"
+	CollectionStatusPublished = "published"
+)
+
+var collectionSlugRegexp = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Collection create body.
+type CollectionCreateBody struct {
+	Slug        string `json:"slug"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	FilmsIDs    []int  `json:"films_ids"`
+}
+
+func ValidateCollectionCreateBody(body *CollectionCreateBody) (err error) {
+	if len(body.Slug) == 0 || len(body.Slug) > 100 || !collectionSlugRegexp.MatchString(body.Slug) {
+		return ErrInvalidCollectionSlug
+	}
+	if len(body.Title) == 0 || len(body.Title) > 150 {
+		return ErrInvalidCollectionTitleLength
+	}
+	if len(body.Description) > 1000 {
+		return ErrInvalidCollectionDescriptionLength
+	}
+	if body.Status != CollectionStatusDraft && body.Status != CollectionStatusPublished {
+		return ErrInvalidCollectionStatus
+	}
+	return validateCollectionFilmsIDs(body.FilmsIDs)
+}
+
+// Collection update body.
+type CollectionUpdateBody struct {
+	Slug        string `json:"slug"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	FilmsIDs    []int  `json:"films_ids"`
+}
+
+func ValidateCollectionUpdateBody(body *CollectionUpdateBody) (err error) {
+	if len(body.Slug) > 100 || (len(body.Slug) > 0 && !collectionSlugRegexp.MatchString(body.Slug)) {
+		return ErrInvalidCollectionSlug
+	}
+	if len(body.Title) > 150 {
+		return ErrInvalidCollectionTitleLength
+	}
+	if len(body.Description) > 1000 {
+		return ErrInvalidCollectionDescriptionLength
+	}
+	if len(body.Status) > 0 && body.Status != CollectionStatusDraft && body.Status != CollectionStatusPublished {
+		return ErrInvalidCollectionStatus
+	}
+	return validateCollectionFilmsIDs(body.FilmsIDs)
+}
+
+// Collection replace body.
+type CollectionReplaceBody struct {
+	Slug        string `json:"slug"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	FilmsIDs    []int  `json:"films_ids"`
+}
+
+func ValidateCollectionReplaceBody(body *CollectionReplaceBody) (err error) {
+	return ValidateCollectionCreateBody((*CollectionCreateBody)(body))
+}
+
+// Check that collection films are not repeated.
+func validateCollectionFilmsIDs(filmsIDs []int) (err error) {
+	seen := make(map[int]bool, len(filmsIDs))
+	for _, id := range filmsIDs {
+		if seen[id] {
+			return ErrDuplicateFilmsIDs
+		}
+		seen[id] = true
+	}
+	return
+}
@@ -0,0 +1,31 @@
+package entity
+
+// Actor that starred in the same Films as another Actor.
+type Costar struct {
+	ID               int    `json:"id"`
+	Name             string `json:"name"`
+	SharedFilmsCount int    `json:"shared_films_count"`
+	SharedFilmsIDs   []int  `json:"shared_films_ids"`
+}
+
+// Shortest chain of Actors where every two neighbours starred in the same Film.
+type ActorPath struct {
+	// Number of Films in the chain.
+	Degrees int `json:"degrees"`
+	// Films[i] connects Actors[i] and Actors[i+1].
+	Actors []*ActorPathActor `json:"actors"`
+	Films  []*ActorPathFilm  `json:"films"`
+}
+
+type ActorPathActor struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type ActorPathFilm struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+// Max number of Films in a chain of Actors, longer chains are not searched for.
+const MaxActorPathDegrees = 6
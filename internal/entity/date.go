@@ -0,0 +1,144 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Precision of a Date.
+type DatePrecision string
+
+const (
+	DatePrecisionDay   DatePrecision = "day"
+	DatePrecisionMonth DatePrecision = "month"
+	DatePrecisionYear  DatePrecision = "year"
+)
+
+// Layouts of dates accepted from clients, ISO 8601 ones go first.
+var dateLayouts = []struct {
+	layout    string
+	precision DatePrecision
+}{
+	{"2006-01-02", DatePrecisionDay},
+	{"2006-01", DatePrecisionMonth},
+	{"2006", DatePrecisionYear},
+	{"01.02.2006", DatePrecisionDay},
+}
+
+// Calendar date, possibly partial, e.g. only a year is known for some old films.
+// Time holds the first day of the period and is stored in database as is.
+type Date struct {
+	Time      time.Time
+	Precision DatePrecision
+}
+
+// Parse a date in ISO 8601 format (2006-01-02, 2006-01 or 2006) or in legacy format 01.02.2006.
+func ParseDate(value string) (date Date, err error) {
+	for _, l := range dateLayouts {
+		if len(value) != len(l.layout) {
+			continue
+		}
+		if date.Time, err = time.Parse(l.layout, value); err == nil {
+			date.Precision = l.precision
+			return
+		}
+	}
+	return Date{}, fmt.Errorf("invalid date %q", value)
+}
+
+// Parse a date like ParseDate, but only dates with day precision are accepted.
+func ParseFullDate(value string) (date Date, err error) {
+	date, err = ParseDate(value)
+	if err == nil && date.Precision != DatePrecisionDay {
+		return Date{}, fmt.Errorf("incomplete date %q", value)
+	}
+	return
+}
+
+// Create a Date with day precision.
+func NewDate(t time.Time) Date {
+	return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), DatePrecisionDay}
+}
+
+// Check whether the date is not set.
+func (d Date) IsZero() bool {
+	return d.Time.IsZero()
+}
+
+// Get the first day after the period of the date, e.g. 2007-01-01 for 2006.
+func (d Date) End() time.Time {
+	switch d.Precision {
+	case DatePrecisionYear:
+		return d.Time.AddDate(1, 0, 0)
+	case DatePrecisionMonth:
+		return d.Time.AddDate(0, 1, 0)
+	}
+	return d.Time.AddDate(0, 0, 1)
+}
+
+// Format the date in ISO 8601 format according to its precision.
+func (d Date) String() string {
+	switch d.Precision {
+	case DatePrecisionYear:
+		return d.Time.Format("2006")
+	case DatePrecisionMonth:
+		return d.Time.Format("2006-01")
+	}
+	return d.Time.Format("2006-01-02")
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// Unmarshal a date from any format accepted by ParseDate.
+// Timestamps are accepted as well, they are stored in revisions created before dates became ISO.
+func (d *Date) UnmarshalJSON(data []byte) (err error) {
+	var value *string
+	if err = json.Unmarshal(data, &value); err != nil || value == nil {
+		return
+	}
+	if *d, err = ParseDate(*value); err == nil {
+		return
+	}
+	t, tErr := time.Parse(time.RFC3339, *value)
+	if tErr != nil {
+		return
+	}
+	*d, err = NewDate(t), nil
+	return
+}
+
+// Store the first day of the period, precision is stored separately where partial dates are allowed.
+func (d Date) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	return d.Time.Format("2006-01-02"), nil
+}
+
+// Scan a date column with day precision, precision column should be scanned after it where partial dates are allowed.
+func (d *Date) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*d = Date{}
+	case time.Time:
+		*d = NewDate(src)
+	case []byte:
+		return d.Scan(string(src))
+	case string:
+		date, err := ParseDate(src)
+		if err != nil {
+			return err
+		}
+		*d = date
+	default:
+		return fmt.Errorf("could not scan %T into Date", src)
+	}
+	return nil
+}
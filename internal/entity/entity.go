@@ -4,15 +4,77 @@ import "errors"
 
 var (
 	ErrInvalidActorNameLength = errors.New("invalid length of name field, must be of length 1 to 100")
-	ErrInvalidActorBirthDate  = errors.New("invalid format of birth_date field, must be in format 01.02.2006")
-	ErrInvalidActorGender     = errors.New("invalid value of gender field, must be boolean value")
+	ErrInvalidActorBirthDate  = errors.New("invalid format of birth_date field, must be in format 2006-01-02 or 01.02.2006")
+	ErrInvalidActorGender     = errors.New("invalid value of gender field, must be one of: male, female, non_binary, unknown")
+	ErrInvalidActorDeathDate  = errors.New("invalid format of death_date field, must be in format 2006-01-02 or 01.02.2006")
+	ErrActorDeathBeforeBirth  = errors.New("invalid value of death_date field, must not be before birth_date")
+
+	ErrInvalidActorBiographyLength  = errors.New("invalid length of biography field, must be of length 0 to 5000")
+	ErrInvalidActorBirthplaceLength = errors.New("invalid length of birthplace field, must be of length 0 to 150")
+	ErrInvalidActorNationality      = errors.New("invalid value of nationality field, must be an ISO 3166-1 alpha-2 country code")
+	ErrInvalidActorAliases          = errors.New("invalid value of aliases field, must be at most 20 names of length 1 to 100")
+	ErrInvalidActorExternalIDs      = errors.New("invalid value of external_ids field, must be at most 10 ids of length 1 to 100 by sources of lowercase letters, digits and underscores")
 
 	ErrInvalidFilmTitleLength       = errors.New("invalid length of title field, must be of length 1 to 150")
 	ErrInvalidFilmDescriptionLength = errors.New("invalid length of description field, must be of length 1 to 1000")
-	ErrInvalidFilmReleaseDate       = errors.New("invalid format of release_date field, must be in format 01.02.2006")
+	ErrInvalidFilmReleaseDate       = errors.New("invalid format of release_date field, must be in format 2006-01-02, 2006-01, 2006 or 01.02.2006")
 	ErrInvalidFilmRating            = errors.New("invalid value of rating field, must be in range 0 to 10")
 
+	ErrInvalidFilmSortField   = errors.New("invalid sort field, should be one of: title, rating, release_date, user_rating_avg, user_rating_count, actors_count, relevance, optionally prefixed with - for descending order")
+	ErrDuplicateFilmSortField = errors.New("sort field is used more than once")
+	ErrTooManyFilmSortFields  = errors.New("too many sort fields, at most 5 are allowed")
+
+	ErrInvalidReviewScore      = errors.New("invalid value of score field, must be in range 0 to 10")
+	ErrInvalidReviewTextLength = errors.New("invalid length of text field, must be of length 0 to 5000")
+
+	ErrInvalidFilmID    = errors.New("invalid value of film_id field, must be positive integer")
+	ErrInvalidWatchedOn = errors.New("invalid format of watched_on field, must be in format 2006-01-02 or 01.02.2006")
+
+	ErrInvalidCollectionSlug              = errors.New("invalid value of slug field, must be of length 1 to 100 and contain only lowercase letters, digits and hyphens")
+	ErrInvalidCollectionTitleLength       = errors.New("invalid length of title field, must be of length 1 to 150")
+	ErrInvalidCollectionDescriptionLength = errors.New("invalid length of description field, must be of length 0 to 1000")
+	ErrInvalidCollectionStatus            = errors.New("invalid value of status field, must be one of: draft, published")
+	ErrDuplicateFilmsIDs                  = errors.New("films_ids array contains duplicates")
+
+	ErrInvalidImportFormat    = errors.New("invalid import format, should be one of: csv, ndjson")
+	ErrInvalidImportID        = errors.New("invalid value of id field, must be positive integer")
+	ErrInvalidImportActorsIDs = errors.New("invalid value of actors_ids field, must be a list of integers")
+	ErrMissingImportColumn    = errors.New("missing required CSV column")
+	ErrUnknownActorName       = errors.New("no actor has provided name")
+	ErrAmbiguousActorName     = errors.New("more than one actor has provided name")
+	ErrInvalidImportRow       = errors.New("could not decode row")
+	ErrDuplicateImportID      = errors.New("id is already used by a previous row of the batch")
+
+	ErrInvalidSuggestQuery = errors.New("invalid length of q parameter, must be of length 2 to 100")
+
 	ErrInvalidUsernameLength = errors.New("invalid length of username field, must be of length 1 to 100")
 
+	ErrUnsupportedImageType = errors.New("unsupported image type, must be one of: image/jpeg, image/png, image/gif")
+	ErrImageTooLarge        = errors.New("image file is too large")
+	ErrInvalidImage         = errors.New("could not decode image")
+	ErrImageTooManyPixels   = errors.New("image dimensions are too large, must be at most 40 megapixels")
+
+	ErrInvalidReleaseCountry           = errors.New("invalid value of country field of release, must be an ISO 3166-1 alpha-2 country code")
+	ErrInvalidReleaseType              = errors.New("invalid value of type field of release, must be one of: theatrical, digital, festival")
+	ErrInvalidReleaseDate              = errors.New("invalid format of date field of release, must be in format 2006-01-02")
+	ErrDuplicateFilmRelease            = errors.New("film has more than one release of the same type in a country")
+	ErrTooManyFilmReleases             = errors.New("too many releases, at most 200 are allowed")
+	ErrUnsupportedCertificationCountry = errors.New("unsupported country of certification, must be one of: DE, FR, GB, RU, US")
+	ErrInvalidCertification            = errors.New("invalid certification for its country, must be one of: 0+, 6+, 12+, 16+, 18+ for RU; G, PG, PG-13, R, NC-17 for US; U, PG, 12A, 12, 15, 18, R18 for GB; 0, 6, 12, 16, 18 for DE; U, 10, 12, 16, 18 for FR")
+	ErrDuplicateFilmCertification      = errors.New("film has more than one certification in a country")
+	ErrTooManyFilmCertifications       = errors.New("too many certifications, at most 100 are allowed")
+
+	ErrInvalidFilmRelationType   = errors.New("invalid value of type field, must be one of: sequel_of, prequel_of, remake_of, spin_off")
+	ErrInvalidFilmRelationFilmID = errors.New("invalid value of film_id field, must be a positive integer")
+	ErrSelfFilmRelation          = errors.New("film can't be related to itself")
+
+	ErrInvalidFranchiseNameLength = errors.New("invalid length of name field, must be of length 1 to 150")
+
+	ErrActorPathNotFound = errors.New("actors are not connected by a chain of films within provided max_degrees")
+
+	ErrUnknownStatsReport = errors.New("unknown stats report, must be one of: films_per_year, rating_distribution, prolific_actors, cast_size, actors_by_birth_decade, films_without_cast")
+
+	ErrInvalidLocale = errors.New("invalid locale, must be a language tag like en, ru or pt-BR")
+
 	ErrEmptyActorsIDs = errors.New("empty actors_ids array provided")
 )
@@ -0,0 +1,19 @@
+package entity
+
+// Export formats. Zip bundle contains an NDJSON file for every exported entity.
+const (
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatCSV    = "csv"
+	ExportFormatZip    = "zip"
+)
+
+// Exported entities.
+const (
+	ExportEntityActors = "actors"
+	ExportEntityFilms  = "films"
+	ExportEntityCast   = "cast"
+	ExportEntityUsers  = "users"
+)
+
+// Entities in the order they are written to a bundle, actors go before films that reference them.
+var ExportEntities = [...]string{ExportEntityActors, ExportEntityFilms, ExportEntityCast, ExportEntityUsers}
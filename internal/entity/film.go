@@ -1,25 +1,51 @@
 package entity
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Film entity.
 type Film struct {
 	ID          int    `json:"id"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
-	ReleaseDate string `json:"release_date"`
+	ReleaseDate Date   `json:"release_date"`
 	Rating      int    `json:"rating"`
+	Version     int    `json:"version"`
 }
 
 // Film with all actors that is present.
 // This struct is used in the API response.
 type FilmWithActors struct {
-	ID          int    `json:"id"`
+	ID              int        `json:"id"`
+	Title           string     `json:"title"`
+	Description     string     `json:"description"`
+	ReleaseDate     Date       `json:"release_date"`
+	Rating          int        `json:"rating"`
+	UserRatingAvg   float64    `json:"user_rating_avg"`
+	UserRatingCount int        `json:"user_rating_count"`
+	ActorsIDs       []int      `json:"actors_ids"`
+	Version         int        `json:"version"`
+	Poster          *Image     `json:"poster"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	// Locale of translated title and description, absent if they are original.
+	Locale string `json:"locale,omitempty"`
+	// Releases and certifications per country, only of the filtered country in filtered lists.
+	Releases       []*FilmRelease       `json:"releases,omitempty"`
+	Certifications []*FilmCertification `json:"certifications,omitempty"`
+	// Related films and franchises, present only in the single film view.
+	Relations  []*FilmRelation  `json:"relations,omitempty"`
+	Franchises []*FilmFranchise `json:"franchises,omitempty"`
+	// Full-text search relevance and highlights, present only in search results.
+	Relevance  *float64        `json:"relevance,omitempty"`
+	Highlights *FilmHighlights `json:"highlights,omitempty"`
+}
+
+// Fragments of film's fields with search terms wrapped in <b></b> tags.
+type FilmHighlights struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
-	ReleaseDate string `json:"release_date"`
-	Rating      int    `json:"rating"`
-	ActorsIDs   []int  `json:"actors_ids"`
 }
 
 // Film create body.
@@ -38,7 +64,7 @@ func ValidateFilmCreateBody(body *FilmCreateBody) (err error) {
 	if len(body.Description) > 1000 {
 		return ErrInvalidFilmDescriptionLength
 	}
-	_, err = time.Parse("01.02.2006", body.ReleaseDate)
+	_, err = ParseDate(body.ReleaseDate)
 	if err != nil {
 		return ErrInvalidFilmReleaseDate
 	}
@@ -71,7 +97,7 @@ func ValidateFilmUpdateBody(body *FilmUpdateBody) (err error) {
 		return ErrInvalidFilmDescriptionLength
 	}
 	if len(body.ReleaseDate) > 0 {
-		_, err = time.Parse("01.02.2006", body.ReleaseDate)
+		_, err = ParseDate(body.ReleaseDate)
 		if err != nil {
 			return ErrInvalidFilmReleaseDate
 		}
@@ -98,7 +124,7 @@ func ValidateFilmReplaceBody(body *FilmReplaceBody) (err error) {
 	if len(body.Description) > 1000 {
 		return ErrInvalidFilmDescriptionLength
 	}
-	_, err = time.Parse("01.02.2006", body.ReleaseDate)
+	_, err = ParseDate(body.ReleaseDate)
 	if err != nil {
 		return ErrInvalidFilmReleaseDate
 	}
@@ -114,45 +140,163 @@ func ValidateFilmReplaceBody(body *FilmReplaceBody) (err error) {
 	return
 }
 
-// Film sort params.
-type FilmSortParams struct {
-	Field string
-	Order string
-}
+// Field that Films can be sorted by.
+type FilmSortField string
+
+const (
+	FilmTitleSortField           FilmSortField = "title"
+	FilmRatingSortField          FilmSortField = "rating"
+	FilmReleaseDateSortField     FilmSortField = "release_date"
+	FilmUserRatingAvgSortField   FilmSortField = "user_rating_avg"
+	FilmUserRatingCountSortField FilmSortField = "user_rating_count"
+	FilmActorsCountSortField     FilmSortField = "actors_count"
+	// Sort field for full-text search results, available only with a search query.
+	FilmRelevanceSortField FilmSortField = "relevance"
+)
 
 // Fields that Films can be sorted by.
-var FilmSortFields = [...]string{"title", "rating", "release_date"}
+var FilmSortFields = [...]FilmSortField{
+	FilmTitleSortField, FilmRatingSortField, FilmReleaseDateSortField, FilmUserRatingAvgSortField,
+	FilmUserRatingCountSortField, FilmActorsCountSortField, FilmRelevanceSortField,
+}
+
+// Direction that Films can be sorted in.
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "asc"
+	SortOrderDesc SortOrder = "desc"
+)
 
 // Directions that Films can be sorted in.
-var FilmSortOrder = [...]string{"asc", "desc"}
+var FilmSortOrder = [...]SortOrder{SortOrderAsc, SortOrderDesc}
+
+// Film sort key.
+type FilmSortKey struct {
+	Field FilmSortField
+	Order SortOrder
+}
+
+// Film sort params. Keys are applied in order, remaining ties are broken by id.
+type FilmSortParams struct {
+	Keys []FilmSortKey
+}
 
 // Default sort field.
-const FilmDefaultSortField = "rating"
+const FilmDefaultSortField = FilmRatingSortField
 
 // Default sort order.
-const FilmDefaultSortOrder = "desc"
+const FilmDefaultSortOrder = SortOrderDesc
+
+// Max number of keys in a sort specification.
+const FilmSortMaxKeys = 5
+
+// Parse a sort specification like -rating,title. Fields prefixed with - are sorted descending.
+func ParseFilmSortParams(spec string) (params *FilmSortParams, err error) {
+	params = &FilmSortParams{}
+	for _, key := range strings.Split(spec, ",") {
+		key = strings.TrimSpace(key)
+		order := SortOrderAsc
+		if strings.HasPrefix(key, "-") {
+			key, order = key[1:], SortOrderDesc
+		}
+		if !IsValidParam("sort_field", key) {
+			return nil, ErrInvalidFilmSortField
+		}
+		for _, parsed := range params.Keys {
+			if parsed.Field == FilmSortField(key) {
+				return nil, ErrDuplicateFilmSortField
+			}
+		}
+		params.Keys = append(params.Keys, FilmSortKey{FilmSortField(key), order})
+	}
+	if len(params.Keys) > FilmSortMaxKeys {
+		return nil, ErrTooManyFilmSortFields
+	}
+	return
+}
+
+// Check whether Films are sorted by provided field.
+func (p *FilmSortParams) HasField(field FilmSortField) bool {
+	for _, key := range p.Keys {
+		if key.Field == field {
+			return true
+		}
+	}
+	return false
+}
 
 // Film search params.
 type FilmSearchParams struct {
+	// Full-text search query over title, description and cast names, original and translated.
+	Query string
+	// Substrings of title and cast names, matched in any locale.
 	Title     string
 	ActorName string
+	// Only films from watchlist of the user with this id, ignored if 0.
+	InWatchlistOf int
+	// Only films from the collection with this slug.
+	Collection string
+	// Whether Collection may be a draft, only published collections are matched otherwise.
+	WithDraftCollections bool
+	// Only films of the franchise with this id, ignored if 0.
+	Franchise int
+	// Only films released in the country, of the release type if it is set.
+	// Release dates are compared to releases in the country or of the type if any of them is set, to release_date otherwise.
+	Country        string
+	ReleaseType    ReleaseType
+	ReleasedAfter  Date
+	ReleasedBefore Date
+	// Only films with certification in Country of at most this rank, ignored if nil.
+	MaxCertificationRank *int
 }
 
 // Fields that Films can be searched by.
 var FilmSearchFields = [...]string{"title", "actor_name"}
 
+// Facet bucket with the number of matched Films having its value.
+type FacetBucket struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int    `json:"count"`
+}
+
+// Fields that Films can be faceted by.
+var FilmFacetFields = [...]string{FilmDecadeFacet, FilmRatingFacet, FilmActorFacet}
+
+const (
+	FilmDecadeFacet = "decade"
+	FilmRatingFacet = "rating"
+	FilmActorFacet  = "actor"
+)
+
+// Max number of buckets in actor facet, actors starring in most matched Films are kept.
+const FilmActorFacetLimit = 10
+
+// List of Films together with facet buckets of the whole result set.
+type FilmListWithFacets struct {
+	Items  []*FilmWithActors         `json:"items"`
+	Facets map[string][]*FacetBucket `json:"facets"`
+}
+
 // IsValidParam checks if param is valid.
 func IsValidParam(param, value string) bool {
 	switch param {
 	case "sort_field":
 		for _, f := range FilmSortFields {
-			if f == value {
+			if string(f) == value {
 				return true
 			}
 		}
 	case "sort_order":
 		for _, d := range FilmSortOrder {
-			if d == value {
+			if string(d) == value {
+				return true
+			}
+		}
+	case "facet":
+		for _, f := range FilmFacetFields {
+			if f == value {
 				return true
 			}
 		}
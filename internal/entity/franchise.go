@@ -0,0 +1,37 @@
+package entity
+
+// Franchise entity, its films are kept in chronological order of the story.
+type Franchise struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	FilmsIDs []int  `json:"films_ids"`
+}
+
+// Franchise with all its films in chronological and in release order.
+// This struct is used in the API response.
+type FranchiseWithFilms struct {
+	ID                 int               `json:"id"`
+	Name               string            `json:"name"`
+	ChronologicalOrder []*FilmWithActors `json:"chronological_order"`
+	ReleaseOrder       []*FilmWithActors `json:"release_order"`
+}
+
+// Franchise of a Film with the Film's position in its chronological order, starting from 1.
+type FilmFranchise struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+}
+
+// Franchise create or replace body.
+type FranchiseBody struct {
+	Name     string `json:"name"`
+	FilmsIDs []int  `json:"films_ids"`
+}
+
+func ValidateFranchiseBody(body *FranchiseBody) (err error) {
+	if len(body.Name) == 0 || len(body.Name) > 150 {
+		return ErrInvalidFranchiseNameLength
+	}
+	return validateCollectionFilmsIDs(body.FilmsIDs)
+}
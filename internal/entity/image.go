@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Uploaded image: a film poster or an actor photo.
+type Image struct {
+	// Keys of the original and thumbnails in blob storage, they are not exposed in the API.
+	Keys       []string          `json:"-"`
+	URL        string            `json:"url"`
+	Width      int               `json:"width"`
+	Height     int               `json:"height"`
+	Thumbnails map[string]string `json:"thumbnails"`
+}
+
+// Image as it is stored in database, including its keys.
+type imageRecord struct {
+	Keys       []string          `json:"keys"`
+	URL        string            `json:"url"`
+	Width      int               `json:"width"`
+	Height     int               `json:"height"`
+	Thumbnails map[string]string `json:"thumbnails"`
+}
+
+func (i Image) Value() (driver.Value, error) {
+	data, err := json.Marshal(imageRecord(i))
+	return string(data), err
+}
+
+func (i *Image) Scan(src interface{}) (err error) {
+	var record imageRecord
+	switch src := src.(type) {
+	case []byte:
+		err = json.Unmarshal(src, &record)
+	case string:
+		err = json.Unmarshal([]byte(src), &record)
+	default:
+		return fmt.Errorf("could not scan %T into Image", src)
+	}
+	*i = Image(record)
+	return
+}
+
+// Thumbnail size, images are scaled down to fit into a square with MaxSide side.
+type ThumbnailSize struct {
+	Name    string
+	MaxSide int
+}
+
+// Sizes of thumbnails generated for uploaded images.
+var ThumbnailSizes = [...]ThumbnailSize{{"small", 160}, {"medium", 320}, {"large", 640}}
+
+// Content types of images that can be uploaded and extensions of their stored originals.
+var ImageContentTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+// Max number of pixels of an uploaded image, bigger images are rejected before being decoded.
+const ImageMaxPixels = 40_000_000
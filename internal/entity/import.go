@@ -0,0 +1,129 @@
+package entity
+
+// Import formats.
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+// Import params.
+type ImportParams struct {
+	Format string
+	// Validate and report rows without saving anything.
+	DryRun bool
+	// Ignore ids of rows and cast, so that every row creates a new entity and cast is matched by actors' names.
+	// This allows to move data exported from another database.
+	IgnoreIDs bool
+}
+
+// Number of rows written to the database in one batch.
+const ImportBatchSize = 1000
+
+// Import row statuses.
+const (
+	ImportRowStatusCreated = "created"
+	ImportRowStatusUpdated = "updated"
+	ImportRowStatusFailed  = "failed"
+)
+
+// Actor import row. Rows with id update an existing actor, rows without id create a new one.
+type ActorImportRow struct {
+	Row         int               `json:"-"`
+	ID          *int              `json:"id"`
+	Name        string            `json:"name"`
+	Gender      *Gender           `json:"gender"`
+	BirthDate   string            `json:"birth_date"`
+	DeathDate   string            `json:"death_date"`
+	Biography   string            `json:"biography"`
+	Birthplace  string            `json:"birthplace"`
+	Nationality string            `json:"nationality"`
+	Aliases     []string          `json:"aliases"`
+	ExternalIDs map[string]string `json:"external_ids"`
+}
+
+// Validate an actor import row with the same rules as create and replace bodies.
+func ValidateActorImportRow(row *ActorImportRow) (err error) {
+	if row.ID != nil {
+		if *row.ID <= 0 {
+			return ErrInvalidImportID
+		}
+		return ValidateActorReplaceBody(&ActorReplaceBody{
+			Name:        row.Name,
+			Gender:      row.Gender,
+			BirthDate:   row.BirthDate,
+			DeathDate:   row.DeathDate,
+			Biography:   row.Biography,
+			Birthplace:  row.Birthplace,
+			Nationality: row.Nationality,
+			Aliases:     row.Aliases,
+			ExternalIDs: row.ExternalIDs,
+		})
+	}
+	return ValidateActorCreateBody(&ActorCreateBody{
+		Name:        row.Name,
+		Gender:      row.Gender,
+		BirthDate:   row.BirthDate,
+		DeathDate:   row.DeathDate,
+		Biography:   row.Biography,
+		Birthplace:  row.Birthplace,
+		Nationality: row.Nationality,
+		Aliases:     row.Aliases,
+		ExternalIDs: row.ExternalIDs,
+	})
+}
+
+// Film import row. Rows with id update an existing film, rows without id create a new one.
+// Cast may be referenced by actors' ids, names or both.
+type FilmImportRow struct {
+	Row         int      `json:"-"`
+	ID          *int     `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	ReleaseDate string   `json:"release_date"`
+	Rating      *int     `json:"rating"`
+	ActorsIDs   []int    `json:"actors_ids"`
+	ActorsNames []string `json:"actors_names"`
+}
+
+// Validate a film import row with the same rules as create and replace bodies.
+// Actors' names must be resolved to ids before validation.
+func ValidateFilmImportRow(row *FilmImportRow) (err error) {
+	if row.ID != nil {
+		if *row.ID <= 0 {
+			return ErrInvalidImportID
+		}
+		return ValidateFilmReplaceBody(&FilmReplaceBody{
+			Title:       row.Title,
+			Description: row.Description,
+			ReleaseDate: row.ReleaseDate,
+			Rating:      row.Rating,
+			ActorsIDs:   row.ActorsIDs,
+		})
+	}
+	return ValidateFilmCreateBody(&FilmCreateBody{
+		Title:       row.Title,
+		Description: row.Description,
+		ReleaseDate: row.ReleaseDate,
+		Rating:      row.Rating,
+		ActorsIDs:   row.ActorsIDs,
+	})
+}
+
+// Result of importing a single row.
+// Rows are numbered from 1: CSV rows are counted without the header and NDJSON rows are line numbers.
+type ImportRowResult struct {
+	Row    int    `json:"row"`
+	Status string `json:"status"`
+	ID     int    `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Import report with per-row results ordered by row number.
+// In dry-run mode nothing is saved and ids of rows that would be created are not reported.
+type ImportReport struct {
+	DryRun  bool               `json:"dry_run"`
+	Created int                `json:"created"`
+	Updated int                `json:"updated"`
+	Failed  int                `json:"failed"`
+	Rows    []*ImportRowResult `json:"rows"`
+}
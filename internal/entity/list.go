@@ -0,0 +1,10 @@
+package entity
+
+import "time"
+
+// State of lists of films and actors. It changes whenever a film, an actor or a cast changes,
+// lists of films include cast and lists of actors include films, so both share the state.
+type ListState struct {
+	Count     int
+	UpdatedAt time.Time
+}
@@ -0,0 +1,13 @@
+package entity
+
+// Pagination params.
+type PaginationParams struct {
+	Limit  int
+	Offset int
+}
+
+// Default number of items per page.
+const DefaultPaginationLimit = 20
+
+// Max number of items per page.
+const MaxPaginationLimit = 100
@@ -0,0 +1,22 @@
+package entity
+
+// Film scored by its similarity to another Film or by the rating a user is predicted to give it.
+type FilmRecommendation struct {
+	ID            int     `json:"id"`
+	Title         string  `json:"title"`
+	ReleaseDate   Date    `json:"release_date"`
+	Rating        int     `json:"rating"`
+	UserRatingAvg float64 `json:"user_rating_avg"`
+	Poster        *Image  `json:"poster"`
+	Score         float64 `json:"score"`
+	// Number of actors shared with the Film it is similar to, present only in similar films.
+	SharedActorsCount int `json:"shared_actors_count,omitempty"`
+}
+
+// Tuning of rating-based similarity of Films.
+const (
+	// Films rated by fewer common users are not considered similar.
+	MinCommonRaters = 2
+	// Number of the most similar Films kept for every Film.
+	MaxSimilarFilmsPerFilm = 50
+)
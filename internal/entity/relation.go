@@ -0,0 +1,66 @@
+package entity
+
+// Type of a relation of a Film to another Film.
+type FilmRelationType string
+
+const (
+	FilmRelationSequelOf  FilmRelationType = "sequel_of"
+	FilmRelationPrequelOf FilmRelationType = "prequel_of"
+	FilmRelationRemakeOf  FilmRelationType = "remake_of"
+	FilmRelationSpinOff   FilmRelationType = "spin_off"
+	// Inverse types of remake_of and spin_off, they are only returned and can't be created.
+	FilmRelationHasRemake  FilmRelationType = "has_remake"
+	FilmRelationHasSpinOff FilmRelationType = "has_spin_off"
+)
+
+// Relation types that can be created.
+var FilmRelationTypes = [...]FilmRelationType{FilmRelationSequelOf, FilmRelationPrequelOf, FilmRelationRemakeOf, FilmRelationSpinOff}
+
+// Get the type of the same relation seen from the other Film.
+func (t FilmRelationType) Inverse() FilmRelationType {
+	switch t {
+	case FilmRelationSequelOf:
+		return FilmRelationPrequelOf
+	case FilmRelationPrequelOf:
+		return FilmRelationSequelOf
+	case FilmRelationRemakeOf:
+		return FilmRelationHasRemake
+	case FilmRelationHasRemake:
+		return FilmRelationRemakeOf
+	case FilmRelationSpinOff:
+		return FilmRelationHasSpinOff
+	case FilmRelationHasSpinOff:
+		return FilmRelationSpinOff
+	}
+	return t
+}
+
+// Relation of a Film to another Film, e.g. the Film is sequel_of the related one.
+type FilmRelation struct {
+	Type        FilmRelationType `json:"type"`
+	FilmID      int              `json:"film_id"`
+	Title       string           `json:"title"`
+	ReleaseDate Date             `json:"release_date"`
+}
+
+// Film relation create body.
+type FilmRelationBody struct {
+	Type   FilmRelationType `json:"type"`
+	FilmID int              `json:"film_id"`
+}
+
+func ValidateFilmRelationBody(body *FilmRelationBody) (err error) {
+	isValidType := false
+	for _, t := range FilmRelationTypes {
+		if t == body.Type {
+			isValidType = true
+		}
+	}
+	if !isValidType {
+		return ErrInvalidFilmRelationType
+	}
+	if body.FilmID <= 0 {
+		return ErrInvalidFilmRelationFilmID
+	}
+	return
+}
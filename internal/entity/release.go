@@ -0,0 +1,137 @@
+package entity
+
+// Type of a Film release in a country.
+type ReleaseType string
+
+const (
+	ReleaseTypeTheatrical ReleaseType = "theatrical"
+	ReleaseTypeDigital    ReleaseType = "digital"
+	ReleaseTypeFestival   ReleaseType = "festival"
+)
+
+var ReleaseTypes = [...]ReleaseType{ReleaseTypeTheatrical, ReleaseTypeDigital, ReleaseTypeFestival}
+
+// Check if a release type is one of ReleaseTypes.
+func IsValidReleaseType(releaseType ReleaseType) bool {
+	for _, t := range ReleaseTypes {
+		if t == releaseType {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if a code is an ISO 3166-1 alpha-2 country code in uppercase.
+func IsValidCountryCode(code string) bool {
+	return countryCodeRegexp.MatchString(code)
+}
+
+// Release of a Film in a country.
+type FilmRelease struct {
+	Country string      `json:"country"`
+	Type    ReleaseType `json:"type"`
+	Date    Date        `json:"date"`
+}
+
+// Age certification of a Film in a country.
+type FilmCertification struct {
+	Country       string `json:"country"`
+	Certification string `json:"certification"`
+	// Position of the certification in its country's system, used for filtering only.
+	Rank int `json:"-"`
+}
+
+// Release dates and age certifications of a Film in all countries.
+type FilmReleaseInfo struct {
+	Releases       []*FilmRelease       `json:"releases"`
+	Certifications []*FilmCertification `json:"certifications"`
+}
+
+// Age certifications of every supported country ranked from the least to the most restrictive.
+// Certifications with the same rank are equally restrictive.
+var CertificationRanks = map[string]map[string]int{
+	"RU": {"0+": 0, "6+": 6, "12+": 12, "16+": 16, "18+": 18},
+	"US": {"G": 0, "PG": 10, "PG-13": 13, "R": 17, "NC-17": 18},
+	"GB": {"U": 0, "PG": 8, "12A": 12, "12": 12, "15": 15, "18": 18, "R18": 19},
+	"DE": {"0": 0, "6": 6, "12": 12, "16": 16, "18": 18},
+	"FR": {"U": 0, "10": 10, "12": 12, "16": 16, "18": 18},
+}
+
+// Get rank of an age certification in the system of a country.
+func CertificationRank(country, certification string) (rank int, err error) {
+	ranks, ok := CertificationRanks[country]
+	if !ok {
+		return 0, ErrUnsupportedCertificationCountry
+	}
+	rank, ok = ranks[certification]
+	if !ok {
+		return 0, ErrInvalidCertification
+	}
+	return
+}
+
+// Max number of releases and certifications of a Film.
+const (
+	FilmMaxReleases       = 200
+	FilmMaxCertifications = 100
+)
+
+// Release body.
+type FilmReleaseBody struct {
+	Country string      `json:"country"`
+	Type    ReleaseType `json:"type"`
+	Date    string      `json:"date"`
+}
+
+// Release info body, it replaces all releases and certifications of a Film.
+type FilmReleaseInfoBody struct {
+	Releases       []*FilmReleaseBody   `json:"releases"`
+	Certifications []*FilmCertification `json:"certifications"`
+}
+
+// Validate release info body and build release info from it.
+// A Film may have one release of every type and one certification per country.
+func ParseFilmReleaseInfoBody(body *FilmReleaseInfoBody) (info *FilmReleaseInfo, err error) {
+	if len(body.Releases) > FilmMaxReleases {
+		return nil, ErrTooManyFilmReleases
+	}
+	if len(body.Certifications) > FilmMaxCertifications {
+		return nil, ErrTooManyFilmCertifications
+	}
+	info = &FilmReleaseInfo{Releases: []*FilmRelease{}, Certifications: []*FilmCertification{}}
+	releases := make(map[FilmRelease]bool)
+	for _, release := range body.Releases {
+		if release == nil || !IsValidCountryCode(release.Country) {
+			return nil, ErrInvalidReleaseCountry
+		}
+		if !IsValidReleaseType(release.Type) {
+			return nil, ErrInvalidReleaseType
+		}
+		date, err := ParseFullDate(release.Date)
+		if err != nil {
+			return nil, ErrInvalidReleaseDate
+		}
+		key := FilmRelease{Country: release.Country, Type: release.Type}
+		if releases[key] {
+			return nil, ErrDuplicateFilmRelease
+		}
+		releases[key] = true
+		info.Releases = append(info.Releases, &FilmRelease{release.Country, release.Type, date})
+	}
+	countries := make(map[string]bool)
+	for _, certification := range body.Certifications {
+		if certification == nil {
+			return nil, ErrInvalidCertification
+		}
+		rank, err := CertificationRank(certification.Country, certification.Certification)
+		if err != nil {
+			return nil, err
+		}
+		if countries[certification.Country] {
+			return nil, ErrDuplicateFilmCertification
+		}
+		countries[certification.Country] = true
+		info.Certifications = append(info.Certifications, &FilmCertification{certification.Country, certification.Certification, rank})
+	}
+	return
+}
@@ -0,0 +1,46 @@
+package entity
+
+import "time"
+
+// Review entity.
+type Review struct {
+	ID        int       `json:"id"`
+	FilmID    int       `json:"film_id"`
+	UserID    int       `json:"user_id"`
+	Score     int       `json:"score"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Review create body.
+type ReviewCreateBody struct {
+	Score *int   `json:"score"`
+	Text  string `json:"text"`
+}
+
+func ValidateReviewCreateBody(body *ReviewCreateBody) (err error) {
+	if body.Score == nil || *body.Score < 0 || *body.Score > 10 {
+		return ErrInvalidReviewScore
+	}
+	if len(body.Text) > 5000 {
+		return ErrInvalidReviewTextLength
+	}
+	return
+}
+
+// Review update body.
+type ReviewUpdateBody struct {
+	Score *int    `json:"score"`
+	Text  *string `json:"text"`
+}
+
+func ValidateReviewUpdateBody(body *ReviewUpdateBody) (err error) {
+	if body.Score != nil && (*body.Score < 0 || *body.Score > 10) {
+		return ErrInvalidReviewScore
+	}
+	if body.Text != nil && len(*body.Text) > 5000 {
+		return ErrInvalidReviewTextLength
+	}
+	return
+}
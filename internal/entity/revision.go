@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Revision entity, a single recorded change of a film or an actor.
+type Revision struct {
+	ID         int64                  `json:"id"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   int                    `json:"entity_id"`
+	Action     string                 `json:"action"`
+	ChangedBy  *int                   `json:"changed_by"`
+	ChangedAt  time.Time              `json:"changed_at"`
+	Diff       map[string]FieldChange `json:"diff"`
+	// State of the entity after the change, or before it for deletions.
+	Snapshot json.RawMessage `json:"-"`
+}
+
+// Old and new value of a changed field.
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Revision entity types.
+const (
+	RevisionEntityFilm  = "film"
+	RevisionEntityActor = "actor"
+)
+
+// Revision actions.
+const (
+	RevisionActionCreate  = "create"
+	RevisionActionUpdate  = "update"
+	RevisionActionDelete  = "delete"
+	RevisionActionRestore = "restore"
+	RevisionActionRevert  = "revert"
+)
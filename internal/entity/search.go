@@ -0,0 +1,33 @@
+package entity
+
+import "unicode/utf8"
+
+// Search suggestion for autocomplete.
+type Suggestion struct {
+	Type  string  `json:"type"`
+	ID    int     `json:"id"`
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
+}
+
+// Suggestion types.
+const (
+	SuggestionTypeFilm  = "film"
+	SuggestionTypeActor = "actor"
+)
+
+// Suggestion limits.
+const (
+	SuggestDefaultLimit = 10
+	SuggestMaxLimit     = 20
+)
+
+// Minimal trigram word similarity of a suggestion to the query.
+const SuggestSimilarityThreshold = 0.3
+
+func ValidateSuggestQuery(query string) (err error) {
+	if length := utf8.RuneCountInString(query); length < 2 || length > 100 {
+		return ErrInvalidSuggestQuery
+	}
+	return
+}
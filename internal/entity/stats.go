@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Catalog statistics reports.
+const (
+	StatsFilmsPerYear        = "films_per_year"
+	StatsRatingDistribution  = "rating_distribution"
+	StatsProlificActors      = "prolific_actors"
+	StatsCastSize            = "cast_size"
+	StatsActorsByBirthDecade = "actors_by_birth_decade"
+	StatsFilmsWithoutCast    = "films_without_cast"
+)
+
+var StatsReports = [...]string{StatsFilmsPerYear, StatsRatingDistribution, StatsProlificActors, StatsCastSize, StatsActorsByBirthDecade, StatsFilmsWithoutCast}
+
+// Stats report params.
+type StatsParams struct {
+	// Window of release years of films, or birth years of actors, both bounds are inclusive and ignored if 0.
+	FromYear int
+	ToYear   int
+	// Page of reports listing actors or films.
+	Pagination *PaginationParams
+}
+
+// Stats report as a table.
+type StatsReport struct {
+	Name    string
+	Columns []string
+	Rows    [][]interface{}
+	// Time of the last refresh of aggregates the report is built from, nil if it is built from live data.
+	RefreshedAt *time.Time
+}
+
+// Encode report rows as objects keyed by column names.
+func (r *StatsReport) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.MsgpackValue())
+}
+
+// Get the report as it is encoded, with rows as objects keyed by column names.
+func (r *StatsReport) MsgpackValue() interface{} {
+	rows := make([]map[string]interface{}, len(r.Rows))
+	for i, row := range r.Rows {
+		rows[i] = make(map[string]interface{}, len(r.Columns))
+		for j, column := range r.Columns {
+			rows[i][column] = row[j]
+		}
+	}
+	return struct {
+		Report      string                   `json:"report"`
+		RefreshedAt *time.Time               `json:"refreshed_at,omitempty"`
+		Rows        []map[string]interface{} `json:"rows"`
+	}{r.Name, r.RefreshedAt, rows}
+}
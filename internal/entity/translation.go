@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Translation of a Film's title and description.
+type FilmTranslation struct {
+	Locale      string `json:"locale"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// Translation of an Actor's name.
+type ActorTranslation struct {
+	Locale string `json:"locale"`
+	Name   string `json:"name"`
+}
+
+// Film translation body. Empty description falls back to the original one.
+type FilmTranslationBody struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+func ValidateFilmTranslationBody(body *FilmTranslationBody) (err error) {
+	if len(body.Title) == 0 || len(body.Title) > 150 {
+		return ErrInvalidFilmTitleLength
+	}
+	if len(body.Description) > 1000 {
+		return ErrInvalidFilmDescriptionLength
+	}
+	return
+}
+
+// Actor translation body.
+type ActorTranslationBody struct {
+	Name string `json:"name"`
+}
+
+func ValidateActorTranslationBody(body *ActorTranslationBody) (err error) {
+	if len(body.Name) == 0 || len(body.Name) > 100 {
+		return ErrInvalidActorNameLength
+	}
+	return
+}
+
+// Language tag with optional subtags, e.g. en, ru or pt-br. Tags are stored and compared in lowercase.
+var localeRegexp = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{1,8})*$`)
+
+// Max number of locales a request may prefer, including fallbacks.
+const MaxLocales = 10
+
+// Parse and normalize a language tag.
+func ParseLocale(value string) (locale string, err error) {
+	locale = strings.ToLower(strings.TrimSpace(value))
+	if len(locale) > 35 || !localeRegexp.MatchString(locale) {
+		return "", ErrInvalidLocale
+	}
+	return
+}
+
+// Add fallbacks to preferred locales: every tag is followed by its shorter prefixes, e.g. pt-br by pt.
+// Duplicates are removed and the result is truncated to MaxLocales.
+func LocaleFallbacks(preferred []string) (locales []string) {
+	seen := make(map[string]bool)
+	for _, locale := range preferred {
+		for {
+			if !seen[locale] {
+				seen[locale] = true
+				locales = append(locales, locale)
+			}
+			idx := strings.LastIndexByte(locale, '-')
+			if idx < 0 {
+				break
+			}
+			locale = locale[:idx]
+		}
+	}
+	if len(locales) > MaxLocales {
+		locales = locales[:MaxLocales]
+	}
+	return
+}
@@ -0,0 +1,69 @@
+package entity
+
+import "time"
+
+// Watchlist entry entity.
+type WatchlistEntry struct {
+	FilmID  int       `json:"film_id"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// Watchlist add body.
+type WatchlistAddBody struct {
+	FilmID int `json:"film_id"`
+}
+
+func ValidateWatchlistAddBody(body *WatchlistAddBody) (err error) {
+	if body.FilmID <= 0 {
+		return ErrInvalidFilmID
+	}
+	return
+}
+
+// Watched film entry entity.
+type WatchedEntry struct {
+	FilmID    int  `json:"film_id"`
+	WatchedOn Date `json:"watched_on"`
+}
+
+// Watched film add body.
+type WatchedAddBody struct {
+	FilmID    int    `json:"film_id"`
+	WatchedOn string `json:"watched_on"`
+}
+
+func ValidateWatchedAddBody(body *WatchedAddBody) (err error) {
+	if body.FilmID <= 0 {
+		return ErrInvalidFilmID
+	}
+	_, err = ParseFullDate(body.WatchedOn)
+	if err != nil {
+		return ErrInvalidWatchedOn
+	}
+	return
+}
+
+// Number of films watched in a year.
+type WatchedPerYear struct {
+	Year  int `json:"year"`
+	Count int `json:"count"`
+}
+
+// Actor present in watched films.
+type FavouriteActor struct {
+	ActorID    int    `json:"actor_id"`
+	Name       string `json:"name"`
+	FilmsCount int    `json:"films_count"`
+}
+
+// User's watching stats.
+// This struct is used in the API response.
+type UserStats struct {
+	WatchlistCount  int               `json:"watchlist_count"`
+	WatchedCount    int               `json:"watched_count"`
+	WatchedPerYear  []*WatchedPerYear `json:"watched_per_year"`
+	FavouriteActors []*FavouriteActor `json:"favourite_actors"`
+}
+
+// Number of favourite actors in user's stats.
+const FavouriteActorsLimit = 10
@@ -2,20 +2,27 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 
 	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/internal/http_server/middleware"
 	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
 	"github.com/itmosha/vk-internship-2024/pkg/logger"
 )
 
 type ActorUsecaseInterface interface {
-	Create(ctx *context.Context, body *entity.ActorCreateBody) (actor *entity.Actor, err error)
-	Update(ctx *context.Context, id int, body *entity.ActorUpdateBody) (err error)
-	Replace(ctx *context.Context, id int, body *entity.ActorReplaceBody) (err error)
-	Delete(ctx *context.Context, id int) (err error)
-	GetAllWithFilms(ctx *context.Context) (actors []*entity.ActorWithFilms, err error)
+	Create(ctx *context.Context, body *entity.ActorCreateBody, userID int) (actor *entity.Actor, err error)
+	Update(ctx *context.Context, id int, body *entity.ActorUpdateBody, userID int, versions []int) (err error)
+	Replace(ctx *context.Context, id int, body *entity.ActorReplaceBody, userID int, versions []int) (err error)
+	Delete(ctx *context.Context, id int, userID int, versions []int) (err error)
+	GetByID(ctx *context.Context, id int, locales []string) (actor *entity.Actor, err error)
+	GetByIDs(ctx *context.Context, ids []int, locales []string) (actors []*entity.ActorWithFilms, err error)
+	GetAllWithFilms(ctx *context.Context, locales []string) (actors []*entity.ActorWithFilms, err error)
+	GetListState(ctx *context.Context) (state *entity.ListState, err error)
+	GetHistory(ctx *context.Context, id int, pagination *entity.PaginationParams) (revisions []*entity.Revision, err error)
+	Revert(ctx *context.Context, id int, revisionID int64, userID int) (actor *entity.Actor, err error)
+	GetTrash(ctx *context.Context) (actors []*entity.ActorWithFilms, err error)
+	Restore(ctx *context.Context, id int, userID int) (err error)
 }
 
 type ActorHandler struct {
@@ -57,8 +64,15 @@ func (h *ActorHandler) Create() http.HandlerFunc {
 			return
 		}
 
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
 		ctx := context.Background()
-		actor, err := h.actorUsecase.Create(&ctx, body)
+		actor, err := h.actorUsecase.Create(&ctx, body, userID)
 		if err != nil {
 			switch err {
 			default:
@@ -67,8 +81,8 @@ func (h *ActorHandler) Create() http.HandlerFunc {
 			}
 			return
 		}
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(actor)
+		w.Header().Set("ETag", formatETag(actor.Version, nil, middleware.MediaTypeFromContext(r.Context())))
+		writeResponse(w, r, http.StatusCreated, actor)
 		h.logger.Log(r, http.StatusCreated, nil)
 	}
 }
@@ -77,10 +91,12 @@ func (h *ActorHandler) Create() http.HandlerFunc {
 // @Description Update an actor by id.
 // @Param id path integer true "Actor ID"
 // @Param body body entity.ActorUpdateBody true "Update actor body"
+// @Param If-Match header string false "ETag of the version the change is based on"
 // @Success 200 {}
 // @Failure 400 {object} RequestError
 // @Failure 401 {object} RequestError
 // @Failure 403 {object} RequestError
+// @Failure 412 {object} RequestError
 // @Failure 500 {object} RequestError
 // @Resource Actors
 // @Route /api/actors/{id}/ [patch]
@@ -110,13 +126,30 @@ func (h *ActorHandler) Update() http.HandlerFunc {
 			return
 		}
 
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		versions, err := parseIfMatch(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
 		ctx := context.Background()
-		err = h.actorUsecase.Update(&ctx, id, body)
+		err = h.actorUsecase.Update(&ctx, id, body, userID, versions)
 		if err != nil {
 			switch err {
-			case repo.ErrActorNotFound:
+			case repo.ErrActorNotFound, entity.ErrActorDeathBeforeBirth:
 				h.logger.Log(r, http.StatusBadRequest, err)
 				returnError(w, http.StatusBadRequest, err)
+			case repo.ErrVersionMismatch:
+				h.logger.Log(r, http.StatusPreconditionFailed, err)
+				returnError(w, http.StatusPreconditionFailed, err)
 			default:
 				h.logger.Log(r, http.StatusInternalServerError, err)
 				returnError(w, http.StatusInternalServerError, ErrServerError)
@@ -131,10 +164,12 @@ func (h *ActorHandler) Update() http.HandlerFunc {
 // @Description Replace an actor by id.
 // @Param id path integer true "Actor ID"
 // @Param body body entity.ActorReplaceBody true "Replace actor body"
+// @Param If-Match header string false "ETag of the version the change is based on"
 // @Success 200 {}
 // @Failure 400 {object} RequestError
 // @Failure 401 {object} RequestError
 // @Failure 403 {object} RequestError
+// @Failure 412 {object} RequestError
 // @Failure 500 {object} RequestError
 // @Resource Actors
 // @Route /api/actors/{id}/ [put]
@@ -164,13 +199,30 @@ func (h *ActorHandler) Replace() http.HandlerFunc {
 			return
 		}
 
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		versions, err := parseIfMatch(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
 		ctx := context.Background()
-		err = h.actorUsecase.Replace(&ctx, id, body)
+		err = h.actorUsecase.Replace(&ctx, id, body, userID, versions)
 		if err != nil {
 			switch err {
-			case repo.ErrActorNotFound:
+			case repo.ErrActorNotFound, entity.ErrActorDeathBeforeBirth:
 				h.logger.Log(r, http.StatusBadRequest, err)
 				returnError(w, http.StatusBadRequest, err)
+			case repo.ErrVersionMismatch:
+				h.logger.Log(r, http.StatusPreconditionFailed, err)
+				returnError(w, http.StatusPreconditionFailed, err)
 			default:
 				h.logger.Log(r, http.StatusInternalServerError, err)
 				returnError(w, http.StatusInternalServerError, ErrServerError)
@@ -182,12 +234,14 @@ func (h *ActorHandler) Replace() http.HandlerFunc {
 }
 
 // @Title Delete actor
-// @Description Delete an actor by id.
+// @Description Move an actor to trash by id. Actors in trash are permanently deleted after the retention period.
 // @Param id path integer true "Actor ID"
+// @Param If-Match header string false "ETag of the version the change is based on"
 // @Success 204 {}
 // @Failure 400 {object} RequestError
 // @Failure 401 {object} RequestError
 // @Failure 403 {object} RequestError
+// @Failure 412 {object} RequestError
 // @Failure 500 {object} RequestError
 // @Resource Actors
 // @Route /api/actors/{id} [delete]
@@ -199,13 +253,30 @@ func (h *ActorHandler) Delete() http.HandlerFunc {
 			returnError(w, http.StatusBadRequest, err)
 			return
 		}
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		versions, err := parseIfMatch(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
 		ctx := context.Background()
-		err = h.actorUsecase.Delete(&ctx, id)
+		err = h.actorUsecase.Delete(&ctx, id, userID, versions)
 		if err != nil {
 			switch err {
 			case repo.ErrActorNotFound:
 				h.logger.Log(r, http.StatusBadRequest, err)
 				returnError(w, http.StatusBadRequest, err)
+			case repo.ErrVersionMismatch:
+				h.logger.Log(r, http.StatusPreconditionFailed, err)
+				returnError(w, http.StatusPreconditionFailed, err)
 			default:
 				h.logger.Log(r, http.StatusInternalServerError, err)
 				returnError(w, http.StatusInternalServerError, ErrServerError)
@@ -217,17 +288,98 @@ func (h *ActorHandler) Delete() http.HandlerFunc {
 	}
 }
 
+// @Title Get actor
+// @Description Get an actor by id. Name is translated to the most preferred available locale, falling back to the original one.
+// @Param id path integer true "Actor ID"
+// @Param lang query string false "Comma-separated preferred locales of name, Accept-Language is used if omitted"
+// @Param Accept-Language header string false "Preferred locales of name"
+// @Param If-None-Match header string false "ETag of a cached version"
+// @Success 200 {object} entity.Actor
+// @Success 304 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 404 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Actors
+// @Route /api/actors/{id} [get]
+func (h *ActorHandler) GetByID() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		locales, err := parseLocales(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		actor, err := h.actorUsecase.GetByID(&ctx, id, locales)
+		if err != nil {
+			switch err {
+			case repo.ErrActorNotFound:
+				h.logger.Log(r, http.StatusNotFound, err)
+				returnError(w, http.StatusNotFound, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		etag := formatETag(actor.Version, locales, middleware.MediaTypeFromContext(r.Context()))
+		w.Header().Set("ETag", etag)
+		w.Header().Add("Vary", "Accept-Language")
+		if isNotModified(r, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			h.logger.Log(r, http.StatusNotModified, nil)
+			return
+		}
+		writeResponse(w, r, http.StatusOK, actor)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
 // @Title Get all actors
-// @Description Get all actors.
+// @Description Get all actors. Names are translated to the most preferred available locale, falling back to the original ones.
+// @Param lang query string false "Comma-separated preferred locales of names, Accept-Language is used if omitted"
+// @Param Accept-Language header string false "Preferred locales of names"
+// @Param If-None-Match header string false "ETag of a cached list"
+// @Param If-Modified-Since header string false "Last-Modified of a cached list, ignored with If-None-Match"
+// @Param Accept header string false "Media type of the list: application/json, application/msgpack, text/csv or application/x-ndjson"
 // @Success 200 {array} entity.ActorWithFilms
+// @Success 304 {}
 // @Failure 401 {object} RequestError
+// @Failure 406 {object} RequestError
 // @Failure 500 {object} RequestError
 // @Resource Actors
 // @Route /api/actors [get]
 func (h *ActorHandler) GetAllWithFilms() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		locales, err := parseLocales(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
 		ctx := context.Background()
-		actors, err := h.actorUsecase.GetAllWithFilms(&ctx)
+		state, err := h.actorUsecase.GetListState(&ctx)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Language")
+		if isListNotModified(w, r, state, locales) {
+			w.WriteHeader(http.StatusNotModified)
+			h.logger.Log(r, http.StatusNotModified, nil)
+			return
+		}
+		actors, err := h.actorUsecase.GetAllWithFilms(&ctx, locales)
 		if err != nil {
 			switch err {
 			default:
@@ -237,11 +389,174 @@ func (h *ActorHandler) GetAllWithFilms() http.HandlerFunc {
 			return
 		}
 
+		writeList(w, r, actors, actorCSVHeader, actorCSVRecord)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Get actors in trash
+// @Description Get all deleted actors that were not purged yet, recently deleted first.
+// @Success 200 {array} entity.ActorWithFilms
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Trash
+// @Route /api/trash/actors [get]
+func (h *ActorHandler) GetTrash() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.Background()
+		actors, err := h.actorUsecase.GetTrash(&ctx)
+		if err != nil {
+			switch err {
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		w.Header().Add("Vary", "Accept-Language")
 		if actors == nil { // Handle empty actors slice, return [] instead of nil
-			json.NewEncoder(w).Encode([]struct{}{})
+			writeResponse(w, r, http.StatusOK, []struct{}{})
+		} else {
+			writeResponse(w, r, http.StatusOK, actors)
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Restore actor
+// @Description Restore an actor from trash by id, together with its cast links.
+// @Param id path integer true "Actor ID"
+// @Success 200 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Trash
+// @Route /api/actors/{id}/restore [post]
+func (h *ActorHandler) Restore() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		ctx := context.Background()
+		err = h.actorUsecase.Restore(&ctx, id, userID)
+		if err != nil {
+			switch err {
+			case repo.ErrActorNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Get actor history
+// @Description Get changes of an actor, newest first.
+// @Param id path integer true "Actor ID"
+// @Param limit query integer false "Number of revisions per page, 20 by default"
+// @Param offset query integer false "Number of revisions to skip"
+// @Success 200 {array} entity.Revision
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Actors
+// @Route /api/actors/{id}/history [get]
+func (h *ActorHandler) GetHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		pagination, err := parsePaginationParams(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		revisions, err := h.actorUsecase.GetHistory(&ctx, id, pagination)
+		if err != nil {
+			switch err {
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		if revisions == nil { // Handle empty revisions slice, return [] instead of nil
+			writeResponse(w, r, http.StatusOK, []struct{}{})
 		} else {
-			json.NewEncoder(w).Encode(actors)
+			writeResponse(w, r, http.StatusOK, revisions)
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Revert actor
+// @Description Revert an actor to the state from provided revision. Actors in trash are restored.
+// @Param id path integer true "Actor ID"
+// @Param revision_id path integer true "Revision ID"
+// @Success 200 {object} entity.Actor
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Actors
+// @Route /api/actors/{id}/revisions/{revision_id}/revert [post]
+func (h *ActorHandler) Revert() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		revisionID, err := extractPathParam(r, "revision_id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		ctx := context.Background()
+		actor, err := h.actorUsecase.Revert(&ctx, id, int64(revisionID), userID)
+		if err != nil {
+			switch err {
+			case repo.ErrRevisionNotFound, repo.ErrActorNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
 		}
+		writeResponse(w, r, http.StatusOK, actor)
 		h.logger.Log(r, http.StatusOK, nil)
 	}
 }
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/logger"
+)
+
+type CacheUsecaseInterface interface {
+	GetStats(ctx *context.Context) (stats *entity.CacheStats, err error)
+}
+
+type CacheHandler struct {
+	cacheUsecase CacheUsecaseInterface
+	logger       *logger.Logger
+}
+
+// Create new CacheHandler.
+func NewCacheHandler(cacheUsecase CacheUsecaseInterface, logger *logger.Logger) *CacheHandler {
+	return &CacheHandler{cacheUsecase, logger}
+}
+
+// @Title Get cache stats
+// @Description Get hit and miss counters of the read cache of film and actor lists.
+// @Success 200 {object} entity.CacheStats
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Cache
+// @Route /api/cache [get]
+func (h *CacheHandler) GetStats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.Background()
+		stats, err := h.cacheUsecase.GetStats(&ctx)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+		writeResponse(w, r, http.StatusOK, stats)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
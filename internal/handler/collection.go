@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
+	"github.com/itmosha/vk-internship-2024/pkg/logger"
+)
+
+type CollectionUsecaseInterface interface {
+	Create(ctx *context.Context, body *entity.CollectionCreateBody) (collection *entity.Collection, err error)
+	Update(ctx *context.Context, id int, body *entity.CollectionUpdateBody) (err error)
+	Replace(ctx *context.Context, id int, body *entity.CollectionReplaceBody) (err error)
+	Delete(ctx *context.Context, id int) (err error)
+	GetAll(ctx *context.Context, isAdmin bool) (collections []*entity.Collection, err error)
+	GetBySlug(ctx *context.Context, slug string, isAdmin bool) (collection *entity.CollectionWithFilms, err error)
+}
+
+type CollectionHandler struct {
+	collectionUsecase CollectionUsecaseInterface
+	logger            *logger.Logger
+}
+
+// Create new CollectionHandler.
+func NewCollectionHandler(collectionUsecase CollectionUsecaseInterface, logger *logger.Logger) *CollectionHandler {
+	return &CollectionHandler{collectionUsecase, logger}
+}
+
+// @Title Create collection
+// @Description Create a new collection of films.
+// @Param body body entity.CollectionCreateBody true "Create collection body"
+// @Success 201 {object} entity.Collection
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 409 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Collections
+// @Route /api/collections/ [post]
+func (h *CollectionHandler) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isEmptyBody(r) {
+			h.logger.Log(r, http.StatusBadRequest, ErrEmptyBody)
+			returnError(w, http.StatusBadRequest, ErrEmptyBody)
+			return
+		}
+		body, err := readBodyToStruct(r, &entity.CollectionCreateBody{})
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		err = entity.ValidateCollectionCreateBody(body)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		collection, err := h.collectionUsecase.Create(&ctx, body)
+		if err != nil {
+			switch err {
+			case repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			case repo.ErrNonUniqueSlug:
+				h.logger.Log(r, http.StatusConflict, err)
+				returnError(w, http.StatusConflict, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusCreated, collection)
+		h.logger.Log(r, http.StatusCreated, nil)
+	}
+}
+
+// @Title Update collection
+// @Description Update a collection by id. If films_ids is provided, the films are replaced in the given order.
+// @Param id path integer true "Collection ID"
+// @Param body body entity.CollectionUpdateBody true "Update collection body"
+// @Success 200 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 409 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Collections
+// @Route /api/collections/{id}/ [patch]
+func (h *CollectionHandler) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isEmptyBody(r) {
+			h.logger.Log(r, http.StatusBadRequest, ErrEmptyBody)
+			returnError(w, http.StatusBadRequest, ErrEmptyBody)
+			return
+		}
+		id, err := extractIDFromPath(r.URL.Path)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		body, err := readBodyToStruct(r, &entity.CollectionUpdateBody{})
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		err = entity.ValidateCollectionUpdateBody(body)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		err = h.collectionUsecase.Update(&ctx, id, body)
+		if err != nil {
+			switch err {
+			case repo.ErrCollectionNotFound, repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			case repo.ErrNonUniqueSlug:
+				h.logger.Log(r, http.StatusConflict, err)
+				returnError(w, http.StatusConflict, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Replace collection
+// @Description Replace a collection by id.
+// @Param id path integer true "Collection ID"
+// @Param body body entity.CollectionReplaceBody true "Replace collection body"
+// @Success 200 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 409 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Collections
+// @Route /api/collections/{id}/ [put]
+func (h *CollectionHandler) Replace() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isEmptyBody(r) {
+			h.logger.Log(r, http.StatusBadRequest, ErrEmptyBody)
+			returnError(w, http.StatusBadRequest, ErrEmptyBody)
+			return
+		}
+		id, err := extractIDFromPath(r.URL.Path)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		body, err := readBodyToStruct(r, &entity.CollectionReplaceBody{})
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		err = entity.ValidateCollectionReplaceBody(body)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		err = h.collectionUsecase.Replace(&ctx, id, body)
+		if err != nil {
+			switch err {
+			case repo.ErrCollectionNotFound, repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			case repo.ErrNonUniqueSlug:
+				h.logger.Log(r, http.StatusConflict, err)
+				returnError(w, http.StatusConflict, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Delete collection
+// @Description Delete a collection by id. Films of the collection are not deleted.
+// @Param id path integer true "Collection ID"
+// @Success 204 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Collections
+// @Route /api/collections/{id} [delete]
+func (h *CollectionHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractIDFromPath(r.URL.Path)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		ctx := context.Background()
+		err = h.collectionUsecase.Delete(&ctx, id)
+		if err != nil {
+			switch err {
+			case repo.ErrCollectionNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		h.logger.Log(r, http.StatusNoContent, nil)
+	}
+}
+
+// @Title Get all collections
+// @Description Get all published collections. Admins also get drafts.
+// @Success 200 {array} entity.Collection
+// @Failure 401 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Collections
+// @Route /api/collections [get]
+func (h *CollectionHandler) GetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.Background()
+		collections, err := h.collectionUsecase.GetAll(&ctx, isAdminRequest(r))
+		if err != nil {
+			switch err {
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		if collections == nil { // Handle empty collections slice, return [] instead of nil
+			writeResponse(w, r, http.StatusOK, []struct{}{})
+		} else {
+			writeResponse(w, r, http.StatusOK, collections)
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Get collection
+// @Description Get a collection by slug with its films in collection order.
+// @Param slug path string true "Collection slug"
+// @Success 200 {object} entity.CollectionWithFilms
+// @Failure 401 {object} RequestError
+// @Failure 404 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Collections
+// @Route /api/collections/{slug} [get]
+func (h *CollectionHandler) GetBySlug() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		slug, _ := r.Context().Value("slug").(string)
+
+		ctx := context.Background()
+		collection, err := h.collectionUsecase.GetBySlug(&ctx, slug, isAdminRequest(r))
+		if err != nil {
+			switch err {
+			case repo.ErrCollectionNotFound:
+				h.logger.Log(r, http.StatusNotFound, err)
+				returnError(w, http.StatusNotFound, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusOK, collection)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
+	"github.com/itmosha/vk-internship-2024/pkg/logger"
+)
+
+type CostarUsecaseInterface interface {
+	GetCostars(ctx *context.Context, actorID int, pagination *entity.PaginationParams) (costars []*entity.Costar, err error)
+	GetPath(ctx *context.Context, fromID, toID int, maxDegrees int) (path *entity.ActorPath, err error)
+}
+
+type CostarHandler struct {
+	costarUsecase CostarUsecaseInterface
+	logger        *logger.Logger
+}
+
+// Create new CostarHandler.
+func NewCostarHandler(costarUsecase CostarUsecaseInterface, logger *logger.Logger) *CostarHandler {
+	return &CostarHandler{costarUsecase, logger}
+}
+
+// @Title Get actor co-stars
+// @Description Get actors that starred in the same films as an actor, ranked by the number of shared films.
+// @Param id path integer true "Actor ID"
+// @Param limit query integer false "Number of co-stars per page, 20 by default"
+// @Param offset query integer false "Number of co-stars to skip"
+// @Success 200 {array} entity.Costar
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 404 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Actors
+// @Route /api/actors/{id}/costars [get]
+func (h *CostarHandler) GetCostars() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		pagination, err := parsePaginationParams(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		costars, err := h.costarUsecase.GetCostars(&ctx, id, pagination)
+		if err != nil {
+			switch err {
+			case repo.ErrActorNotFound:
+				h.logger.Log(r, http.StatusNotFound, err)
+				returnError(w, http.StatusNotFound, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		if costars == nil { // Handle empty costars slice, return [] instead of nil
+			writeResponse(w, r, http.StatusOK, []struct{}{})
+		} else {
+			writeResponse(w, r, http.StatusOK, costars)
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Get path between actors
+// @Description Get the shortest chain of actors from one actor to another, where every two neighbours starred in the same film. Recent cast changes may take up to a minute to be taken into account.
+// @Param from query integer true "ID of the first actor"
+// @Param to query integer true "ID of the last actor"
+// @Param max_degrees query integer false "Max number of films in the chain, from 1 to 6, 6 by default"
+// @Success 200 {object} entity.ActorPath
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 404 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Actors
+// @Route /api/actors/path [get]
+func (h *CostarHandler) GetPath() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		fromID, err := strconv.Atoi(query.Get("from"))
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, ErrInvalidFromParam)
+			returnError(w, http.StatusBadRequest, ErrInvalidFromParam)
+			return
+		}
+		toID, err := strconv.Atoi(query.Get("to"))
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, ErrInvalidToParam)
+			returnError(w, http.StatusBadRequest, ErrInvalidToParam)
+			return
+		}
+		maxDegrees := entity.MaxActorPathDegrees
+		if maxDegreesParam := query.Get("max_degrees"); maxDegreesParam != "" {
+			maxDegrees, err = strconv.Atoi(maxDegreesParam)
+			if err != nil || maxDegrees < 1 || maxDegrees > entity.MaxActorPathDegrees {
+				h.logger.Log(r, http.StatusBadRequest, ErrInvalidMaxDegreesParam)
+				returnError(w, http.StatusBadRequest, ErrInvalidMaxDegreesParam)
+				return
+			}
+		}
+
+		ctx := context.Background()
+		path, err := h.costarUsecase.GetPath(&ctx, fromID, toID, maxDegrees)
+		if err != nil {
+			switch err {
+			case repo.ErrActorNotFound, entity.ErrActorPathNotFound:
+				h.logger.Log(r, http.StatusNotFound, err)
+				returnError(w, http.StatusNotFound, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusOK, path)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
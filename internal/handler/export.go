@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/logger"
+)
+
+type ExportUsecaseInterface interface {
+	Export(ctx *context.Context, entityName, format string, w io.Writer) (err error)
+	ExportBundle(ctx *context.Context, w io.Writer) (err error)
+}
+
+type ExportHandler struct {
+	exportUsecase ExportUsecaseInterface
+	logger        *logger.Logger
+}
+
+// Create new ExportHandler.
+func NewExportHandler(exportUsecase ExportUsecaseInterface, logger *logger.Logger) *ExportHandler {
+	return &ExportHandler{exportUsecase, logger}
+}
+
+// @Title Export catalog
+// @Description Stream the whole catalog. NDJSON and CSV contain a single entity, zip contains an NDJSON file for every entity. Actors and films can be imported back with the bulk importer.
+// @Param format query string false "Output format: ndjson, csv or zip, ndjson by default"
+// @Param entity query string false "Exported entity: actors, films, cast or users, required unless format is zip"
+// @Success 200 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Export
+// @Route /api/export [get]
+func (h *ExportHandler) Export() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		format := query.Get("format")
+		if format == "" {
+			format = entity.ExportFormatNDJSON
+		}
+		entityName := query.Get("entity")
+		var contentType, fileName string
+		switch format {
+		case entity.ExportFormatNDJSON:
+			contentType = "application/x-ndjson"
+		case entity.ExportFormatCSV:
+			contentType = "text/csv"
+		case entity.ExportFormatZip:
+			contentType, fileName = "application/zip", "catalog.zip"
+		default:
+			h.logger.Log(r, http.StatusBadRequest, ErrInvalidExportFormatParam)
+			returnError(w, http.StatusBadRequest, ErrInvalidExportFormatParam)
+			return
+		}
+		if format != entity.ExportFormatZip {
+			if !isExportEntity(entityName) {
+				h.logger.Log(r, http.StatusBadRequest, ErrInvalidExportEntityParam)
+				returnError(w, http.StatusBadRequest, ErrInvalidExportEntityParam)
+				return
+			}
+			fileName = entityName + "." + format
+		}
+
+		// Export may take longer than the server write timeout
+		http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`"`)
+		out := &trackingResponseWriter{ResponseWriter: w}
+
+		ctx := context.Background()
+		var err error
+		if format == entity.ExportFormatZip {
+			err = h.exportUsecase.ExportBundle(&ctx, out)
+		} else {
+			err = h.exportUsecase.Export(&ctx, entityName, format, out)
+		}
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			if !out.written { // Status can be changed only if nothing was sent yet
+				w.Header().Del("Content-Disposition")
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// Check if entity can be exported.
+func isExportEntity(entityName string) bool {
+	for _, name := range entity.ExportEntities {
+		if name == entityName {
+			return true
+		}
+	}
+	return false
+}
+
+// Response writer that tracks whether the response body was started.
+type trackingResponseWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *trackingResponseWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
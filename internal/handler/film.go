@@ -2,21 +2,34 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/internal/http_server/middleware"
 	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
 	"github.com/itmosha/vk-internship-2024/pkg/logger"
 )
 
 type FilmUsecaseInterface interface {
-	Create(ctx *context.Context, body *entity.FilmCreateBody) (film *entity.Film, err error)
-	Update(ctx *context.Context, id int, body *entity.FilmUpdateBody) (err error)
-	Replace(ctx *context.Context, id int, body *entity.FilmReplaceBody) (err error)
-	Delete(ctx *context.Context, id int) (err error)
-	GetAll(ctx *context.Context, sortParams *entity.FilmSortParams, searchFields *entity.FilmSearchParams) (films []*entity.FilmWithActors, err error)
+	Create(ctx *context.Context, body *entity.FilmCreateBody, userID int) (film *entity.Film, err error)
+	Update(ctx *context.Context, id int, body *entity.FilmUpdateBody, userID int, versions []int) (err error)
+	Replace(ctx *context.Context, id int, body *entity.FilmReplaceBody, userID int, versions []int) (err error)
+	Delete(ctx *context.Context, id int, userID int, versions []int) (err error)
+	GetAll(ctx *context.Context, sortParams *entity.FilmSortParams, searchFields *entity.FilmSearchParams, locales []string) (films []*entity.FilmWithActors, err error)
+	GetListState(ctx *context.Context) (state *entity.ListState, err error)
+	GetFacets(ctx *context.Context, searchFields *entity.FilmSearchParams, facets []string) (buckets map[string][]*entity.FacetBucket, err error)
+	GetByID(ctx *context.Context, id int, asOf *time.Time, locales []string) (film *entity.FilmWithActors, err error)
+	GetByIDs(ctx *context.Context, ids []int, locales []string) (films []*entity.FilmWithActors, err error)
+	GetHistory(ctx *context.Context, id int, pagination *entity.PaginationParams) (revisions []*entity.Revision, err error)
+	Revert(ctx *context.Context, id int, revisionID int64, userID int) (film *entity.FilmWithActors, err error)
+	GetTrash(ctx *context.Context) (films []*entity.FilmWithActors, err error)
+	Restore(ctx *context.Context, id int, userID int) (err error)
 }
 
 type FilmHandler struct {
@@ -59,8 +72,15 @@ func (h *FilmHandler) Create() http.HandlerFunc {
 			return
 		}
 
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
 		ctx := context.Background()
-		film, err := h.filmUsecase.Create(&ctx, body)
+		film, err := h.filmUsecase.Create(&ctx, body, userID)
 		if err != nil {
 			switch err {
 			case repo.ErrActorNotFound:
@@ -72,8 +92,8 @@ func (h *FilmHandler) Create() http.HandlerFunc {
 			}
 			return
 		}
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(film)
+		w.Header().Set("ETag", formatETag(film.Version, nil, middleware.MediaTypeFromContext(r.Context())))
+		writeResponse(w, r, http.StatusCreated, film)
 		h.logger.Log(r, http.StatusCreated, nil)
 	}
 }
@@ -82,10 +102,12 @@ func (h *FilmHandler) Create() http.HandlerFunc {
 // @Description Update a film by id.
 // @Param id path integer true "Film ID"
 // @Param body body entity.FilmUpdateBody true "Update film body"
+// @Param If-Match header string false "ETag of the version the change is based on"
 // @Success 200 {}
 // @Failure 400 {object} RequestError
 // @Failure 401 {object} RequestError
 // @Failure 403 {object} RequestError
+// @Failure 412 {object} RequestError
 // @Failure 500 {object} RequestError
 // @Resource Films
 // @Route /api/films/{id}/ [patch]
@@ -115,8 +137,22 @@ func (h *FilmHandler) Update() http.HandlerFunc {
 			return
 		}
 
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		versions, err := parseIfMatch(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
 		ctx := context.Background()
-		err = h.filmUsecase.Update(&ctx, id, body)
+		err = h.filmUsecase.Update(&ctx, id, body, userID, versions)
 		if err != nil {
 			fmt.Println(err)
 			switch err {
@@ -126,6 +162,9 @@ func (h *FilmHandler) Update() http.HandlerFunc {
 			case repo.ErrActorNotFound:
 				h.logger.Log(r, http.StatusBadRequest, err)
 				returnError(w, http.StatusBadRequest, err)
+			case repo.ErrVersionMismatch:
+				h.logger.Log(r, http.StatusPreconditionFailed, err)
+				returnError(w, http.StatusPreconditionFailed, err)
 			default:
 				h.logger.Log(r, http.StatusInternalServerError, err)
 				returnError(w, http.StatusInternalServerError, ErrServerError)
@@ -140,10 +179,12 @@ func (h *FilmHandler) Update() http.HandlerFunc {
 // @Description Replace a film by id.
 // @Param id path integer true "Film ID"
 // @Param body body entity.FilmReplaceBody true "Replace film body"
+// @Param If-Match header string false "ETag of the version the change is based on"
 // @Success 200 {}
 // @Failure 400 {object} RequestError
 // @Failure 401 {object} RequestError
 // @Failure 403 {object} RequestError
+// @Failure 412 {object} RequestError
 // @Failure 500 {object} RequestError
 // @Resource Films
 // @Route /api/films/{id}/ [put]
@@ -173,8 +214,22 @@ func (h *FilmHandler) Replace() http.HandlerFunc {
 			return
 		}
 
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		versions, err := parseIfMatch(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
 		ctx := context.Background()
-		err = h.filmUsecase.Replace(&ctx, id, body)
+		err = h.filmUsecase.Replace(&ctx, id, body, userID, versions)
 		if err != nil {
 			switch err {
 			case repo.ErrFilmNotFound:
@@ -183,6 +238,9 @@ func (h *FilmHandler) Replace() http.HandlerFunc {
 			case repo.ErrActorNotFound:
 				h.logger.Log(r, http.StatusBadRequest, err)
 				returnError(w, http.StatusBadRequest, err)
+			case repo.ErrVersionMismatch:
+				h.logger.Log(r, http.StatusPreconditionFailed, err)
+				returnError(w, http.StatusPreconditionFailed, err)
 			default:
 				h.logger.Log(r, http.StatusInternalServerError, err)
 				returnError(w, http.StatusInternalServerError, ErrServerError)
@@ -194,12 +252,14 @@ func (h *FilmHandler) Replace() http.HandlerFunc {
 }
 
 // @Title Delete film
-// @Description Delete a film by id.
+// @Description Move a film to trash by id. Films in trash are permanently deleted after the retention period.
 // @Param id path integer true "Film ID"
+// @Param If-Match header string false "ETag of the version the change is based on"
 // @Success 204 {}
 // @Failure 400 {object} RequestError
 // @Failure 401 {object} RequestError
 // @Failure 403 {object} RequestError
+// @Failure 412 {object} RequestError
 // @Failure 500 {object} RequestError
 // @Resource Films
 // @Route /api/films/{id} [delete]
@@ -211,13 +271,30 @@ func (h *FilmHandler) Delete() http.HandlerFunc {
 			returnError(w, http.StatusBadRequest, err)
 			return
 		}
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		versions, err := parseIfMatch(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
 		ctx := context.Background()
-		err = h.filmUsecase.Delete(&ctx, id)
+		err = h.filmUsecase.Delete(&ctx, id, userID, versions)
 		if err != nil {
 			switch err {
 			case repo.ErrFilmNotFound:
 				h.logger.Log(r, http.StatusBadRequest, err)
 				returnError(w, http.StatusBadRequest, err)
+			case repo.ErrVersionMismatch:
+				h.logger.Log(r, http.StatusPreconditionFailed, err)
+				returnError(w, http.StatusPreconditionFailed, err)
 			default:
 				h.logger.Log(r, http.StatusInternalServerError, err)
 				returnError(w, http.StatusInternalServerError, ErrServerError)
@@ -230,13 +307,32 @@ func (h *FilmHandler) Delete() http.HandlerFunc {
 }
 
 // @Title Get all films
-// @Description Get all films with seaching and sorting params.
-// @Param sort_by query string true "Sort by field" Enums(title,rating,release_date)
-// @Param sort_order query string true "Sort order" Enums(asc,desc)
-// @Param title query string true "Search by title"
-// @Param actor_name query string true "Search by actor name"
+// @Description Get all films with seaching and sorting params. Titles and descriptions are translated to the most preferred available locale, falling back to the original ones.
+// @Param q query string false "Full-text search over title, description and cast names in English or Russian, including their translations, results are sorted by relevance by default"
+// @Param sort query string false "Comma-separated sort fields applied in order, prefix a field with - for descending order, e.g. -rating,title. Fields: title, rating, release_date, user_rating_avg, user_rating_count, actors_count, relevance (requires q). Ties are broken by id"
+// @Param sort_by query string false "Single sort field, used only if sort is omitted" Enums(title,rating,release_date,user_rating_avg,user_rating_count,actors_count,relevance)
+// @Param order query string false "Sort order of sort_by" Enums(asc,desc)
+// @Param title query string true "Search by title in any locale"
+// @Param actor_name query string true "Search by actor name in any locale or any of their aliases"
+// @Param in_watchlist query boolean false "Only films from the watchlist of the current user"
+// @Param collection query string false "Only films from the collection with this slug"
+// @Param facets query string false "Comma-separated facets to count over all matched films, the response becomes an object with items and facets" Enums(decade,rating,actor)
+// @Param country query string false "Only films released in the country, ISO 3166-1 alpha-2 code. Only releases and certifications in the country are returned"
+// @Param release_type query string false "Only films with a release of the type, in the country if it is set" Enums(theatrical,digital,festival)
+// @Param released_after query string false "Only films released on or after the date, e.g. 2024-03-01, in the country or of the release type if any of them is set"
+// @Param released_before query string false "Only films released on or before the date, partial dates include the whole period, e.g. 2023 includes December 31"
+// @Param certification_max query string false "Only films with at most this age certification in the country, e.g. 16+ for RU or PG-13 for US, requires country"
+// @Param lang query string false "Comma-separated preferred locales of titles and descriptions, Accept-Language is used if omitted"
+// @Param Accept-Language header string false "Preferred locales of titles and descriptions"
+// @Param If-None-Match header string false "ETag of a cached list, ignored with in_watchlist or collection"
+// @Param If-Modified-Since header string false "Last-Modified of a cached list, ignored with If-None-Match, in_watchlist or collection"
+// @Param Accept header string false "Media type of the list: application/json, application/msgpack, text/csv or application/x-ndjson, the latter two without facets"
 // @Success 200 {array} entity.FilmWithActors
+// @Success 200 {object} entity.FilmListWithFacets
+// @Success 304 {}
+// @Failure 400 {object} RequestError
 // @Failure 401 {object} RequestError
+// @Failure 406 {object} RequestError
 // @Failure 500 {object} RequestError
 // @Resource Films
 // @Route /api/films [get]
@@ -244,34 +340,130 @@ func (h *FilmHandler) GetAll() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// TODO: Refactor this method
 		query := r.URL.Query()
-		sortField := query.Get("sort_by")
-		if sortField == "" {
-			sortField = entity.FilmDefaultSortField
-		} else if !entity.IsValidParam("sort_field", sortField) {
-			h.logger.Log(r, http.StatusBadRequest, ErrInvalidSortByParam)
-			returnError(w, http.StatusBadRequest, ErrInvalidSortByParam)
+		searchQuery := query.Get("q")
+		sortParams, err := parseFilmSortParams(query, searchQuery != "")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
 			return
 		}
-		sortOrder := query.Get("order")
-		if sortOrder == "" {
-			sortOrder = entity.FilmDefaultSortOrder
-		} else if !entity.IsValidParam("sort_order", sortOrder) {
-			h.logger.Log(r, http.StatusBadRequest, ErrInvalidOrderParam)
-			returnError(w, http.StatusBadRequest, ErrInvalidOrderParam)
+		if sortParams.HasField(entity.FilmRelevanceSortField) && searchQuery == "" {
+			h.logger.Log(r, http.StatusBadRequest, ErrRelevanceWithoutQuery)
+			returnError(w, http.StatusBadRequest, ErrRelevanceWithoutQuery)
 			return
 		}
-		sortParams := &entity.FilmSortParams{
-			Field: sortField,
-			Order: sortOrder,
-		}
 		searchParams := &entity.FilmSearchParams{
-			Title:     query.Get("title"),
-			ActorName: query.Get("actor_name"),
+			Query:                searchQuery,
+			Title:                query.Get("title"),
+			ActorName:            query.Get("actor_name"),
+			Collection:           query.Get("collection"),
+			WithDraftCollections: isAdminRequest(r),
+		}
+		if inWatchlist := query.Get("in_watchlist"); inWatchlist != "" {
+			isInWatchlist, err := strconv.ParseBool(inWatchlist)
+			if err != nil {
+				h.logger.Log(r, http.StatusBadRequest, ErrInvalidInWatchlistParam)
+				returnError(w, http.StatusBadRequest, ErrInvalidInWatchlistParam)
+				return
+			}
+			if isInWatchlist {
+				searchParams.InWatchlistOf, err = extractUserID(r)
+				if err != nil {
+					h.logger.Log(r, http.StatusInternalServerError, err)
+					returnError(w, http.StatusInternalServerError, ErrServerError)
+					return
+				}
+			}
+		}
+		if err = parseFilmReleaseParams(query, searchParams); err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		var facets []string
+		if value := query.Get("facets"); value != "" {
+			for _, facet := range strings.Split(value, ",") {
+				facet = strings.TrimSpace(facet)
+				if !entity.IsValidParam("facet", facet) {
+					h.logger.Log(r, http.StatusBadRequest, ErrInvalidFacetsParam)
+					returnError(w, http.StatusBadRequest, ErrInvalidFacetsParam)
+					return
+				}
+				if !slices.Contains(facets, facet) {
+					facets = append(facets, facet)
+				}
+			}
+		}
+		if mediaType := middleware.MediaTypeFromContext(r.Context()); facets != nil && (mediaType == middleware.MediaTypeCSV || mediaType == middleware.MediaTypeNDJSON) {
+			h.logger.Log(r, http.StatusNotAcceptable, ErrFacetsNotAcceptable)
+			returnError(w, http.StatusNotAcceptable, ErrFacetsNotAcceptable)
+			return
+		}
+		locales, err := parseLocales(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		ctx := context.Background()
+		w.Header().Add("Vary", "Accept-Language")
+		if searchParams.InWatchlistOf != 0 || searchParams.Collection != "" {
+			// Watchlists and collections depend on the user and change without changing the list state.
+			w.Header().Set("Cache-Control", "private, no-cache")
+		} else {
+			state, err := h.filmUsecase.GetListState(&ctx)
+			if err != nil {
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+				return
+			}
+			if isListNotModified(w, r, state, locales) {
+				w.WriteHeader(http.StatusNotModified)
+				h.logger.Log(r, http.StatusNotModified, nil)
+				return
+			}
+		}
+		films, err := h.filmUsecase.GetAll(&ctx, sortParams, searchParams, locales)
+		if err != nil {
+			switch err {
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		if facets != nil {
+			buckets, err := h.filmUsecase.GetFacets(&ctx, searchParams, facets)
+			if err != nil {
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+				return
+			}
+			if films == nil {
+				films = []*entity.FilmWithActors{}
+			}
+			writeResponse(w, r, http.StatusOK, &entity.FilmListWithFacets{Items: films, Facets: buckets})
+			h.logger.Log(r, http.StatusOK, nil)
+			return
 		}
+		writeList(w, r, films, filmCSVHeader, filmCSVRecord)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Get films in trash
+// @Description Get all deleted films that were not purged yet, recently deleted first.
+// @Success 200 {array} entity.FilmWithActors
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Trash
+// @Route /api/trash/films [get]
+func (h *FilmHandler) GetTrash() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := context.Background()
-		films, err := h.filmUsecase.GetAll(&ctx, sortParams, searchParams)
+		films, err := h.filmUsecase.GetTrash(&ctx)
 		if err != nil {
-			fmt.Println(err)
 			switch err {
 			default:
 				h.logger.Log(r, http.StatusInternalServerError, err)
@@ -280,10 +472,277 @@ func (h *FilmHandler) GetAll() http.HandlerFunc {
 			return
 		}
 		if films == nil { // Handle empty films slice, return [] instead of nil
-			json.NewEncoder(w).Encode([]struct{}{})
+			writeResponse(w, r, http.StatusOK, []struct{}{})
 		} else {
-			json.NewEncoder(w).Encode(films)
+			writeResponse(w, r, http.StatusOK, films)
 		}
 		h.logger.Log(r, http.StatusOK, nil)
 	}
 }
+
+// @Title Restore film
+// @Description Restore a film from trash by id, together with its cast links.
+// @Param id path integer true "Film ID"
+// @Success 200 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Trash
+// @Route /api/films/{id}/restore [post]
+func (h *FilmHandler) Restore() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		ctx := context.Background()
+		err = h.filmUsecase.Restore(&ctx, id, userID)
+		if err != nil {
+			switch err {
+			case repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Get film
+// @Description Get a film by id. If as_of is provided, the film is returned as it was at that time. Title and description are translated to the most preferred available locale, falling back to the original ones.
+// @Param id path integer true "Film ID"
+// @Param as_of query string false "Point in time in RFC 3339 format, e.g. 2024-03-20T15:04:05Z"
+// @Param lang query string false "Comma-separated preferred locales of title and description, Accept-Language is used if omitted"
+// @Param Accept-Language header string false "Preferred locales of title and description"
+// @Param If-None-Match header string false "ETag of a cached version, ignored with as_of"
+// @Success 200 {object} entity.FilmWithActors
+// @Success 304 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 404 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Films
+// @Route /api/films/{id} [get]
+func (h *FilmHandler) GetByID() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		var asOf *time.Time
+		if asOfParam := r.URL.Query().Get("as_of"); asOfParam != "" {
+			parsed, err := time.Parse(time.RFC3339, asOfParam)
+			if err != nil {
+				h.logger.Log(r, http.StatusBadRequest, ErrInvalidAsOfParam)
+				returnError(w, http.StatusBadRequest, ErrInvalidAsOfParam)
+				return
+			}
+			asOf = &parsed
+		}
+		locales, err := parseLocales(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		film, err := h.filmUsecase.GetByID(&ctx, id, asOf, locales)
+		if err != nil {
+			switch err {
+			case repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusNotFound, err)
+				returnError(w, http.StatusNotFound, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		w.Header().Add("Vary", "Accept-Language")
+		if asOf == nil {
+			etag := formatETag(film.Version, locales, middleware.MediaTypeFromContext(r.Context()))
+			w.Header().Set("ETag", etag)
+			if isNotModified(r, etag) {
+				w.WriteHeader(http.StatusNotModified)
+				h.logger.Log(r, http.StatusNotModified, nil)
+				return
+			}
+		}
+		writeResponse(w, r, http.StatusOK, film)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Get film history
+// @Description Get changes of a film and its cast, newest first.
+// @Param id path integer true "Film ID"
+// @Param limit query integer false "Number of revisions per page, 20 by default"
+// @Param offset query integer false "Number of revisions to skip"
+// @Success 200 {array} entity.Revision
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Films
+// @Route /api/films/{id}/history [get]
+func (h *FilmHandler) GetHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		pagination, err := parsePaginationParams(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		revisions, err := h.filmUsecase.GetHistory(&ctx, id, pagination)
+		if err != nil {
+			switch err {
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		if revisions == nil { // Handle empty revisions slice, return [] instead of nil
+			writeResponse(w, r, http.StatusOK, []struct{}{})
+		} else {
+			writeResponse(w, r, http.StatusOK, revisions)
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Revert film
+// @Description Revert a film and its cast to the state from provided revision. Films in trash are restored.
+// @Param id path integer true "Film ID"
+// @Param revision_id path integer true "Revision ID"
+// @Success 200 {object} entity.FilmWithActors
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Films
+// @Route /api/films/{id}/revisions/{revision_id}/revert [post]
+func (h *FilmHandler) Revert() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		revisionID, err := extractPathParam(r, "revision_id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		ctx := context.Background()
+		film, err := h.filmUsecase.Revert(&ctx, id, int64(revisionID), userID)
+		if err != nil {
+			switch err {
+			case repo.ErrRevisionNotFound, repo.ErrFilmNotFound, repo.ErrActorNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusOK, film)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// Parse sort query parameter, falling back to sort_by and order ones.
+// Without any of them films are sorted by relevance for search queries and by rating otherwise.
+func parseFilmSortParams(query url.Values, isFullTextSearch bool) (sortParams *entity.FilmSortParams, err error) {
+	if spec := query.Get("sort"); spec != "" {
+		return entity.ParseFilmSortParams(spec)
+	}
+	key := entity.FilmSortKey{Field: entity.FilmDefaultSortField, Order: entity.FilmDefaultSortOrder}
+	if isFullTextSearch {
+		key.Field = entity.FilmRelevanceSortField
+	}
+	if sortField := query.Get("sort_by"); sortField != "" {
+		if !entity.IsValidParam("sort_field", sortField) {
+			return nil, ErrInvalidSortByParam
+		}
+		key.Field = entity.FilmSortField(sortField)
+	}
+	if sortOrder := query.Get("order"); sortOrder != "" {
+		if !entity.IsValidParam("sort_order", sortOrder) {
+			return nil, ErrInvalidOrderParam
+		}
+		key.Order = entity.SortOrder(sortOrder)
+	}
+	return &entity.FilmSortParams{Keys: []entity.FilmSortKey{key}}, nil
+}
+
+// Parse country, release_type, released_after, released_before and certification_max query parameters into search params.
+func parseFilmReleaseParams(query url.Values, searchParams *entity.FilmSearchParams) (err error) {
+	if country := query.Get("country"); country != "" {
+		searchParams.Country = strings.ToUpper(country)
+		if !entity.IsValidCountryCode(searchParams.Country) {
+			return ErrInvalidCountryParam
+		}
+	}
+	if releaseType := query.Get("release_type"); releaseType != "" {
+		searchParams.ReleaseType = entity.ReleaseType(releaseType)
+		if !entity.IsValidReleaseType(searchParams.ReleaseType) {
+			return ErrInvalidReleaseTypeParam
+		}
+	}
+	if releasedAfter := query.Get("released_after"); releasedAfter != "" {
+		if searchParams.ReleasedAfter, err = entity.ParseDate(releasedAfter); err != nil {
+			return ErrInvalidReleasedAfterParam
+		}
+	}
+	if releasedBefore := query.Get("released_before"); releasedBefore != "" {
+		if searchParams.ReleasedBefore, err = entity.ParseDate(releasedBefore); err != nil {
+			return ErrInvalidReleasedBeforeParam
+		}
+	}
+	if certificationMax := query.Get("certification_max"); certificationMax != "" {
+		if searchParams.Country == "" {
+			return ErrCertificationMaxWithoutCountry
+		}
+		rank, err := entity.CertificationRank(searchParams.Country, certificationMax)
+		if err != nil {
+			return err
+		}
+		searchParams.MaxCertificationRank = &rank
+	}
+	return
+}
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
+	"github.com/itmosha/vk-internship-2024/pkg/logger"
+)
+
+type FranchiseUsecaseInterface interface {
+	Create(ctx *context.Context, body *entity.FranchiseBody) (franchise *entity.Franchise, err error)
+	Replace(ctx *context.Context, id int, body *entity.FranchiseBody) (err error)
+	Delete(ctx *context.Context, id int) (err error)
+	GetAll(ctx *context.Context) (franchises []*entity.Franchise, err error)
+	GetByID(ctx *context.Context, id int) (franchise *entity.FranchiseWithFilms, err error)
+}
+
+type FranchiseHandler struct {
+	franchiseUsecase FranchiseUsecaseInterface
+	logger           *logger.Logger
+}
+
+// Create new FranchiseHandler.
+func NewFranchiseHandler(franchiseUsecase FranchiseUsecaseInterface, logger *logger.Logger) *FranchiseHandler {
+	return &FranchiseHandler{franchiseUsecase, logger}
+}
+
+// @Title Create franchise
+// @Description Create a new franchise, films_ids are in chronological order of the story.
+// @Param body body entity.FranchiseBody true "Franchise body"
+// @Success 201 {object} entity.Franchise
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Franchises
+// @Route /api/franchises/ [post]
+func (h *FranchiseHandler) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isEmptyBody(r) {
+			h.logger.Log(r, http.StatusBadRequest, ErrEmptyBody)
+			returnError(w, http.StatusBadRequest, ErrEmptyBody)
+			return
+		}
+		body, err := readBodyToStruct(r, &entity.FranchiseBody{})
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		err = entity.ValidateFranchiseBody(body)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		franchise, err := h.franchiseUsecase.Create(&ctx, body)
+		if err != nil {
+			switch err {
+			case repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusCreated, franchise)
+		h.logger.Log(r, http.StatusCreated, nil)
+	}
+}
+
+// @Title Replace franchise
+// @Description Replace name and films of a franchise by id, films_ids are in chronological order of the story.
+// @Param id path integer true "Franchise ID"
+// @Param body body entity.FranchiseBody true "Franchise body"
+// @Success 200 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Franchises
+// @Route /api/franchises/{id}/ [put]
+func (h *FranchiseHandler) Replace() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isEmptyBody(r) {
+			h.logger.Log(r, http.StatusBadRequest, ErrEmptyBody)
+			returnError(w, http.StatusBadRequest, ErrEmptyBody)
+			return
+		}
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		body, err := readBodyToStruct(r, &entity.FranchiseBody{})
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		err = entity.ValidateFranchiseBody(body)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		err = h.franchiseUsecase.Replace(&ctx, id, body)
+		if err != nil {
+			switch err {
+			case repo.ErrFranchiseNotFound, repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Delete franchise
+// @Description Delete a franchise by id, its films are kept.
+// @Param id path integer true "Franchise ID"
+// @Success 204 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Franchises
+// @Route /api/franchises/{id} [delete]
+func (h *FranchiseHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		err = h.franchiseUsecase.Delete(&ctx, id)
+		if err != nil {
+			switch err {
+			case repo.ErrFranchiseNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		h.logger.Log(r, http.StatusNoContent, nil)
+	}
+}
+
+// @Title Get all franchises
+// @Description Get all franchises ordered by name.
+// @Success 200 {array} entity.Franchise
+// @Failure 401 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Franchises
+// @Route /api/franchises [get]
+func (h *FranchiseHandler) GetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.Background()
+		franchises, err := h.franchiseUsecase.GetAll(&ctx)
+		if err != nil {
+			switch err {
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		if franchises == nil { // Handle empty franchises slice, return [] instead of nil
+			writeResponse(w, r, http.StatusOK, []struct{}{})
+		} else {
+			writeResponse(w, r, http.StatusOK, franchises)
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Get franchise
+// @Description Get a franchise by id with its films in chronological order of the story and in release order.
+// @Param id path integer true "Franchise ID"
+// @Success 200 {object} entity.FranchiseWithFilms
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 404 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Franchises
+// @Route /api/franchises/{id} [get]
+func (h *FranchiseHandler) GetByID() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		franchise, err := h.franchiseUsecase.GetByID(&ctx, id)
+		if err != nil {
+			switch err {
+			case repo.ErrFranchiseNotFound:
+				h.logger.Log(r, http.StatusNotFound, err)
+				returnError(w, http.StatusNotFound, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusOK, franchise)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
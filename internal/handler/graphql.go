@@ -0,0 +1,573 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/internal/http_server/middleware"
+	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
+	"github.com/itmosha/vk-internship-2024/pkg/dataloader"
+	"github.com/itmosha/vk-internship-2024/pkg/graphql"
+	"github.com/itmosha/vk-internship-2024/pkg/logger"
+)
+
+type GraphQLHandler struct {
+	filmUsecase      FilmUsecaseInterface
+	actorUsecase     ActorUsecaseInterface
+	watchlistUsecase WatchlistUsecaseInterface
+	logger           *logger.Logger
+	schema           *graphql.Schema
+}
+
+// Create new GraphQLHandler.
+func NewGraphQLHandler(filmUsecase FilmUsecaseInterface, actorUsecase ActorUsecaseInterface, watchlistUsecase WatchlistUsecaseInterface, logger *logger.Logger) *GraphQLHandler {
+	h := &GraphQLHandler{filmUsecase: filmUsecase, actorUsecase: actorUsecase, watchlistUsecase: watchlistUsecase, logger: logger}
+	h.schema = h.newSchema()
+	return h
+}
+
+// GraphQL request body, variables are decoded keeping numbers exact.
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// @Title Execute GraphQL request
+// @Description Execute a GraphQL query or mutation over films, actors and the current user. Mutations require admin rights.
+// @Param body body GraphQLRequest true "GraphQL request"
+// @Success 200 {object} graphql.Result
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Resource GraphQL
+// @Route /graphql [post]
+func (h *GraphQLHandler) Post() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isEmptyBody(r) {
+			h.logger.Log(r, http.StatusBadRequest, ErrEmptyBody)
+			returnError(w, http.StatusBadRequest, ErrEmptyBody)
+			return
+		}
+		body := &GraphQLRequest{}
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+		if err := decoder.Decode(body); err != nil {
+			h.logger.Log(r, http.StatusBadRequest, ErrDecodeBody)
+			returnError(w, http.StatusBadRequest, ErrDecodeBody)
+			return
+		}
+		h.execute(w, r, body, false)
+	}
+}
+
+// @Title Execute GraphQL query
+// @Description Execute a GraphQL query passed in query parameters, mutations are not allowed.
+// @Param query query string true "GraphQL query"
+// @Param operationName query string false "Name of the operation to execute"
+// @Param variables query string false "JSON object of variables"
+// @Success 200 {object} graphql.Result
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Resource GraphQL
+// @Route /graphql [get]
+func (h *GraphQLHandler) Get() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		body := &GraphQLRequest{Query: query.Get("query"), OperationName: query.Get("operationName")}
+		if variables := query.Get("variables"); variables != "" {
+			decoder := json.NewDecoder(strings.NewReader(variables))
+			decoder.UseNumber()
+			if err := decoder.Decode(&body.Variables); err != nil {
+				h.logger.Log(r, http.StatusBadRequest, ErrInvalidVariablesParam)
+				returnError(w, http.StatusBadRequest, ErrInvalidVariablesParam)
+				return
+			}
+		}
+		h.execute(w, r, body, true)
+	}
+}
+
+func (h *GraphQLHandler) execute(w http.ResponseWriter, r *http.Request, body *GraphQLRequest, queryOnly bool) {
+	if strings.TrimSpace(body.Query) == "" {
+		h.logger.Log(r, http.StatusBadRequest, ErrEmptyGraphQLQuery)
+		returnError(w, http.StatusBadRequest, ErrEmptyGraphQLQuery)
+		return
+	}
+	locales, err := parseLocales(r)
+	if err != nil {
+		h.logger.Log(r, http.StatusBadRequest, err)
+		returnError(w, http.StatusBadRequest, err)
+		return
+	}
+	req := &graphqlRequest{r: r, locales: locales}
+	h.resetLoaders(req)
+	result := graphql.Do(graphql.Params{
+		Schema:        h.schema,
+		Query:         body.Query,
+		OperationName: body.OperationName,
+		Variables:     body.Variables,
+		Context:       context.WithValue(r.Context(), graphqlRequestKey{}, req),
+		QueryOnly:     queryOnly,
+	})
+	w.Header().Add("Vary", "Accept-Language")
+	writeResponse(w, r, http.StatusOK, result)
+	h.logger.Log(r, http.StatusOK, nil)
+}
+
+type graphqlRequestKey struct{}
+
+// State of a GraphQL request shared by its resolvers.
+type graphqlRequest struct {
+	r       *http.Request
+	locales []string
+	films   *dataloader.Loader[int, *entity.FilmWithActors]
+	actors  *dataloader.Loader[int, *entity.ActorWithFilms]
+}
+
+func requestFromParams(p graphql.ResolveParams) *graphqlRequest {
+	return p.Context.Value(graphqlRequestKey{}).(*graphqlRequest)
+}
+
+// Create empty loaders of films and actors, done after every mutation so that later fields see its changes.
+func (h *GraphQLHandler) resetLoaders(req *graphqlRequest) {
+	req.films = dataloader.New(func(ids []int) (map[int]*entity.FilmWithActors, error) {
+		ctx := context.Background()
+		films, err := h.filmUsecase.GetByIDs(&ctx, ids, req.locales)
+		if err != nil {
+			return nil, h.usecaseError(req, err)
+		}
+		byID := make(map[int]*entity.FilmWithActors, len(films))
+		for _, film := range films {
+			byID[film.ID] = film
+		}
+		return byID, nil
+	})
+	req.actors = dataloader.New(func(ids []int) (map[int]*entity.ActorWithFilms, error) {
+		ctx := context.Background()
+		actors, err := h.actorUsecase.GetByIDs(&ctx, ids, req.locales)
+		if err != nil {
+			return nil, h.usecaseError(req, err)
+		}
+		byID := make(map[int]*entity.ActorWithFilms, len(actors))
+		for _, actor := range actors {
+			byID[actor.ID] = actor
+		}
+		return byID, nil
+	})
+}
+
+// Keep errors that clients can act on, log and hide the others.
+func (h *GraphQLHandler) usecaseError(req *graphqlRequest, err error) error {
+	switch err {
+	case repo.ErrFilmNotFound, repo.ErrActorNotFound, repo.ErrVersionMismatch:
+		return err
+	}
+	h.logger.Log(req.r, http.StatusInternalServerError, err)
+	return ErrServerError
+}
+
+// Scalar of arbitrary JSON values, used for maps.
+var graphqlJSON = &graphql.Scalar{Name: "JSON"}
+
+func (h *GraphQLHandler) newSchema() *graphql.Schema {
+	image := &graphql.Object{Name: "Image", Fields: map[string]*graphql.Field{
+		"url":        {Type: graphql.String},
+		"width":      {Type: graphql.Int},
+		"height":     {Type: graphql.Int},
+		"thumbnails": {Type: graphqlJSON},
+	}}
+	film := &graphql.Object{Name: "Film", Fields: map[string]*graphql.Field{
+		"id":                {Type: graphql.Int},
+		"title":             {Type: graphql.String},
+		"description":       {Type: graphql.String},
+		"release_date":      {Type: graphql.String},
+		"rating":            {Type: graphql.Int},
+		"user_rating_avg":   {Type: graphql.Float},
+		"user_rating_count": {Type: graphql.Int},
+		"version":           {Type: graphql.Int},
+		"poster":            {Type: image},
+		"locale":            {Type: graphql.String},
+		"actors_ids":        {Type: &graphql.List{OfType: graphql.Int}},
+	}}
+	actor := &graphql.Object{Name: "Actor", Fields: map[string]*graphql.Field{
+		"id":          {Type: graphql.Int},
+		"name":        {Type: graphql.String},
+		"gender":      {Type: graphql.String},
+		"birth_date":  {Type: graphql.String},
+		"death_date":  {Type: graphql.String},
+		"biography":   {Type: graphql.String},
+		"birthplace":  {Type: graphql.String},
+		"nationality": {Type: graphql.String},
+		"aliases":     {Type: &graphql.List{OfType: graphql.String}},
+		"photo":       {Type: image},
+		"version":     {Type: graphql.Int},
+		"locale":      {Type: graphql.String},
+		"films_ids":   {Type: &graphql.List{OfType: graphql.Int}},
+	}}
+	film.Fields["cast"] = &graphql.Field{Type: &graphql.List{OfType: actor}, Resolve: h.resolveCast}
+	actor.Fields["filmography"] = &graphql.Field{Type: &graphql.List{OfType: film}, Resolve: h.resolveFilmography}
+	watchlistEntry := &graphql.Object{Name: "WatchlistEntry", Fields: map[string]*graphql.Field{
+		"film_id":  {Type: graphql.Int},
+		"added_at": {Type: graphql.String},
+		"film":     {Type: film, Resolve: h.resolveWatchlistFilm},
+	}}
+	user := &graphql.Object{Name: "User", Fields: map[string]*graphql.Field{
+		"id":        {Type: graphql.Int},
+		"username":  {Type: graphql.String},
+		"is_admin":  {Type: graphql.Boolean},
+		"watchlist": {Type: &graphql.List{OfType: watchlistEntry}, Resolve: h.resolveWatchlist},
+	}}
+
+	query := &graphql.Object{Name: "Query", Fields: map[string]*graphql.Field{
+		"films": {
+			Type: &graphql.List{OfType: film},
+			Args: []string{"q", "title", "actor_name", "in_watchlist", "collection", "franchise", "country",
+				"release_type", "released_after", "released_before", "certification_max", "sort"},
+			Resolve: h.resolveFilms,
+		},
+		"film":   {Type: film, Args: []string{"id"}, Resolve: h.resolveFilm},
+		"actors": {Type: &graphql.List{OfType: actor}, Resolve: h.resolveActors},
+		"actor":  {Type: actor, Args: []string{"id"}, Resolve: h.resolveActor},
+		"me":     {Type: user, Resolve: h.resolveMe},
+	}}
+	mutation := &graphql.Object{Name: "Mutation", Fields: map[string]*graphql.Field{
+		"create_film":  {Type: film, Args: []string{"input"}, Resolve: h.createFilm},
+		"update_film":  {Type: film, Args: []string{"id", "input", "version"}, Resolve: h.updateFilm},
+		"delete_film":  {Type: graphql.Boolean, Args: []string{"id", "version"}, Resolve: h.deleteFilm},
+		"create_actor": {Type: actor, Args: []string{"input"}, Resolve: h.createActor},
+		"update_actor": {Type: actor, Args: []string{"id", "input", "version"}, Resolve: h.updateActor},
+		"delete_actor": {Type: graphql.Boolean, Args: []string{"id", "version"}, Resolve: h.deleteActor},
+	}}
+	return &graphql.Schema{Query: query, Mutation: mutation}
+}
+
+// Get films with the filters and sorting of GET /api/films.
+func (h *GraphQLHandler) resolveFilms(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFromParams(p)
+	query := url.Values{}
+	for _, name := range []string{"q", "title", "actor_name", "collection", "country", "release_type", "released_after", "released_before", "certification_max", "sort"} {
+		value, ok, err := graphqlArg[string](p.Args, name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			query.Set(name, value)
+		}
+	}
+	searchQuery := query.Get("q")
+	sortParams, err := parseFilmSortParams(query, searchQuery != "")
+	if err != nil {
+		return nil, err
+	}
+	if sortParams.HasField(entity.FilmRelevanceSortField) && searchQuery == "" {
+		return nil, ErrRelevanceWithoutQuery
+	}
+	searchParams := &entity.FilmSearchParams{
+		Query:                searchQuery,
+		Title:                query.Get("title"),
+		ActorName:            query.Get("actor_name"),
+		Collection:           query.Get("collection"),
+		WithDraftCollections: isAdminRequest(req.r),
+	}
+	if searchParams.Franchise, _, err = graphqlArg[int](p.Args, "franchise"); err != nil {
+		return nil, err
+	}
+	isInWatchlist, _, err := graphqlArg[bool](p.Args, "in_watchlist")
+	if err != nil {
+		return nil, err
+	}
+	if isInWatchlist {
+		if searchParams.InWatchlistOf, err = extractUserID(req.r); err != nil {
+			return nil, h.usecaseError(req, err)
+		}
+	}
+	if err = parseFilmReleaseParams(query, searchParams); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	films, err := h.filmUsecase.GetAll(&ctx, sortParams, searchParams, req.locales)
+	if err != nil {
+		return nil, h.usecaseError(req, err)
+	}
+	for _, film := range films {
+		req.films.Prime(film.ID, film)
+	}
+	return films, nil
+}
+
+func (h *GraphQLHandler) resolveFilm(p graphql.ResolveParams) (interface{}, error) {
+	id, err := graphqlID(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	return loadThunk(requestFromParams(p).films.Load(id)), nil
+}
+
+func (h *GraphQLHandler) resolveActors(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFromParams(p)
+	ctx := context.Background()
+	actors, err := h.actorUsecase.GetAllWithFilms(&ctx, req.locales)
+	if err != nil {
+		return nil, h.usecaseError(req, err)
+	}
+	for _, actor := range actors {
+		req.actors.Prime(actor.ID, actor)
+	}
+	return actors, nil
+}
+
+func (h *GraphQLHandler) resolveActor(p graphql.ResolveParams) (interface{}, error) {
+	id, err := graphqlID(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	return loadThunk(requestFromParams(p).actors.Load(id)), nil
+}
+
+// Get the current user from the access token claims.
+func (h *GraphQLHandler) resolveMe(p graphql.ResolveParams) (interface{}, error) {
+	claims, ok := middleware.ClaimsFromContext(requestFromParams(p).r.Context())
+	if !ok {
+		return nil, ErrNoClaimsInContext
+	}
+	return &entity.User{ID: claims.ID, Username: claims.Username, IsAdmin: claims.IsAdmin}, nil
+}
+
+// Get actors of a film, loaded together for all films of the level.
+func (h *GraphQLHandler) resolveCast(p graphql.ResolveParams) (interface{}, error) {
+	load := requestFromParams(p).actors.LoadMany(p.Source.(*entity.FilmWithActors).ActorsIDs)
+	return graphql.Thunk(func() (interface{}, error) {
+		actors, err := load()
+		return compactLoaded(actors), err
+	}), nil
+}
+
+// Get films of an actor, loaded together for all actors of the level.
+func (h *GraphQLHandler) resolveFilmography(p graphql.ResolveParams) (interface{}, error) {
+	load := requestFromParams(p).films.LoadMany(p.Source.(*entity.ActorWithFilms).FilmsIDs)
+	return graphql.Thunk(func() (interface{}, error) {
+		films, err := load()
+		return compactLoaded(films), err
+	}), nil
+}
+
+func (h *GraphQLHandler) resolveWatchlist(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFromParams(p)
+	ctx := context.Background()
+	entries, err := h.watchlistUsecase.GetWatchlist(&ctx, p.Source.(*entity.User).ID)
+	if err != nil {
+		return nil, h.usecaseError(req, err)
+	}
+	return entries, nil
+}
+
+func (h *GraphQLHandler) resolveWatchlistFilm(p graphql.ResolveParams) (interface{}, error) {
+	return loadThunk(requestFromParams(p).films.Load(p.Source.(*entity.WatchlistEntry).FilmID)), nil
+}
+
+func (h *GraphQLHandler) createFilm(p graphql.ResolveParams) (interface{}, error) {
+	req, userID, err := h.mutationRequest(p)
+	if err != nil {
+		return nil, err
+	}
+	body, err := graphqlInput(p.Args, &entity.FilmCreateBody{})
+	if err != nil {
+		return nil, err
+	}
+	if err = entity.ValidateFilmCreateBody(body); err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	film, err := h.filmUsecase.Create(&ctx, body, userID)
+	if err != nil {
+		return nil, h.usecaseError(req, err)
+	}
+	h.resetLoaders(req)
+	return loadThunk(req.films.Load(film.ID)), nil
+}
+
+func (h *GraphQLHandler) updateFilm(p graphql.ResolveParams) (interface{}, error) {
+	req, userID, err := h.mutationRequest(p)
+	if err != nil {
+		return nil, err
+	}
+	id, versions, err := graphqlTarget(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	body, err := graphqlInput(p.Args, &entity.FilmUpdateBody{})
+	if err != nil {
+		return nil, err
+	}
+	if err = entity.ValidateFilmUpdateBody(body); err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if err = h.filmUsecase.Update(&ctx, id, body, userID, versions); err != nil {
+		return nil, h.usecaseError(req, err)
+	}
+	h.resetLoaders(req)
+	return loadThunk(req.films.Load(id)), nil
+}
+
+func (h *GraphQLHandler) deleteFilm(p graphql.ResolveParams) (interface{}, error) {
+	req, userID, err := h.mutationRequest(p)
+	if err != nil {
+		return nil, err
+	}
+	id, versions, err := graphqlTarget(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if err = h.filmUsecase.Delete(&ctx, id, userID, versions); err != nil {
+		return nil, h.usecaseError(req, err)
+	}
+	h.resetLoaders(req)
+	return true, nil
+}
+
+func (h *GraphQLHandler) createActor(p graphql.ResolveParams) (interface{}, error) {
+	req, userID, err := h.mutationRequest(p)
+	if err != nil {
+		return nil, err
+	}
+	body, err := graphqlInput(p.Args, &entity.ActorCreateBody{})
+	if err != nil {
+		return nil, err
+	}
+	if err = entity.ValidateActorCreateBody(body); err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	actor, err := h.actorUsecase.Create(&ctx, body, userID)
+	if err != nil {
+		return nil, h.usecaseError(req, err)
+	}
+	h.resetLoaders(req)
+	return loadThunk(req.actors.Load(actor.ID)), nil
+}
+
+func (h *GraphQLHandler) updateActor(p graphql.ResolveParams) (interface{}, error) {
+	req, userID, err := h.mutationRequest(p)
+	if err != nil {
+		return nil, err
+	}
+	id, versions, err := graphqlTarget(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	body, err := graphqlInput(p.Args, &entity.ActorUpdateBody{})
+	if err != nil {
+		return nil, err
+	}
+	if err = entity.ValidateActorUpdateBody(body); err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if err = h.actorUsecase.Update(&ctx, id, body, userID, versions); err != nil {
+		return nil, h.usecaseError(req, err)
+	}
+	h.resetLoaders(req)
+	return loadThunk(req.actors.Load(id)), nil
+}
+
+func (h *GraphQLHandler) deleteActor(p graphql.ResolveParams) (interface{}, error) {
+	req, userID, err := h.mutationRequest(p)
+	if err != nil {
+		return nil, err
+	}
+	id, versions, err := graphqlTarget(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if err = h.actorUsecase.Delete(&ctx, id, userID, versions); err != nil {
+		return nil, h.usecaseError(req, err)
+	}
+	h.resetLoaders(req)
+	return true, nil
+}
+
+// Check that the request may change films and actors, like admin routes do, and get the user id.
+func (h *GraphQLHandler) mutationRequest(p graphql.ResolveParams) (req *graphqlRequest, userID int, err error) {
+	req = requestFromParams(p)
+	if !isAdminRequest(req.r) {
+		return nil, 0, middleware.ErrNotEnoughPermissions
+	}
+	userID, err = extractUserID(req.r)
+	return
+}
+
+// Get an argument of type T, ok is false if it is absent or null.
+func graphqlArg[T any](args map[string]interface{}, name string) (value T, ok bool, err error) {
+	raw, present := args[name]
+	if !present || raw == nil {
+		return
+	}
+	if value, ok = raw.(T); !ok {
+		err = fmt.Errorf("%w: %s", ErrInvalidGraphQLArgument, name)
+	}
+	return
+}
+
+// Get required id argument.
+func graphqlID(args map[string]interface{}) (id int, err error) {
+	id, ok, err := graphqlArg[int](args, "id")
+	if err == nil && !ok {
+		err = fmt.Errorf("%w: id", ErrInvalidGraphQLArgument)
+	}
+	return
+}
+
+// Get id and optional version of the entity to change, the version works like If-Match header.
+func graphqlTarget(args map[string]interface{}) (id int, versions []int, err error) {
+	if id, err = graphqlID(args); err != nil {
+		return
+	}
+	version, ok, err := graphqlArg[int](args, "version")
+	if ok {
+		versions = []int{version}
+	}
+	return
+}
+
+// Decode input argument into a request body struct, like a JSON body of REST routes.
+func graphqlInput[T any](args map[string]interface{}, out *T) (*T, error) {
+	input, ok, err := graphqlArg[map[string]interface{}](args, "input")
+	if err != nil || !ok {
+		return nil, fmt.Errorf("%w: input", ErrInvalidGraphQLArgument)
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, ErrDecodeBody
+	}
+	if err = json.Unmarshal(data, out); err != nil {
+		return nil, ErrDecodeBody
+	}
+	return out, nil
+}
+
+// Wrap a load of a loader into a thunk resolved after the other fields of the level.
+func loadThunk[V any](load func() (V, error)) graphql.Thunk {
+	return func() (interface{}, error) {
+		return load()
+	}
+}
+
+// Drop entities that were not found by a loader.
+func compactLoaded[T any](values []*T) []*T {
+	compacted := make([]*T, 0, len(values))
+	for _, value := range values {
+		if value != nil {
+			compacted = append(compacted, value)
+		}
+	}
+	return compacted
+}
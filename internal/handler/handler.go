@@ -1,11 +1,19 @@
 package handler
 
 import (
+	"cmp"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"hash/fnv"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/internal/http_server/middleware"
 )
 
 var (
@@ -13,10 +21,59 @@ var (
 	ErrDecodeBody           = errors.New("could not decode request body")
 	ErrEmptyBody            = errors.New("empty request body")
 
-	ErrInvalidSortByParam   = errors.New("invalid sort_by query parameter, should be one of: title, rating, release_date")
+	ErrInvalidSortByParam   = errors.New("invalid sort_by query parameter, should be one of: title, rating, release_date, user_rating_avg, user_rating_count, actors_count, relevance")
 	ErrInvalidOrderParam    = errors.New("invalid order query parameter, should be one of: asc, desc")
 	ErrInvalidSearchByParam = errors.New("invalid search_by query parameter, should be one of: title, actor_name")
 
+	ErrRelevanceWithoutQuery = errors.New("sort_by=relevance requires q query parameter")
+
+	ErrInvalidSuggestLimitParam = errors.New("invalid limit query parameter, should be an integer from 1 to 20")
+
+	ErrInvalidInWatchlistParam = errors.New("invalid in_watchlist query parameter, should be boolean value")
+	ErrInvalidFacetsParam      = errors.New("invalid facets query parameter, should be a comma-separated list of decade, rating, actor (films have no genres)")
+	ErrFacetsNotAcceptable     = errors.New("facets can be returned only as application/json or application/msgpack")
+
+	ErrInvalidCountryParam            = errors.New("invalid country query parameter, should be an ISO 3166-1 alpha-2 country code")
+	ErrInvalidReleaseTypeParam        = errors.New("invalid release_type query parameter, should be one of: theatrical, digital, festival")
+	ErrInvalidReleasedAfterParam      = errors.New("invalid released_after query parameter, should be a date in format 2006-01-02, 2006-01 or 2006")
+	ErrInvalidReleasedBeforeParam     = errors.New("invalid released_before query parameter, should be a date in format 2006-01-02, 2006-01 or 2006")
+	ErrCertificationMaxWithoutCountry = errors.New("certification_max query parameter requires country query parameter")
+
+	ErrInvalidAsOfParam = errors.New("invalid as_of query parameter, should be a timestamp in RFC 3339 format")
+
+	ErrInvalidFromParam       = errors.New("invalid from query parameter, should be an actor id")
+	ErrInvalidToParam         = errors.New("invalid to query parameter, should be an actor id")
+	ErrInvalidMaxDegreesParam = errors.New("invalid max_degrees query parameter, should be an integer from 1 to 6")
+
+	ErrInvalidFromYearParam    = errors.New("invalid from_year query parameter, should be a positive integer")
+	ErrInvalidToYearParam      = errors.New("invalid to_year query parameter, should be a positive integer not less than from_year")
+	ErrInvalidStatsFormatParam = errors.New("invalid format query parameter, should be one of: json, csv")
+
+	ErrInvalidLimitParam  = errors.New("invalid limit query parameter, should be an integer from 1 to 100")
+	ErrInvalidOffsetParam = errors.New("invalid offset query parameter, should be a non-negative integer")
+
+	ErrInvalidIfMatchHeader = errors.New("invalid If-Match header, should be * or a list of ETags")
+
+	ErrInvalidImportFormatParam = errors.New("invalid format query parameter, should be one of: csv, ndjson")
+	ErrInvalidDryRunParam       = errors.New("invalid dry_run query parameter, should be boolean value")
+	ErrInvalidIgnoreIDsParam    = errors.New("invalid ignore_ids query parameter, should be boolean value")
+
+	ErrInvalidExportFormatParam = errors.New("invalid format query parameter, should be one of: ndjson, csv, zip")
+	ErrInvalidExportEntityParam = errors.New("invalid entity query parameter, should be one of: actors, films, cast, users")
+
+	ErrInvalidLangParam = errors.New("invalid lang query parameter, should be a comma-separated list of language tags like en, ru or pt-BR")
+
+	ErrInvalidMultipartBody = errors.New("invalid request body, should be multipart/form-data")
+	ErrMissingFilePart      = errors.New("request body has no file part")
+
+	ErrEmptyGraphQLQuery      = errors.New("empty GraphQL query")
+	ErrInvalidVariablesParam  = errors.New("invalid variables query parameter, should be a JSON object")
+	ErrInvalidGraphQLArgument = errors.New("invalid argument")
+
+	ErrReviewByAdmin = errors.New("admins can not review films")
+
+	ErrNoClaimsInContext = errors.New("could not get access token claims")
+
 	ErrServerError = errors.New("internal server error")
 )
 
@@ -46,6 +103,175 @@ func extractIDFromPath(path string) (id int, err error) {
 	return
 }
 
+// Get integer path parameter stored in the request context by the router.
+func extractPathParam(r *http.Request, name string) (value int, err error) {
+	raw, _ := r.Context().Value(name).(string)
+	value, err = strconv.Atoi(raw)
+	if err != nil {
+		err = ErrInvalidPathParameter
+	}
+	return
+}
+
+// Get id of the user that made the request.
+func extractUserID(r *http.Request) (id int, err error) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		err = ErrNoClaimsInContext
+		return
+	}
+	id = claims.ID
+	return
+}
+
+// Check if the request was made by an admin.
+func isAdminRequest(r *http.Request) bool {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	return ok && claims.IsAdmin
+}
+
+// Parse limit and offset query parameters.
+func parsePaginationParams(r *http.Request) (pagination *entity.PaginationParams, err error) {
+	query := r.URL.Query()
+	pagination = &entity.PaginationParams{Limit: entity.DefaultPaginationLimit}
+	if limit := query.Get("limit"); limit != "" {
+		pagination.Limit, err = strconv.Atoi(limit)
+		if err != nil || pagination.Limit < 1 || pagination.Limit > entity.MaxPaginationLimit {
+			return nil, ErrInvalidLimitParam
+		}
+	}
+	if offset := query.Get("offset"); offset != "" {
+		pagination.Offset, err = strconv.Atoi(offset)
+		if err != nil || pagination.Offset < 0 {
+			return nil, ErrInvalidOffsetParam
+		}
+	}
+	return
+}
+
+// Parse locales preferred by the client from lang query parameter or Accept-Language header, most preferred first.
+// Every locale is followed by its fallbacks, e.g. pt-br by pt. Empty locales mean original texts.
+func parseLocales(r *http.Request) (locales []string, err error) {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		preferred := []string{}
+		for _, tag := range strings.Split(lang, ",") {
+			locale, err := entity.ParseLocale(tag)
+			if err != nil {
+				return nil, ErrInvalidLangParam
+			}
+			preferred = append(preferred, locale)
+		}
+		return entity.LocaleFallbacks(preferred), nil
+	}
+	return entity.LocaleFallbacks(parseAcceptLanguage(r.Header.Get("Accept-Language"))), nil
+}
+
+// Parse Accept-Language header into language tags ordered by quality.
+// Invalid tags, wildcards and tags with zero quality are skipped.
+func parseAcceptLanguage(header string) (locales []string) {
+	type weightedLocale struct {
+		locale  string
+		quality float64
+	}
+	weighted := []weightedLocale{}
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		locale, err := entity.ParseLocale(tag)
+		if err != nil || quality <= 0 {
+			continue
+		}
+		weighted = append(weighted, weightedLocale{locale, quality})
+	}
+	slices.SortStableFunc(weighted, func(a, b weightedLocale) int {
+		return cmp.Compare(b.quality, a.quality)
+	})
+	for _, w := range weighted {
+		locales = append(locales, w.locale)
+	}
+	return
+}
+
+// Format an entity version, locales it is translated to and its media type as a strong ETag "<version>-<variant>".
+// Each representation gets its own tag, while If-Match compares versions only.
+func formatETag(version int, locales []string, mediaType string) string {
+	hash := fnv.New32a()
+	fmt.Fprintf(hash, "%s-%s", strings.Join(locales, ","), mediaType)
+	return `"` + strconv.Itoa(version) + "-" + strconv.FormatUint(uint64(hash.Sum32()), 16) + `"`
+}
+
+// Parse If-Match header into a list of acceptable versions, taken from ETags of any representation or bare versions.
+// Nil versions mean that the header is absent or equals *, so any version is acceptable.
+// Weak and foreign ETags never match, as If-Match requires strong comparison.
+func parseIfMatch(r *http.Request) (versions []int, err error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return
+	}
+	versions = []int{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			continue
+		}
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			return nil, ErrInvalidIfMatchHeader
+		}
+		value, _, _ := strings.Cut(tag[1:len(tag)-1], "-")
+		if version, err := strconv.Atoi(value); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	return
+}
+
+// Check if If-None-Match header matches provided ETag using weak comparison.
+func isNotModified(r *http.Request, etag string) bool {
+	header := strings.TrimSpace(r.Header.Get("If-None-Match"))
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Format a list state, locales the list is translated to and its media type as a weak ETag.
+func formatListETag(state *entity.ListState, locales []string, mediaType string) string {
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%d-%d-%s-%s", state.Count, state.UpdatedAt.UnixNano(), strings.Join(locales, ","), mediaType)
+	return `W/"` + strconv.FormatUint(hash.Sum64(), 16) + `"`
+}
+
+// Set ETag and Last-Modified of a list and check if the cached list is still valid.
+// If-Modified-Since is ignored if If-None-Match is present.
+func isListNotModified(w http.ResponseWriter, r *http.Request, state *entity.ListState, locales []string) bool {
+	etag := formatListETag(state, locales, middleware.MediaTypeFromContext(r.Context()))
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", state.UpdatedAt.UTC().Format(http.TimeFormat))
+	if r.Header.Get("If-None-Match") != "" {
+		return isNotModified(r, etag)
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !state.UpdatedAt.Truncate(time.Second).After(since)
+}
+
 func isEmptyBody(r *http.Request) bool {
 	return r.ContentLength == 0
 }
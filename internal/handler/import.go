@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/logger"
+)
+
+// Time allowed to receive an import and send its report, imports may take much longer than regular requests.
+const importTimeout = 30 * time.Minute
+
+type ImportUsecaseInterface interface {
+	ImportActors(ctx *context.Context, params *entity.ImportParams, reader io.Reader, userID int) (report *entity.ImportReport, err error)
+	ImportFilms(ctx *context.Context, params *entity.ImportParams, reader io.Reader, userID int) (report *entity.ImportReport, err error)
+}
+
+type ImportHandler struct {
+	importUsecase ImportUsecaseInterface
+	logger        *logger.Logger
+}
+
+// Create new ImportHandler.
+func NewImportHandler(importUsecase ImportUsecaseInterface, logger *logger.Logger) *ImportHandler {
+	return &ImportHandler{importUsecase, logger}
+}
+
+// @Title Import actors
+// @Description Import actors from CSV or NDJSON. Rows with id update existing actors, rows without id create new ones. CSV must have a header with columns id, name, gender, birth_date and may have columns death_date, biography, birthplace, nationality, aliases, external_ids, where aliases and external_ids are separated by semicolons and external ids are written as source=id.
+// @Param format query string false "Input format: csv or ndjson, detected from Content-Type if omitted"
+// @Param dry_run query boolean false "Validate and report without saving anything"
+// @Param ignore_ids query boolean false "Create every row as a new entity, matching cast by actors' names, e.g. for data exported from another database"
+// @Success 200 {object} entity.ImportReport
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Import
+// @Route /api/import/actors [post]
+func (h *ImportHandler) ImportActors() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isEmptyBody(r) {
+			h.logger.Log(r, http.StatusBadRequest, ErrEmptyBody)
+			returnError(w, http.StatusBadRequest, ErrEmptyBody)
+			return
+		}
+		params, err := parseImportParams(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		rc.SetReadDeadline(time.Now().Add(importTimeout))
+		rc.SetWriteDeadline(time.Now().Add(importTimeout))
+
+		ctx := context.Background()
+		report, err := h.importUsecase.ImportActors(&ctx, params, r.Body, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, entity.ErrMissingImportColumn), errors.Is(err, entity.ErrInvalidImportRow):
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusOK, report)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Import films
+// @Description Import films with their cast from CSV or NDJSON. Rows with id update existing films, rows without id create new ones. Cast is referenced by actors_ids, actors_names or both. CSV must have a header with columns id, title, description, release_date, rating, actors_ids, actors_names, where lists are separated by semicolons.
+// @Param format query string false "Input format: csv or ndjson, detected from Content-Type if omitted"
+// @Param dry_run query boolean false "Validate and report without saving anything"
+// @Param ignore_ids query boolean false "Create every row as a new entity, matching cast by actors' names, e.g. for data exported from another database"
+// @Success 200 {object} entity.ImportReport
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Import
+// @Route /api/import/films [post]
+func (h *ImportHandler) ImportFilms() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isEmptyBody(r) {
+			h.logger.Log(r, http.StatusBadRequest, ErrEmptyBody)
+			returnError(w, http.StatusBadRequest, ErrEmptyBody)
+			return
+		}
+		params, err := parseImportParams(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		rc.SetReadDeadline(time.Now().Add(importTimeout))
+		rc.SetWriteDeadline(time.Now().Add(importTimeout))
+
+		ctx := context.Background()
+		report, err := h.importUsecase.ImportFilms(&ctx, params, r.Body, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, entity.ErrMissingImportColumn), errors.Is(err, entity.ErrInvalidImportRow):
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusOK, report)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// Parse format, dry_run and ignore_ids query parameters. If format is omitted, it is detected from Content-Type header.
+func parseImportParams(r *http.Request) (params *entity.ImportParams, err error) {
+	query := r.URL.Query()
+	params = &entity.ImportParams{Format: query.Get("format")}
+	if params.Format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case "text/csv":
+			params.Format = entity.ImportFormatCSV
+		case "application/x-ndjson", "application/jsonl":
+			params.Format = entity.ImportFormatNDJSON
+		}
+	}
+	if params.Format != entity.ImportFormatCSV && params.Format != entity.ImportFormatNDJSON {
+		return nil, ErrInvalidImportFormatParam
+	}
+	if value := query.Get("dry_run"); value != "" {
+		params.DryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, ErrInvalidDryRunParam
+		}
+	}
+	if value := query.Get("ignore_ids"); value != "" {
+		params.IgnoreIDs, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, ErrInvalidIgnoreIDsParam
+		}
+	}
+	return
+}
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
+	"github.com/itmosha/vk-internship-2024/pkg/logger"
+)
+
+// Time allowed to receive an uploaded image, uploads may take longer than regular requests.
+const uploadTimeout = time.Minute
+
+type MediaUsecaseInterface interface {
+	UploadFilmPoster(ctx *context.Context, filmID int, reader io.Reader, userID int) (poster *entity.Image, err error)
+	DeleteFilmPoster(ctx *context.Context, filmID int, userID int) (err error)
+	UploadActorPhoto(ctx *context.Context, actorID int, reader io.Reader, userID int) (photo *entity.Image, err error)
+	DeleteActorPhoto(ctx *context.Context, actorID int, userID int) (err error)
+	OpenFile(ctx *context.Context, key string) (body io.ReadCloser, contentType string, err error)
+}
+
+type MediaHandler struct {
+	mediaUsecase MediaUsecaseInterface
+	logger       *logger.Logger
+}
+
+// Create new MediaHandler.
+func NewMediaHandler(mediaUsecase MediaUsecaseInterface, logger *logger.Logger) *MediaHandler {
+	return &MediaHandler{mediaUsecase, logger}
+}
+
+// @Title Upload film poster
+// @Description Upload a poster of a film as multipart/form-data with the image in file part, replacing the previous one. JPEG, PNG and GIF images are accepted, small, medium and large JPEG thumbnails are generated.
+// @Param id path integer true "Film ID"
+// @Param file formData file true "Poster image"
+// @Success 200 {object} entity.Image
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 413 {object} RequestError
+// @Failure 415 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Media
+// @Route /api/films/{id}/poster [post]
+func (h *MediaHandler) UploadFilmPoster() http.HandlerFunc {
+	return h.upload(h.mediaUsecase.UploadFilmPoster, repo.ErrFilmNotFound)
+}
+
+// @Title Delete film poster
+// @Description Delete a poster of a film with all its thumbnails.
+// @Param id path integer true "Film ID"
+// @Success 204 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Media
+// @Route /api/films/{id}/poster [delete]
+func (h *MediaHandler) DeleteFilmPoster() http.HandlerFunc {
+	return h.delete(h.mediaUsecase.DeleteFilmPoster, repo.ErrFilmNotFound)
+}
+
+// @Title Upload actor photo
+// @Description Upload a photo of an actor as multipart/form-data with the image in file part, replacing the previous one. JPEG, PNG and GIF images are accepted, small, medium and large JPEG thumbnails are generated.
+// @Param id path integer true "Actor ID"
+// @Param file formData file true "Photo image"
+// @Success 200 {object} entity.Image
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 413 {object} RequestError
+// @Failure 415 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Media
+// @Route /api/actors/{id}/photo [post]
+func (h *MediaHandler) UploadActorPhoto() http.HandlerFunc {
+	return h.upload(h.mediaUsecase.UploadActorPhoto, repo.ErrActorNotFound)
+}
+
+// @Title Delete actor photo
+// @Description Delete a photo of an actor with all its thumbnails.
+// @Param id path integer true "Actor ID"
+// @Success 204 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Media
+// @Route /api/actors/{id}/photo [delete]
+func (h *MediaHandler) DeleteActorPhoto() http.HandlerFunc {
+	return h.delete(h.mediaUsecase.DeleteActorPhoto, repo.ErrActorNotFound)
+}
+
+// @Title Get media file
+// @Description Get an uploaded image or its thumbnail stored in local storage. Files never change, so they may be cached forever.
+// @Param kind path string true "films or actors"
+// @Param id path integer true "Film or actor ID"
+// @Param image path string true "Image directory"
+// @Param file path string true "File name"
+// @Success 200 {file} file
+// @Failure 404 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Media
+// @Route /media/{kind}/{id}/{image}/{file} [get]
+func (h *MediaHandler) GetFile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		kind, _ := r.Context().Value("kind").(string)
+		id, _ := r.Context().Value("id").(string)
+		image, _ := r.Context().Value("image").(string)
+		file, _ := r.Context().Value("file").(string)
+		if kind != "films" && kind != "actors" {
+			h.logger.Log(r, http.StatusNotFound, repo.ErrImageNotFound)
+			returnError(w, http.StatusNotFound, repo.ErrImageNotFound)
+			return
+		}
+
+		ctx := context.Background()
+		body, contentType, err := h.mediaUsecase.OpenFile(&ctx, kind+"/"+id+"/"+image+"/"+file)
+		if err != nil {
+			switch err {
+			case repo.ErrImageNotFound:
+				h.logger.Log(r, http.StatusNotFound, err)
+				returnError(w, http.StatusNotFound, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		defer body.Close()
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		io.Copy(w, body)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// Build a handler that uploads an image for an entity with id from the path.
+func (h *MediaHandler) upload(uploadFunc func(ctx *context.Context, id int, reader io.Reader, userID int) (*entity.Image, error), errNotFound error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+		rc := http.NewResponseController(w)
+		rc.SetReadDeadline(time.Now().Add(uploadTimeout))
+		rc.SetWriteDeadline(time.Now().Add(uploadTimeout))
+
+		part, err := findFilePart(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		defer part.Close()
+
+		ctx := context.Background()
+		img, err := uploadFunc(&ctx, id, part, userID)
+		if err != nil {
+			switch {
+			case err == errNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			case errors.Is(err, entity.ErrImageTooLarge):
+				h.logger.Log(r, http.StatusRequestEntityTooLarge, err)
+				returnError(w, http.StatusRequestEntityTooLarge, err)
+			case errors.Is(err, entity.ErrUnsupportedImageType):
+				h.logger.Log(r, http.StatusUnsupportedMediaType, err)
+				returnError(w, http.StatusUnsupportedMediaType, err)
+			case errors.Is(err, entity.ErrInvalidImage), errors.Is(err, entity.ErrImageTooManyPixels):
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusOK, img)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// Build a handler that deletes an image of an entity with id from the path.
+func (h *MediaHandler) delete(deleteFunc func(ctx *context.Context, id int, userID int) error, errNotFound error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		ctx := context.Background()
+		err = deleteFunc(&ctx, id, userID)
+		if err != nil {
+			switch err {
+			case errNotFound, repo.ErrImageNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		h.logger.Log(r, http.StatusNoContent, nil)
+	}
+}
+
+// Find file part of a multipart/form-data request body, skipping other parts.
+func findFilePart(r *http.Request) (part *multipart.Part, err error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, ErrInvalidMultipartBody
+	}
+	for {
+		part, err = reader.NextPart()
+		if err == io.EOF {
+			return nil, ErrMissingFilePart
+		}
+		if err != nil {
+			return nil, ErrInvalidMultipartBody
+		}
+		if part.FormName() == "file" {
+			return
+		}
+		part.Close()
+	}
+}
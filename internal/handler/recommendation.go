@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
+	"github.com/itmosha/vk-internship-2024/pkg/logger"
+)
+
+type RecommendationUsecaseInterface interface {
+	GetSimilar(ctx *context.Context, filmID int, pagination *entity.PaginationParams) (films []*entity.FilmRecommendation, err error)
+	GetRecommendations(ctx *context.Context, userID int, pagination *entity.PaginationParams) (films []*entity.FilmRecommendation, err error)
+}
+
+type RecommendationHandler struct {
+	recommendationUsecase RecommendationUsecaseInterface
+	logger                *logger.Logger
+}
+
+// Create new RecommendationHandler.
+func NewRecommendationHandler(recommendationUsecase RecommendationUsecaseInterface, logger *logger.Logger) *RecommendationHandler {
+	return &RecommendationHandler{recommendationUsecase, logger}
+}
+
+// @Title Get similar films
+// @Description Get films sharing cast with a film, scored by cast overlap and rating proximity, most similar first. Films have no genres, so they are not compared by genre.
+// @Param id path integer true "Film ID"
+// @Param limit query integer false "Number of films per page, 20 by default"
+// @Param offset query integer false "Number of films to skip"
+// @Success 200 {array} entity.FilmRecommendation
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 404 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Recommendations
+// @Route /api/films/{id}/similar [get]
+func (h *RecommendationHandler) GetSimilar() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		pagination, err := parsePaginationParams(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		films, err := h.recommendationUsecase.GetSimilar(&ctx, id, pagination)
+		if err != nil {
+			switch err {
+			case repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusNotFound, err)
+				returnError(w, http.StatusNotFound, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		if films == nil { // Handle empty films slice, return [] instead of nil
+			writeResponse(w, r, http.StatusOK, []struct{}{})
+		} else {
+			writeResponse(w, r, http.StatusOK, films)
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Get recommendations
+// @Description Get films recommended to the current user, with the highest predicted rating first. Ratings are predicted from the user's reviews of similar films, rated and watched films are skipped. Users without such reviews get the best rated films.
+// @Param limit query integer false "Number of films per page, 20 by default"
+// @Param offset query integer false "Number of films to skip"
+// @Success 200 {array} entity.FilmRecommendation
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Me
+// @Route /api/me/recommendations [get]
+func (h *RecommendationHandler) GetRecommendations() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+		pagination, err := parsePaginationParams(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		films, err := h.recommendationUsecase.GetRecommendations(&ctx, userID, pagination)
+		if err != nil {
+			switch err {
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		if films == nil { // Handle empty films slice, return [] instead of nil
+			writeResponse(w, r, http.StatusOK, []struct{}{})
+		} else {
+			writeResponse(w, r, http.StatusOK, films)
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
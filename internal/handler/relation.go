@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
+	"github.com/itmosha/vk-internship-2024/pkg/logger"
+)
+
+type RelationUsecaseInterface interface {
+	Create(ctx *context.Context, filmID int, body *entity.FilmRelationBody) (relation *entity.FilmRelation, err error)
+	Delete(ctx *context.Context, filmID, relatedFilmID int) (err error)
+}
+
+type RelationHandler struct {
+	relationUsecase RelationUsecaseInterface
+	logger          *logger.Logger
+}
+
+// Create new RelationHandler.
+func NewRelationHandler(relationUsecase RelationUsecaseInterface, logger *logger.Logger) *RelationHandler {
+	return &RelationHandler{relationUsecase, logger}
+}
+
+// @Title Create film relation
+// @Description Relate a film to another film, e.g. mark it as a sequel_of the other one. Films can be related only once, self-references and cycles like a film being a sequel of its own sequel are rejected.
+// @Param id path integer true "Film ID"
+// @Param body body entity.FilmRelationBody true "Film relation body"
+// @Success 201 {object} entity.FilmRelation
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 409 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Relations
+// @Route /api/films/{id}/relations/ [post]
+func (h *RelationHandler) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isEmptyBody(r) {
+			h.logger.Log(r, http.StatusBadRequest, ErrEmptyBody)
+			returnError(w, http.StatusBadRequest, ErrEmptyBody)
+			return
+		}
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		body, err := readBodyToStruct(r, &entity.FilmRelationBody{})
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		err = entity.ValidateFilmRelationBody(body)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		relation, err := h.relationUsecase.Create(&ctx, id, body)
+		if err != nil {
+			switch err {
+			case entity.ErrSelfFilmRelation, repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			case repo.ErrNonUniqueFilmRelation, repo.ErrFilmRelationCycle:
+				h.logger.Log(r, http.StatusConflict, err)
+				returnError(w, http.StatusConflict, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusCreated, relation)
+		h.logger.Log(r, http.StatusCreated, nil)
+	}
+}
+
+// @Title Delete film relation
+// @Description Delete the relation between two films, whichever of them it was created on.
+// @Param id path integer true "Film ID"
+// @Param related_id path integer true "Related film ID"
+// @Success 204 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Relations
+// @Route /api/films/{id}/relations/{related_id} [delete]
+func (h *RelationHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		relatedID, err := extractPathParam(r, "related_id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		err = h.relationUsecase.Delete(&ctx, id, relatedID)
+		if err != nil {
+			switch err {
+			case repo.ErrFilmNotFound, repo.ErrFilmRelationNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		h.logger.Log(r, http.StatusNoContent, nil)
+	}
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
+	"github.com/itmosha/vk-internship-2024/pkg/logger"
+)
+
+type ReleaseUsecaseInterface interface {
+	GetFilmReleaseInfo(ctx *context.Context, filmID int) (info *entity.FilmReleaseInfo, err error)
+	ReplaceFilmReleaseInfo(ctx *context.Context, filmID int, info *entity.FilmReleaseInfo) (err error)
+}
+
+type ReleaseHandler struct {
+	releaseUsecase ReleaseUsecaseInterface
+	logger         *logger.Logger
+}
+
+// Create new ReleaseHandler.
+func NewReleaseHandler(releaseUsecase ReleaseUsecaseInterface, logger *logger.Logger) *ReleaseHandler {
+	return &ReleaseHandler{releaseUsecase, logger}
+}
+
+// @Title Get film releases
+// @Description Get release dates and age certifications of a film in all countries.
+// @Param id path integer true "Film ID"
+// @Success 200 {object} entity.FilmReleaseInfo
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 404 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Releases
+// @Route /api/films/{id}/releases [get]
+func (h *ReleaseHandler) GetFilmReleaseInfo() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		info, err := h.releaseUsecase.GetFilmReleaseInfo(&ctx, id)
+		if err != nil {
+			switch err {
+			case repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusNotFound, err)
+				returnError(w, http.StatusNotFound, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusOK, info)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Replace film releases
+// @Description Replace release dates and age certifications of a film in all countries. A film may have one release of every type and one certification per country. Certifications are validated against the system of their country, supported countries are DE, FR, GB, RU and US.
+// @Param id path integer true "Film ID"
+// @Param body body entity.FilmReleaseInfoBody true "Film releases body"
+// @Success 200 {object} entity.FilmReleaseInfo
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Releases
+// @Route /api/films/{id}/releases [put]
+func (h *ReleaseHandler) ReplaceFilmReleaseInfo() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isEmptyBody(r) {
+			h.logger.Log(r, http.StatusBadRequest, ErrEmptyBody)
+			returnError(w, http.StatusBadRequest, ErrEmptyBody)
+			return
+		}
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		body, err := readBodyToStruct(r, &entity.FilmReleaseInfoBody{})
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		info, err := entity.ParseFilmReleaseInfoBody(body)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		err = h.releaseUsecase.ReplaceFilmReleaseInfo(&ctx, id, info)
+		if err != nil {
+			switch err {
+			case repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusOK, info)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
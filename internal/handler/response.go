@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/internal/http_server/middleware"
+	"github.com/itmosha/vk-internship-2024/pkg/msgpack"
+)
+
+// Number of NDJSON lines sent at once.
+const ndjsonFlushLines = 100
+
+// Write a value as JSON or MessagePack, as negotiated for the request.
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, value interface{}) {
+	if middleware.MediaTypeFromContext(r.Context()) == middleware.MediaTypeMsgpack {
+		data, err := msgpack.Marshal(value)
+		if err != nil {
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+		w.Header().Set("Content-Type", middleware.MediaTypeMsgpack)
+		w.WriteHeader(status)
+		w.Write(data)
+		return
+	}
+	w.Header().Set("Content-Type", middleware.MediaTypeJSON)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// Write a list as JSON or MessagePack array, as CSV with provided header and records, or as NDJSON streamed in batches of lines.
+func writeList[T any](w http.ResponseWriter, r *http.Request, items []T, header []string, record func(item T) []string) {
+	switch middleware.MediaTypeFromContext(r.Context()) {
+	case middleware.MediaTypeCSV:
+		w.Header().Set("Content-Type", middleware.MediaTypeCSV+"; charset=utf-8")
+		csvWriter := csv.NewWriter(w)
+		csvWriter.Write(header)
+		for _, item := range items {
+			csvWriter.Write(record(item))
+		}
+		csvWriter.Flush()
+	case middleware.MediaTypeNDJSON:
+		w.Header().Set("Content-Type", middleware.MediaTypeNDJSON)
+		encoder := json.NewEncoder(w)
+		controller := http.NewResponseController(w)
+		for i, item := range items {
+			if err := encoder.Encode(item); err != nil {
+				return
+			}
+			if (i+1)%ndjsonFlushLines == 0 {
+				controller.Flush()
+			}
+		}
+	default:
+		if items == nil { // Encode empty list as [] instead of null
+			items = []T{}
+		}
+		writeResponse(w, r, http.StatusOK, items)
+	}
+}
+
+// CSV columns of lists of films and actors.
+var (
+	filmCSVHeader  = []string{"id", "title", "description", "release_date", "rating", "user_rating_avg", "user_rating_count", "actors_ids", "version", "locale"}
+	actorCSVHeader = []string{"id", "name", "gender", "birth_date", "death_date", "nationality", "films_ids", "version", "locale"}
+)
+
+func filmCSVRecord(film *entity.FilmWithActors) []string {
+	return []string{strconv.Itoa(film.ID), film.Title, film.Description, formatCSVDate(film.ReleaseDate), strconv.Itoa(film.Rating),
+		strconv.FormatFloat(film.UserRatingAvg, 'f', -1, 64), strconv.Itoa(film.UserRatingCount), formatCSVIDs(film.ActorsIDs),
+		strconv.Itoa(film.Version), film.Locale}
+}
+
+func actorCSVRecord(actor *entity.ActorWithFilms) []string {
+	return []string{strconv.Itoa(actor.ID), actor.Name, string(actor.Gender), formatCSVDate(actor.BirthDate), formatCSVDate(actor.DeathDate),
+		actor.Nationality, formatCSVIDs(actor.FilmsIDs), strconv.Itoa(actor.Version), actor.Locale}
+}
+
+// Format a date for CSV, unknown dates are empty.
+func formatCSVDate(date entity.Date) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.String()
+}
+
+// Format ids for CSV as a semicolon-separated list, like the exporter does.
+func formatCSVIDs(ids []int) string {
+	formatted := make([]string, len(ids))
+	for i, id := range ids {
+		formatted[i] = strconv.Itoa(id)
+	}
+	return strings.Join(formatted, ";")
+}
@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
+	"github.com/itmosha/vk-internship-2024/pkg/logger"
+)
+
+type ReviewUsecaseInterface interface {
+	Create(ctx *context.Context, filmID, userID int, body *entity.ReviewCreateBody) (review *entity.Review, err error)
+	Update(ctx *context.Context, filmID, userID int, body *entity.ReviewUpdateBody) (review *entity.Review, err error)
+	Delete(ctx *context.Context, filmID, userID int) (err error)
+	GetByFilmID(ctx *context.Context, filmID int, pagination *entity.PaginationParams) (reviews []*entity.Review, err error)
+}
+
+type ReviewHandler struct {
+	reviewUsecase ReviewUsecaseInterface
+	logger        *logger.Logger
+}
+
+// Create new ReviewHandler.
+func NewReviewHandler(reviewUsecase ReviewUsecaseInterface, logger *logger.Logger) *ReviewHandler {
+	return &ReviewHandler{reviewUsecase, logger}
+}
+
+// @Title Create review
+// @Description Rate and review a film. Each user can review a film only once, admins can not review films.
+// @Param id path integer true "Film ID"
+// @Param body body entity.ReviewCreateBody true "Create review body"
+// @Success 201 {object} entity.Review
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 409 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Reviews
+// @Route /api/films/{id}/reviews/ [post]
+func (h *ReviewHandler) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isEmptyBody(r) {
+			h.logger.Log(r, http.StatusBadRequest, ErrEmptyBody)
+			returnError(w, http.StatusBadRequest, ErrEmptyBody)
+			return
+		}
+		filmID, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+		if isAdminRequest(r) {
+			h.logger.Log(r, http.StatusForbidden, ErrReviewByAdmin)
+			returnError(w, http.StatusForbidden, ErrReviewByAdmin)
+			return
+		}
+		body, err := readBodyToStruct(r, &entity.ReviewCreateBody{})
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		err = entity.ValidateReviewCreateBody(body)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		review, err := h.reviewUsecase.Create(&ctx, filmID, userID, body)
+		if err != nil {
+			switch err {
+			case repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			case repo.ErrNonUniqueReview:
+				h.logger.Log(r, http.StatusConflict, err)
+				returnError(w, http.StatusConflict, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusCreated, review)
+		h.logger.Log(r, http.StatusCreated, nil)
+	}
+}
+
+// @Title Update review
+// @Description Update own review of a film.
+// @Param id path integer true "Film ID"
+// @Param body body entity.ReviewUpdateBody true "Update review body"
+// @Success 200 {object} entity.Review
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Reviews
+// @Route /api/films/{id}/reviews/ [patch]
+func (h *ReviewHandler) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isEmptyBody(r) {
+			h.logger.Log(r, http.StatusBadRequest, ErrEmptyBody)
+			returnError(w, http.StatusBadRequest, ErrEmptyBody)
+			return
+		}
+		filmID, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+		if isAdminRequest(r) {
+			h.logger.Log(r, http.StatusForbidden, ErrReviewByAdmin)
+			returnError(w, http.StatusForbidden, ErrReviewByAdmin)
+			return
+		}
+		body, err := readBodyToStruct(r, &entity.ReviewUpdateBody{})
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		err = entity.ValidateReviewUpdateBody(body)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		review, err := h.reviewUsecase.Update(&ctx, filmID, userID, body)
+		if err != nil {
+			switch err {
+			case repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			case repo.ErrReviewNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusOK, review)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Delete review
+// @Description Delete own review of a film.
+// @Param id path integer true "Film ID"
+// @Success 204 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Reviews
+// @Route /api/films/{id}/reviews/ [delete]
+func (h *ReviewHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filmID, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+		if isAdminRequest(r) {
+			h.logger.Log(r, http.StatusForbidden, ErrReviewByAdmin)
+			returnError(w, http.StatusForbidden, ErrReviewByAdmin)
+			return
+		}
+
+		ctx := context.Background()
+		err = h.reviewUsecase.Delete(&ctx, filmID, userID)
+		if err != nil {
+			switch err {
+			case repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			case repo.ErrReviewNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		h.logger.Log(r, http.StatusNoContent, nil)
+	}
+}
+
+// @Title Get film reviews
+// @Description Get film reviews, newest first.
+// @Param id path integer true "Film ID"
+// @Param limit query integer false "Number of reviews per page, 20 by default"
+// @Param offset query integer false "Number of reviews to skip"
+// @Success 200 {array} entity.Review
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Reviews
+// @Route /api/films/{id}/reviews [get]
+func (h *ReviewHandler) GetByFilmID() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filmID, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		pagination, err := parsePaginationParams(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		reviews, err := h.reviewUsecase.GetByFilmID(&ctx, filmID, pagination)
+		if err != nil {
+			switch err {
+			case repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		if reviews == nil { // Handle empty reviews slice, return [] instead of nil
+			writeResponse(w, r, http.StatusOK, []struct{}{})
+		} else {
+			writeResponse(w, r, http.StatusOK, reviews)
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/logger"
+)
+
+type SearchUsecaseInterface interface {
+	Suggest(ctx *context.Context, query string, limit int) (suggestions []*entity.Suggestion, err error)
+}
+
+type SearchHandler struct {
+	searchUsecase SearchUsecaseInterface
+	logger        *logger.Logger
+}
+
+// Create new SearchHandler.
+func NewSearchHandler(searchUsecase SearchUsecaseInterface, logger *logger.Logger) *SearchHandler {
+	return &SearchHandler{searchUsecase, logger}
+}
+
+// @Title Search suggestions
+// @Description Get films and actors with titles or names similar to the query, tolerating typos. Suggestions of both types are mixed and ordered by score.
+// @Param q query string true "Search query of length 2 to 100"
+// @Param limit query integer false "Number of suggestions from 1 to 20, 10 by default"
+// @Success 200 {array} entity.Suggestion
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Search
+// @Route /api/search/suggest [get]
+func (h *SearchHandler) Suggest() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		searchQuery := strings.TrimSpace(query.Get("q"))
+		err := entity.ValidateSuggestQuery(searchQuery)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		limit := entity.SuggestDefaultLimit
+		if limitParam := query.Get("limit"); limitParam != "" {
+			limit, err = strconv.Atoi(limitParam)
+			if err != nil || limit < 1 || limit > entity.SuggestMaxLimit {
+				h.logger.Log(r, http.StatusBadRequest, ErrInvalidSuggestLimitParam)
+				returnError(w, http.StatusBadRequest, ErrInvalidSuggestLimitParam)
+				return
+			}
+		}
+
+		ctx := context.Background()
+		suggestions, err := h.searchUsecase.Suggest(&ctx, searchQuery, limit)
+		if err != nil {
+			switch err {
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		if suggestions == nil { // Handle empty suggestions slice, return [] instead of nil
+			writeResponse(w, r, http.StatusOK, []struct{}{})
+		} else {
+			writeResponse(w, r, http.StatusOK, suggestions)
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/logger"
+)
+
+type StatsUsecaseInterface interface {
+	GetReport(ctx *context.Context, name string, params *entity.StatsParams) (report *entity.StatsReport, err error)
+}
+
+type StatsHandler struct {
+	statsUsecase StatsUsecaseInterface
+	logger       *logger.Logger
+}
+
+// Create new StatsHandler.
+func NewStatsHandler(statsUsecase StatsUsecaseInterface, logger *logger.Logger) *StatsHandler {
+	return &StatsHandler{statsUsecase, logger}
+}
+
+// @Title Get stats reports
+// @Description Get names of all catalog stats reports.
+// @Success 200 {array} string
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Resource Stats
+// @Route /api/stats [get]
+func (h *StatsHandler) GetReports() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeResponse(w, r, http.StatusOK, entity.StatsReports)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Get stats report
+// @Description Get a catalog stats report. Films are windowed by release year and actors by birth year. Reports built from aggregates have refreshed_at, aggregates are refreshed on a schedule.
+// @Param report path string true "Report: films_per_year, rating_distribution, prolific_actors, cast_size, actors_by_birth_decade or films_without_cast"
+// @Param from_year query integer false "First year of the window"
+// @Param to_year query integer false "Last year of the window"
+// @Param limit query integer false "Number of rows of prolific_actors and films_without_cast, 20 by default"
+// @Param offset query integer false "Number of rows of prolific_actors and films_without_cast to skip"
+// @Param format query string false "Output format: json or csv, json by default"
+// @Success 200 {object} entity.StatsReport
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 404 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Stats
+// @Route /api/stats/{report} [get]
+func (h *StatsHandler) GetReport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, _ := r.Context().Value("report").(string)
+		params, err := parseStatsParams(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		format := r.URL.Query().Get("format")
+		if format != "" && format != "json" && format != "csv" {
+			h.logger.Log(r, http.StatusBadRequest, ErrInvalidStatsFormatParam)
+			returnError(w, http.StatusBadRequest, ErrInvalidStatsFormatParam)
+			return
+		}
+
+		ctx := context.Background()
+		report, err := h.statsUsecase.GetReport(&ctx, name, params)
+		if err != nil {
+			switch err {
+			case entity.ErrUnknownStatsReport:
+				h.logger.Log(r, http.StatusNotFound, err)
+				returnError(w, http.StatusNotFound, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		if format == "csv" {
+			w.Header().Set("Content-Type", "text/csv")
+			w.Header().Set("Content-Disposition", `attachment; filename="`+report.Name+`.csv"`)
+			writeStatsReportCSV(w, report)
+		} else {
+			writeResponse(w, r, http.StatusOK, report)
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// Parse window and pagination of a stats report.
+func parseStatsParams(r *http.Request) (params *entity.StatsParams, err error) {
+	query := r.URL.Query()
+	params = &entity.StatsParams{}
+	if fromYear := query.Get("from_year"); fromYear != "" {
+		params.FromYear, err = strconv.Atoi(fromYear)
+		if err != nil || params.FromYear < 1 {
+			return nil, ErrInvalidFromYearParam
+		}
+	}
+	if toYear := query.Get("to_year"); toYear != "" {
+		params.ToYear, err = strconv.Atoi(toYear)
+		if err != nil || params.ToYear < 1 || params.ToYear < params.FromYear {
+			return nil, ErrInvalidToYearParam
+		}
+	}
+	params.Pagination, err = parsePaginationParams(r)
+	return
+}
+
+// Write report with a header of column names.
+func writeStatsReportCSV(w http.ResponseWriter, report *entity.StatsReport) {
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Write(report.Columns)
+	for _, row := range report.Rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			switch v := value.(type) {
+			case float64:
+				record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				record[i] = fmt.Sprint(v)
+			}
+		}
+		csvWriter.Write(record)
+	}
+	csvWriter.Flush()
+}
@@ -0,0 +1,310 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
+	"github.com/itmosha/vk-internship-2024/pkg/logger"
+)
+
+type TranslationUsecaseInterface interface {
+	GetFilmTranslations(ctx *context.Context, filmID int) (translations []*entity.FilmTranslation, err error)
+	PutFilmTranslation(ctx *context.Context, filmID int, locale string, body *entity.FilmTranslationBody) (translation *entity.FilmTranslation, err error)
+	DeleteFilmTranslation(ctx *context.Context, filmID int, locale string) (err error)
+	GetActorTranslations(ctx *context.Context, actorID int) (translations []*entity.ActorTranslation, err error)
+	PutActorTranslation(ctx *context.Context, actorID int, locale string, body *entity.ActorTranslationBody) (translation *entity.ActorTranslation, err error)
+	DeleteActorTranslation(ctx *context.Context, actorID int, locale string) (err error)
+}
+
+type TranslationHandler struct {
+	translationUsecase TranslationUsecaseInterface
+	logger             *logger.Logger
+}
+
+// Create new TranslationHandler.
+func NewTranslationHandler(translationUsecase TranslationUsecaseInterface, logger *logger.Logger) *TranslationHandler {
+	return &TranslationHandler{translationUsecase, logger}
+}
+
+// @Title Get film translations
+// @Description Get all translations of a film's title and description, ordered by locale.
+// @Param id path integer true "Film ID"
+// @Success 200 {array} entity.FilmTranslation
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 404 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Translations
+// @Route /api/films/{id}/translations [get]
+func (h *TranslationHandler) GetFilmTranslations() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		translations, err := h.translationUsecase.GetFilmTranslations(&ctx, id)
+		if err != nil {
+			switch err {
+			case repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusNotFound, err)
+				returnError(w, http.StatusNotFound, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		if translations == nil { // Handle empty translations slice, return [] instead of nil
+			writeResponse(w, r, http.StatusOK, []struct{}{})
+		} else {
+			writeResponse(w, r, http.StatusOK, translations)
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Put film translation
+// @Description Create or replace a translation of a film's title and description to a locale. Empty description falls back to the original one.
+// @Param id path integer true "Film ID"
+// @Param locale path string true "Language tag, e.g. en, ru or pt-BR"
+// @Param body body entity.FilmTranslationBody true "Film translation body"
+// @Success 200 {object} entity.FilmTranslation
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Translations
+// @Route /api/films/{id}/translations/{locale} [put]
+func (h *TranslationHandler) PutFilmTranslation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isEmptyBody(r) {
+			h.logger.Log(r, http.StatusBadRequest, ErrEmptyBody)
+			returnError(w, http.StatusBadRequest, ErrEmptyBody)
+			return
+		}
+		id, locale, err := extractTranslationPathParams(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		body, err := readBodyToStruct(r, &entity.FilmTranslationBody{})
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		err = entity.ValidateFilmTranslationBody(body)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		translation, err := h.translationUsecase.PutFilmTranslation(&ctx, id, locale, body)
+		if err != nil {
+			switch err {
+			case repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusOK, translation)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Delete film translation
+// @Description Delete a translation of a film to a locale.
+// @Param id path integer true "Film ID"
+// @Param locale path string true "Language tag, e.g. en, ru or pt-BR"
+// @Success 204 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Translations
+// @Route /api/films/{id}/translations/{locale} [delete]
+func (h *TranslationHandler) DeleteFilmTranslation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, locale, err := extractTranslationPathParams(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		err = h.translationUsecase.DeleteFilmTranslation(&ctx, id, locale)
+		if err != nil {
+			switch err {
+			case repo.ErrFilmNotFound, repo.ErrTranslationNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		h.logger.Log(r, http.StatusNoContent, nil)
+	}
+}
+
+// @Title Get actor translations
+// @Description Get all translations of an actor's name, ordered by locale.
+// @Param id path integer true "Actor ID"
+// @Success 200 {array} entity.ActorTranslation
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 404 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Translations
+// @Route /api/actors/{id}/translations [get]
+func (h *TranslationHandler) GetActorTranslations() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := extractPathParam(r, "id")
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		translations, err := h.translationUsecase.GetActorTranslations(&ctx, id)
+		if err != nil {
+			switch err {
+			case repo.ErrActorNotFound:
+				h.logger.Log(r, http.StatusNotFound, err)
+				returnError(w, http.StatusNotFound, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		if translations == nil { // Handle empty translations slice, return [] instead of nil
+			writeResponse(w, r, http.StatusOK, []struct{}{})
+		} else {
+			writeResponse(w, r, http.StatusOK, translations)
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Put actor translation
+// @Description Create or replace a translation of an actor's name to a locale.
+// @Param id path integer true "Actor ID"
+// @Param locale path string true "Language tag, e.g. en, ru or pt-BR"
+// @Param body body entity.ActorTranslationBody true "Actor translation body"
+// @Success 200 {object} entity.ActorTranslation
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Translations
+// @Route /api/actors/{id}/translations/{locale} [put]
+func (h *TranslationHandler) PutActorTranslation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isEmptyBody(r) {
+			h.logger.Log(r, http.StatusBadRequest, ErrEmptyBody)
+			returnError(w, http.StatusBadRequest, ErrEmptyBody)
+			return
+		}
+		id, locale, err := extractTranslationPathParams(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		body, err := readBodyToStruct(r, &entity.ActorTranslationBody{})
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		err = entity.ValidateActorTranslationBody(body)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		translation, err := h.translationUsecase.PutActorTranslation(&ctx, id, locale, body)
+		if err != nil {
+			switch err {
+			case repo.ErrActorNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusOK, translation)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Delete actor translation
+// @Description Delete a translation of an actor to a locale.
+// @Param id path integer true "Actor ID"
+// @Param locale path string true "Language tag, e.g. en, ru or pt-BR"
+// @Success 204 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 403 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Translations
+// @Route /api/actors/{id}/translations/{locale} [delete]
+func (h *TranslationHandler) DeleteActorTranslation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, locale, err := extractTranslationPathParams(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		err = h.translationUsecase.DeleteActorTranslation(&ctx, id, locale)
+		if err != nil {
+			switch err {
+			case repo.ErrActorNotFound, repo.ErrTranslationNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		h.logger.Log(r, http.StatusNoContent, nil)
+	}
+}
+
+// Get id and normalized locale path parameters of a translation.
+func extractTranslationPathParams(r *http.Request) (id int, locale string, err error) {
+	id, err = extractPathParam(r, "id")
+	if err != nil {
+		return
+	}
+	raw, _ := r.Context().Value("locale").(string)
+	locale, err = entity.ParseLocale(raw)
+	return
+}
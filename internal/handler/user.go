@@ -2,7 +2,6 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 
 	"github.com/itmosha/vk-internship-2024/internal/entity"
@@ -66,8 +65,7 @@ func (h *UserHandler) Register() http.HandlerFunc {
 			}
 			return
 		}
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(user)
+		writeResponse(w, r, http.StatusCreated, user)
 		h.logger.Log(r, http.StatusCreated, nil)
 	}
 }
@@ -113,7 +111,7 @@ func (h *UserHandler) Login() http.HandlerFunc {
 			}
 			return
 		}
-		json.NewEncoder(w).Encode(entity.UserLoginResponse{AccessToken: accessToken})
+		writeResponse(w, r, http.StatusOK, entity.UserLoginResponse{AccessToken: accessToken})
 		h.logger.Log(r, http.StatusOK, nil)
 	}
 }
@@ -0,0 +1,332 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
+	"github.com/itmosha/vk-internship-2024/pkg/logger"
+)
+
+type WatchlistUsecaseInterface interface {
+	AddToWatchlist(ctx *context.Context, userID int, body *entity.WatchlistAddBody) (entry *entity.WatchlistEntry, err error)
+	RemoveFromWatchlist(ctx *context.Context, userID, filmID int) (err error)
+	GetWatchlist(ctx *context.Context, userID int) (entries []*entity.WatchlistEntry, err error)
+	AddToWatched(ctx *context.Context, userID int, body *entity.WatchedAddBody) (entry *entity.WatchedEntry, err error)
+	RemoveFromWatched(ctx *context.Context, userID, filmID int, watchedOn string) (err error)
+	GetWatched(ctx *context.Context, userID int) (entries []*entity.WatchedEntry, err error)
+	GetStats(ctx *context.Context, userID int) (stats *entity.UserStats, err error)
+}
+
+type WatchlistHandler struct {
+	watchlistUsecase WatchlistUsecaseInterface
+	logger           *logger.Logger
+}
+
+// Create new WatchlistHandler.
+func NewWatchlistHandler(watchlistUsecase WatchlistUsecaseInterface, logger *logger.Logger) *WatchlistHandler {
+	return &WatchlistHandler{watchlistUsecase, logger}
+}
+
+// @Title Add film to watchlist
+// @Description Add a film to the watchlist of the current user.
+// @Param body body entity.WatchlistAddBody true "Add to watchlist body"
+// @Success 201 {object} entity.WatchlistEntry
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Me
+// @Route /api/me/watchlist/ [post]
+func (h *WatchlistHandler) AddToWatchlist() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isEmptyBody(r) {
+			h.logger.Log(r, http.StatusBadRequest, ErrEmptyBody)
+			returnError(w, http.StatusBadRequest, ErrEmptyBody)
+			return
+		}
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+		body, err := readBodyToStruct(r, &entity.WatchlistAddBody{})
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		err = entity.ValidateWatchlistAddBody(body)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		entry, err := h.watchlistUsecase.AddToWatchlist(&ctx, userID, body)
+		if err != nil {
+			switch err {
+			case repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusCreated, entry)
+		h.logger.Log(r, http.StatusCreated, nil)
+	}
+}
+
+// @Title Remove film from watchlist
+// @Description Remove a film from the watchlist of the current user.
+// @Param id path integer true "Film ID"
+// @Success 204 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Me
+// @Route /api/me/watchlist/{id} [delete]
+func (h *WatchlistHandler) RemoveFromWatchlist() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filmID, err := extractIDFromPath(r.URL.Path)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		ctx := context.Background()
+		err = h.watchlistUsecase.RemoveFromWatchlist(&ctx, userID, filmID)
+		if err != nil {
+			switch err {
+			case repo.ErrWatchlistEntryNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		h.logger.Log(r, http.StatusNoContent, nil)
+	}
+}
+
+// @Title Get watchlist
+// @Description Get the watchlist of the current user.
+// @Success 200 {array} entity.WatchlistEntry
+// @Failure 401 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Me
+// @Route /api/me/watchlist [get]
+func (h *WatchlistHandler) GetWatchlist() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		ctx := context.Background()
+		entries, err := h.watchlistUsecase.GetWatchlist(&ctx, userID)
+		if err != nil {
+			switch err {
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		if entries == nil { // Handle empty entries slice, return [] instead of nil
+			writeResponse(w, r, http.StatusOK, []struct{}{})
+		} else {
+			writeResponse(w, r, http.StatusOK, entries)
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Mark film as watched
+// @Description Add a film watched on a date to the watched list of the current user.
+// @Param body body entity.WatchedAddBody true "Add to watched body"
+// @Success 201 {object} entity.WatchedEntry
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Me
+// @Route /api/me/watched/ [post]
+func (h *WatchlistHandler) AddToWatched() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isEmptyBody(r) {
+			h.logger.Log(r, http.StatusBadRequest, ErrEmptyBody)
+			returnError(w, http.StatusBadRequest, ErrEmptyBody)
+			return
+		}
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+		body, err := readBodyToStruct(r, &entity.WatchedAddBody{})
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		err = entity.ValidateWatchedAddBody(body)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.Background()
+		entry, err := h.watchlistUsecase.AddToWatched(&ctx, userID, body)
+		if err != nil {
+			switch err {
+			case repo.ErrFilmNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusCreated, entry)
+		h.logger.Log(r, http.StatusCreated, nil)
+	}
+}
+
+// @Title Remove film from watched
+// @Description Remove a film from the watched list of the current user.
+// @Param id path integer true "Film ID"
+// @Param watched_on query string false "Remove only the view on this date, all views are removed by default"
+// @Success 204 {}
+// @Failure 400 {object} RequestError
+// @Failure 401 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Me
+// @Route /api/me/watched/{id} [delete]
+func (h *WatchlistHandler) RemoveFromWatched() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filmID, err := extractIDFromPath(r.URL.Path)
+		if err != nil {
+			h.logger.Log(r, http.StatusBadRequest, err)
+			returnError(w, http.StatusBadRequest, err)
+			return
+		}
+		watchedOn := r.URL.Query().Get("watched_on")
+		if watchedOn != "" {
+			date, err := entity.ParseFullDate(watchedOn)
+			if err != nil {
+				h.logger.Log(r, http.StatusBadRequest, entity.ErrInvalidWatchedOn)
+				returnError(w, http.StatusBadRequest, entity.ErrInvalidWatchedOn)
+				return
+			}
+			watchedOn = date.String()
+		}
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		ctx := context.Background()
+		err = h.watchlistUsecase.RemoveFromWatched(&ctx, userID, filmID, watchedOn)
+		if err != nil {
+			switch err {
+			case repo.ErrWatchedEntryNotFound:
+				h.logger.Log(r, http.StatusBadRequest, err)
+				returnError(w, http.StatusBadRequest, err)
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		h.logger.Log(r, http.StatusNoContent, nil)
+	}
+}
+
+// @Title Get watched films
+// @Description Get the watched list of the current user.
+// @Success 200 {array} entity.WatchedEntry
+// @Failure 401 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Me
+// @Route /api/me/watched [get]
+func (h *WatchlistHandler) GetWatched() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		ctx := context.Background()
+		entries, err := h.watchlistUsecase.GetWatched(&ctx, userID)
+		if err != nil {
+			switch err {
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		if entries == nil { // Handle empty entries slice, return [] instead of nil
+			writeResponse(w, r, http.StatusOK, []struct{}{})
+		} else {
+			writeResponse(w, r, http.StatusOK, entries)
+		}
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
+
+// @Title Get stats
+// @Description Get watching stats of the current user: films watched per year and favourite actors.
+// @Success 200 {object} entity.UserStats
+// @Failure 401 {object} RequestError
+// @Failure 500 {object} RequestError
+// @Resource Me
+// @Route /api/me/stats [get]
+func (h *WatchlistHandler) GetStats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := extractUserID(r)
+		if err != nil {
+			h.logger.Log(r, http.StatusInternalServerError, err)
+			returnError(w, http.StatusInternalServerError, ErrServerError)
+			return
+		}
+
+		ctx := context.Background()
+		stats, err := h.watchlistUsecase.GetStats(&ctx, userID)
+		if err != nil {
+			switch err {
+			default:
+				h.logger.Log(r, http.StatusInternalServerError, err)
+				returnError(w, http.StatusInternalServerError, ErrServerError)
+			}
+			return
+		}
+		writeResponse(w, r, http.StatusOK, stats)
+		h.logger.Log(r, http.StatusOK, nil)
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -16,36 +17,51 @@ var (
 	ErrNotEnoughPermissions   = errors.New("not enough permissions")
 )
 
+type contextKey string
+
+// Context key the access token claims are stored under.
+const claimsContextKey contextKey = "claims"
+
 // AuthMiddleware is a middleware to check authorization.
 func AuthMiddleware(isAdminRequired bool, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		accessToken := extractTokenFromHeader(req.Header.Get("Authorization"))
 		if accessToken == "" {
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(map[string]string{"message": ErrAccessTokenNotProvided.Error()})
+			writeError(w, http.StatusUnauthorized, ErrAccessTokenNotProvided)
 			return
 		}
 
 		claims, isExpired, err := jwtfuncs.ExtractAccessTokenClaims(accessToken)
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(map[string]string{"message": ErrInvalidAccessToken.Error()})
+			writeError(w, http.StatusUnauthorized, ErrInvalidAccessToken)
 			return
 		}
 		if isExpired {
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(map[string]string{"message": ErrAccessTokenExpired.Error()})
+			writeError(w, http.StatusUnauthorized, ErrAccessTokenExpired)
 			return
 		}
 		if isAdminRequired && !claims.IsAdmin {
-			w.WriteHeader(http.StatusForbidden)
-			json.NewEncoder(w).Encode(map[string]string{"message": ErrNotEnoughPermissions.Error()})
+			writeError(w, http.StatusForbidden, ErrNotEnoughPermissions)
 			return
 		}
-		next.ServeHTTP(w, req)
+		ctx := context.WithValue(req.Context(), claimsContextKey, claims)
+		next.ServeHTTP(w, req.WithContext(ctx))
 	}
 }
 
+// Get access token claims stored in the context by AuthMiddleware.
+func ClaimsFromContext(ctx context.Context) (claims *jwtfuncs.AccessTokenClaims, ok bool) {
+	claims, ok = ctx.Value(claimsContextKey).(*jwtfuncs.AccessTokenClaims)
+	return
+}
+
+// Write an error as JSON, like handlers do.
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", MediaTypeJSON)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+}
+
 // Extract token from "Authorization" header.
 func extractTokenFromHeader(header string) string {
 	parts := strings.Split(header, " ")
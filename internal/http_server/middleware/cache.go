@@ -0,0 +1,42 @@
+package middleware
+
+import "net/http"
+
+// CacheControlMiddleware sets Cache-Control of successful and not modified responses to policy,
+// unless the handler sets its own. Empty policy leaves responses as is.
+func CacheControlMiddleware(policy string, next http.HandlerFunc) http.HandlerFunc {
+	if policy == "" {
+		return next
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(&cacheControlWriter{ResponseWriter: w, policy: policy}, req)
+	}
+}
+
+// Response writer setting Cache-Control right before the header is written, when the status is known.
+type cacheControlWriter struct {
+	http.ResponseWriter
+	policy      string
+	wroteHeader bool
+}
+
+func (w *cacheControlWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if (status == http.StatusOK || status == http.StatusNotModified) && w.Header().Get("Cache-Control") == "" {
+			w.Header().Set("Cache-Control", w.policy)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *cacheControlWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *cacheControlWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Supported content codings, in order of preference.
+var contentEncodings = []string{"br", "gzip", "deflate"}
+
+// CompressionMiddleware compresses response bodies with brotli, gzip or deflate chosen by Accept-Encoding.
+// Images and archives, which are compressed already, empty responses and responses that set Content-Encoding are sent as is.
+// ETags of compressed responses are suffixed with the coding, e.g. "7-gzip", and If-None-Match is matched against them.
+func CompressionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(req.Header.Get("Accept-Encoding"))
+		if encoding == "" || req.Method == http.MethodHead {
+			next.ServeHTTP(w, req)
+			return
+		}
+		if ifNoneMatch := req.Header.Get("If-None-Match"); ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", decodeIfNoneMatch(ifNoneMatch, encoding))
+		}
+		cw := &compressionWriter{ResponseWriter: w, encoding: encoding}
+		defer cw.Close()
+		next.ServeHTTP(cw, req)
+	})
+}
+
+// Choose the most preferred supported coding with non-zero quality in Accept-Encoding, empty if there is none.
+func negotiateEncoding(acceptEncoding string) string {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			quality, _ = strconv.ParseFloat(q, 64)
+		}
+		qualities[strings.ToLower(strings.TrimSpace(coding))] = quality
+	}
+	best, bestQuality := "", 0.0
+	for _, encoding := range contentEncodings {
+		quality, ok := qualities[encoding]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+		if ok && quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+	return best
+}
+
+// Response writer that starts compression when the header is written, if the response is worth compressing.
+type compressionWriter struct {
+	http.ResponseWriter
+	encoding    string
+	compressor  io.WriteCloser
+	wroteHeader bool
+}
+
+func (w *compressionWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if isCompressible(status, w.Header()) {
+		w.Header().Set("Content-Encoding", w.encoding)
+		w.Header().Del("Content-Length")
+		switch w.encoding {
+		case "br":
+			w.compressor = brotli.NewWriter(w.ResponseWriter)
+		case "gzip":
+			w.compressor = gzip.NewWriter(w.ResponseWriter)
+		default: // HTTP deflate coding is the zlib format
+			w.compressor = zlib.NewWriter(w.ResponseWriter)
+		}
+	}
+	// Not modified responses stand for the compressed representation the client has cached.
+	if w.compressor != nil || status == http.StatusNotModified {
+		if etag := w.Header().Get("ETag"); etag != "" {
+			w.Header().Set("ETag", encodeETag(etag, w.encoding))
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *compressionWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(data))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.compressor != nil {
+		return w.compressor.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush compressed data buffered so far, used by streaming responses.
+func (w *compressionWriter) Flush() {
+	if flusher, ok := w.compressor.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Write the end of compressed stream.
+func (w *compressionWriter) Close() error {
+	if w.compressor == nil {
+		return nil
+	}
+	return w.compressor.Close()
+}
+
+func (w *compressionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Suffix an ETag with a content coding, so that compressed and identity representations never share a tag.
+func encodeETag(etag, encoding string) string {
+	if len(etag) < 2 || etag[len(etag)-1] != '"' {
+		return etag
+	}
+	return etag[:len(etag)-1] + "-" + encoding + `"`
+}
+
+// Turn ETags of representations compressed with the coding into ones set by handlers and drop the rest of If-None-Match,
+// as they are of representations that are not sent with the coding. If none is left, the empty tag "" matching nothing is kept,
+// so that If-Modified-Since is still ignored.
+func decodeIfNoneMatch(header, encoding string) string {
+	if strings.TrimSpace(header) == "*" {
+		return header
+	}
+	suffix := "-" + encoding + `"`
+	decoded := []string{}
+	for _, tag := range strings.Split(header, ",") {
+		if tag, ok := strings.CutSuffix(strings.TrimSpace(tag), suffix); ok {
+			decoded = append(decoded, tag+`"`)
+		}
+	}
+	if len(decoded) == 0 {
+		return `""`
+	}
+	return strings.Join(decoded, ", ")
+}
+
+// Check if a response with the status and header has a body worth compressing.
+func isCompressible(status int, header http.Header) bool {
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified || header.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	switch {
+	case strings.HasPrefix(mediaType, "image/"), mediaType == "application/zip", mediaType == "application/gzip":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var ErrNotAcceptable = errors.New("none of media types in Accept header can be produced")
+
+// Media types of responses.
+const (
+	MediaTypeJSON    = "application/json"
+	MediaTypeMsgpack = "application/msgpack"
+	MediaTypeCSV     = "text/csv"
+	MediaTypeNDJSON  = "application/x-ndjson"
+)
+
+// Media types of single objects and of lists, the first one is the default.
+var (
+	ObjectMediaTypes = []string{MediaTypeJSON, MediaTypeMsgpack}
+	ListMediaTypes   = []string{MediaTypeJSON, MediaTypeMsgpack, MediaTypeCSV, MediaTypeNDJSON}
+)
+
+// Context key the negotiated media type is stored under.
+const mediaTypeContextKey contextKey = "media_type"
+
+// NegotiationMiddleware chooses the media type of the response from offers by Accept header.
+// If none of offers is acceptable, the request is rejected with 406 before it is handled.
+func NegotiationMiddleware(offers []string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		mediaType, ok := negotiateMediaType(req.Header.Get("Accept"), offers)
+		if !ok {
+			writeError(w, http.StatusNotAcceptable, fmt.Errorf("%w, acceptable types are: %s", ErrNotAcceptable, strings.Join(offers, ", ")))
+			return
+		}
+		ctx := context.WithValue(req.Context(), mediaTypeContextKey, mediaType)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	}
+}
+
+// Get the media type negotiated by NegotiationMiddleware, JSON if there was no negotiation.
+func MediaTypeFromContext(ctx context.Context) string {
+	if mediaType, ok := ctx.Value(mediaTypeContextKey).(string); ok {
+		return mediaType
+	}
+	return MediaTypeJSON
+}
+
+// Choose the offer with the highest quality in Accept header, earlier offers win ties.
+// Quality of an offer is taken from the most specific matching range: type/subtype, then type/*, then */*.
+func negotiateMediaType(accept string, offers []string) (mediaType string, ok bool) {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], true
+	}
+	bestQuality := 0.0
+	for _, offer := range offers {
+		quality, specificity := 0.0, -1
+		for _, part := range strings.Split(accept, ",") {
+			rangeType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			rangeSpecificity := mediaRangeSpecificity(rangeType, offer)
+			if rangeSpecificity <= specificity {
+				continue
+			}
+			specificity = rangeSpecificity
+			quality = 1
+			if q, ok := params["q"]; ok {
+				if quality, err = strconv.ParseFloat(q, 64); err != nil {
+					quality = 0
+				}
+			}
+		}
+		if quality > bestQuality {
+			mediaType, bestQuality, ok = offer, quality, true
+		}
+	}
+	return
+}
+
+// Get how specifically a media range matches a media type, -1 if it does not match.
+func mediaRangeSpecificity(mediaRange, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	}
+	return -1
+}
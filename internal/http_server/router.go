@@ -15,6 +15,11 @@ type FilmHandlerInterface interface {
 	Replace() http.HandlerFunc
 	Delete() http.HandlerFunc
 	GetAll() http.HandlerFunc
+	GetByID() http.HandlerFunc
+	GetHistory() http.HandlerFunc
+	Revert() http.HandlerFunc
+	GetTrash() http.HandlerFunc
+	Restore() http.HandlerFunc
 }
 
 // Actor handler interface.
@@ -23,7 +28,12 @@ type ActorHandlerInterface interface {
 	Update() http.HandlerFunc
 	Replace() http.HandlerFunc
 	Delete() http.HandlerFunc
+	GetByID() http.HandlerFunc
 	GetAllWithFilms() http.HandlerFunc
+	GetHistory() http.HandlerFunc
+	Revert() http.HandlerFunc
+	GetTrash() http.HandlerFunc
+	Restore() http.HandlerFunc
 }
 
 // User handler interface.
@@ -32,17 +42,131 @@ type UserHandlerInterface interface {
 	Login() http.HandlerFunc
 }
 
+// Review handler interface.
+type ReviewHandlerInterface interface {
+	Create() http.HandlerFunc
+	Update() http.HandlerFunc
+	Delete() http.HandlerFunc
+	GetByFilmID() http.HandlerFunc
+}
+
+// Watchlist handler interface.
+type WatchlistHandlerInterface interface {
+	AddToWatchlist() http.HandlerFunc
+	RemoveFromWatchlist() http.HandlerFunc
+	GetWatchlist() http.HandlerFunc
+	AddToWatched() http.HandlerFunc
+	RemoveFromWatched() http.HandlerFunc
+	GetWatched() http.HandlerFunc
+	GetStats() http.HandlerFunc
+}
+
+// Collection handler interface.
+type CollectionHandlerInterface interface {
+	Create() http.HandlerFunc
+	Update() http.HandlerFunc
+	Replace() http.HandlerFunc
+	Delete() http.HandlerFunc
+	GetAll() http.HandlerFunc
+	GetBySlug() http.HandlerFunc
+}
+
+// Import handler interface.
+type ImportHandlerInterface interface {
+	ImportActors() http.HandlerFunc
+	ImportFilms() http.HandlerFunc
+}
+
+// Export handler interface.
+type ExportHandlerInterface interface {
+	Export() http.HandlerFunc
+}
+
+// Search handler interface.
+type SearchHandlerInterface interface {
+	Suggest() http.HandlerFunc
+}
+
+// Media handler interface.
+type MediaHandlerInterface interface {
+	UploadFilmPoster() http.HandlerFunc
+	DeleteFilmPoster() http.HandlerFunc
+	UploadActorPhoto() http.HandlerFunc
+	DeleteActorPhoto() http.HandlerFunc
+	GetFile() http.HandlerFunc
+}
+
+// Translation handler interface.
+type TranslationHandlerInterface interface {
+	GetFilmTranslations() http.HandlerFunc
+	PutFilmTranslation() http.HandlerFunc
+	DeleteFilmTranslation() http.HandlerFunc
+	GetActorTranslations() http.HandlerFunc
+	PutActorTranslation() http.HandlerFunc
+	DeleteActorTranslation() http.HandlerFunc
+}
+
+// Release handler interface.
+type ReleaseHandlerInterface interface {
+	GetFilmReleaseInfo() http.HandlerFunc
+	ReplaceFilmReleaseInfo() http.HandlerFunc
+}
+
+// Relation handler interface.
+type RelationHandlerInterface interface {
+	Create() http.HandlerFunc
+	Delete() http.HandlerFunc
+}
+
+// Franchise handler interface.
+type FranchiseHandlerInterface interface {
+	Create() http.HandlerFunc
+	Replace() http.HandlerFunc
+	Delete() http.HandlerFunc
+	GetAll() http.HandlerFunc
+	GetByID() http.HandlerFunc
+}
+
+// Costar handler interface.
+type CostarHandlerInterface interface {
+	GetCostars() http.HandlerFunc
+	GetPath() http.HandlerFunc
+}
+
+// Recommendation handler interface.
+type RecommendationHandlerInterface interface {
+	GetSimilar() http.HandlerFunc
+	GetRecommendations() http.HandlerFunc
+}
+
+// Stats handler interface.
+type StatsHandlerInterface interface {
+	GetReports() http.HandlerFunc
+	GetReport() http.HandlerFunc
+}
+
+// Cache handler interface.
+type CacheHandlerInterface interface {
+	GetStats() http.HandlerFunc
+}
+
+// GraphQL handler interface.
+type GraphQLHandlerInterface interface {
+	Post() http.HandlerFunc
+	Get() http.HandlerFunc
+}
+
 // Router struct.
 type Router struct {
 	routes map[string]map[string]http.HandlerFunc
 }
 
 // Create new Router.
-func NewRouter(filmHandler FilmHandlerInterface, actorHandler ActorHandlerInterface, userHandler UserHandlerInterface) (router *Router) {
+func NewRouter(filmHandler FilmHandlerInterface, actorHandler ActorHandlerInterface, userHandler UserHandlerInterface, reviewHandler ReviewHandlerInterface, watchlistHandler WatchlistHandlerInterface, collectionHandler CollectionHandlerInterface, importHandler ImportHandlerInterface, exportHandler ExportHandlerInterface, searchHandler SearchHandlerInterface, mediaHandler MediaHandlerInterface, translationHandler TranslationHandlerInterface, releaseHandler ReleaseHandlerInterface, relationHandler RelationHandlerInterface, franchiseHandler FranchiseHandlerInterface, costarHandler CostarHandlerInterface, recommendationHandler RecommendationHandlerInterface, statsHandler StatsHandlerInterface, cacheHandler CacheHandlerInterface, graphqlHandler GraphQLHandlerInterface, cachePolicies map[string]string) (router *Router) {
 	router = &Router{routes: make(map[string]map[string]http.HandlerFunc)}
 
 	// Ping endpoint
-	router.HandleFunc("/ping", http.MethodGet, func(w http.ResponseWriter, req *http.Request) {
+	router.HandleRawFunc("/ping", http.MethodGet, func(w http.ResponseWriter, req *http.Request) {
 		w.Write([]byte("pong"))
 	})
 
@@ -51,14 +175,106 @@ func NewRouter(filmHandler FilmHandlerInterface, actorHandler ActorHandlerInterf
 	router.HandleFunc("/api/films/{id}/", http.MethodPatch, middleware.AuthMiddleware(true, filmHandler.Update()))
 	router.HandleFunc("/api/films/{id}/", http.MethodPut, middleware.AuthMiddleware(true, filmHandler.Replace()))
 	router.HandleFunc("/api/films/{id}", http.MethodDelete, middleware.AuthMiddleware(true, filmHandler.Delete()))
-	router.HandleFunc("/api/films", http.MethodGet, middleware.AuthMiddleware(false, filmHandler.GetAll()))
+	router.HandleListFunc("/api/films", http.MethodGet, middleware.CacheControlMiddleware(cachePolicies["/api/films"], middleware.AuthMiddleware(false, filmHandler.GetAll())))
+	router.HandleFunc("/api/films/{id}", http.MethodGet, middleware.CacheControlMiddleware(cachePolicies["/api/films/{id}"], middleware.AuthMiddleware(false, filmHandler.GetByID())))
+	router.HandleFunc("/api/films/{id}/history", http.MethodGet, middleware.AuthMiddleware(false, filmHandler.GetHistory()))
+	router.HandleFunc("/api/films/{id}/revisions/{revision_id}/revert", http.MethodPost, middleware.AuthMiddleware(true, filmHandler.Revert()))
+	router.HandleFunc("/api/films/{id}/restore", http.MethodPost, middleware.AuthMiddleware(true, filmHandler.Restore()))
 
 	// Actor endpoints
 	router.HandleFunc("/api/actors/", http.MethodPost, middleware.AuthMiddleware(true, actorHandler.Create()))
 	router.HandleFunc("/api/actors/{id}/", http.MethodPatch, middleware.AuthMiddleware(true, actorHandler.Update()))
 	router.HandleFunc("/api/actors/{id}/", http.MethodPut, middleware.AuthMiddleware(true, actorHandler.Replace()))
 	router.HandleFunc("/api/actors/{id}", http.MethodDelete, middleware.AuthMiddleware(true, actorHandler.Delete()))
-	router.HandleFunc("/api/actors", http.MethodGet, middleware.AuthMiddleware(false, actorHandler.GetAllWithFilms()))
+	router.HandleListFunc("/api/actors", http.MethodGet, middleware.CacheControlMiddleware(cachePolicies["/api/actors"], middleware.AuthMiddleware(false, actorHandler.GetAllWithFilms())))
+	router.HandleFunc("/api/actors/{id}", http.MethodGet, middleware.CacheControlMiddleware(cachePolicies["/api/actors/{id}"], middleware.AuthMiddleware(false, actorHandler.GetByID())))
+	router.HandleFunc("/api/actors/{id}/history", http.MethodGet, middleware.AuthMiddleware(false, actorHandler.GetHistory()))
+	router.HandleFunc("/api/actors/{id}/revisions/{revision_id}/revert", http.MethodPost, middleware.AuthMiddleware(true, actorHandler.Revert()))
+	router.HandleFunc("/api/actors/{id}/restore", http.MethodPost, middleware.AuthMiddleware(true, actorHandler.Restore()))
+	router.HandleFunc("/api/actors/{id}/costars", http.MethodGet, middleware.AuthMiddleware(false, costarHandler.GetCostars()))
+	router.HandleFunc("/api/actors/path", http.MethodGet, middleware.AuthMiddleware(false, costarHandler.GetPath()))
+
+	// Media endpoints
+	router.HandleFunc("/api/films/{id}/poster", http.MethodPost, middleware.AuthMiddleware(true, mediaHandler.UploadFilmPoster()))
+	router.HandleFunc("/api/films/{id}/poster", http.MethodDelete, middleware.AuthMiddleware(true, mediaHandler.DeleteFilmPoster()))
+	router.HandleFunc("/api/actors/{id}/photo", http.MethodPost, middleware.AuthMiddleware(true, mediaHandler.UploadActorPhoto()))
+	router.HandleFunc("/api/actors/{id}/photo", http.MethodDelete, middleware.AuthMiddleware(true, mediaHandler.DeleteActorPhoto()))
+	router.HandleRawFunc("/media/{kind}/{id}/{image}/{file}", http.MethodGet, mediaHandler.GetFile())
+
+	// Translation endpoints
+	router.HandleFunc("/api/films/{id}/translations", http.MethodGet, middleware.AuthMiddleware(false, translationHandler.GetFilmTranslations()))
+	router.HandleFunc("/api/films/{id}/translations/{locale}", http.MethodPut, middleware.AuthMiddleware(true, translationHandler.PutFilmTranslation()))
+	router.HandleFunc("/api/films/{id}/translations/{locale}", http.MethodDelete, middleware.AuthMiddleware(true, translationHandler.DeleteFilmTranslation()))
+	router.HandleFunc("/api/actors/{id}/translations", http.MethodGet, middleware.AuthMiddleware(false, translationHandler.GetActorTranslations()))
+	router.HandleFunc("/api/actors/{id}/translations/{locale}", http.MethodPut, middleware.AuthMiddleware(true, translationHandler.PutActorTranslation()))
+	router.HandleFunc("/api/actors/{id}/translations/{locale}", http.MethodDelete, middleware.AuthMiddleware(true, translationHandler.DeleteActorTranslation()))
+
+	// Release endpoints
+	router.HandleFunc("/api/films/{id}/releases", http.MethodGet, middleware.AuthMiddleware(false, releaseHandler.GetFilmReleaseInfo()))
+	router.HandleFunc("/api/films/{id}/releases", http.MethodPut, middleware.AuthMiddleware(true, releaseHandler.ReplaceFilmReleaseInfo()))
+
+	// Relation endpoints
+	router.HandleFunc("/api/films/{id}/relations/", http.MethodPost, middleware.AuthMiddleware(true, relationHandler.Create()))
+	router.HandleFunc("/api/films/{id}/relations/{related_id}", http.MethodDelete, middleware.AuthMiddleware(true, relationHandler.Delete()))
+
+	// Franchise endpoints
+	router.HandleFunc("/api/franchises/", http.MethodPost, middleware.AuthMiddleware(true, franchiseHandler.Create()))
+	router.HandleFunc("/api/franchises/{id}/", http.MethodPut, middleware.AuthMiddleware(true, franchiseHandler.Replace()))
+	router.HandleFunc("/api/franchises/{id}", http.MethodDelete, middleware.AuthMiddleware(true, franchiseHandler.Delete()))
+	router.HandleFunc("/api/franchises", http.MethodGet, middleware.AuthMiddleware(false, franchiseHandler.GetAll()))
+	router.HandleFunc("/api/franchises/{id}", http.MethodGet, middleware.AuthMiddleware(false, franchiseHandler.GetByID()))
+
+	// Recommendation endpoints
+	router.HandleFunc("/api/films/{id}/similar", http.MethodGet, middleware.AuthMiddleware(false, recommendationHandler.GetSimilar()))
+	router.HandleFunc("/api/me/recommendations", http.MethodGet, middleware.AuthMiddleware(false, recommendationHandler.GetRecommendations()))
+
+	// Trash endpoints
+	router.HandleFunc("/api/trash/films", http.MethodGet, middleware.AuthMiddleware(true, filmHandler.GetTrash()))
+	router.HandleFunc("/api/trash/actors", http.MethodGet, middleware.AuthMiddleware(true, actorHandler.GetTrash()))
+
+	// Stats endpoints
+	router.HandleFunc("/api/stats", http.MethodGet, middleware.AuthMiddleware(true, statsHandler.GetReports()))
+	router.HandleFunc("/api/stats/{report}", http.MethodGet, middleware.AuthMiddleware(true, statsHandler.GetReport()))
+
+	// Cache endpoints
+	router.HandleFunc("/api/cache", http.MethodGet, middleware.AuthMiddleware(true, cacheHandler.GetStats()))
+
+	// GraphQL endpoints
+	router.HandleFunc("/graphql", http.MethodPost, middleware.AuthMiddleware(false, graphqlHandler.Post()))
+	router.HandleFunc("/graphql", http.MethodGet, middleware.AuthMiddleware(false, graphqlHandler.Get()))
+
+	// Search endpoints
+	router.HandleFunc("/api/search/suggest", http.MethodGet, middleware.AuthMiddleware(false, searchHandler.Suggest()))
+
+	// Import endpoints
+	router.HandleFunc("/api/import/actors", http.MethodPost, middleware.AuthMiddleware(true, importHandler.ImportActors()))
+	router.HandleFunc("/api/import/films", http.MethodPost, middleware.AuthMiddleware(true, importHandler.ImportFilms()))
+
+	// Export endpoints
+	router.HandleRawFunc("/api/export", http.MethodGet, middleware.AuthMiddleware(true, exportHandler.Export()))
+
+	// Review endpoints
+	router.HandleFunc("/api/films/{id}/reviews/", http.MethodPost, middleware.AuthMiddleware(false, reviewHandler.Create()))
+	router.HandleFunc("/api/films/{id}/reviews/", http.MethodPatch, middleware.AuthMiddleware(false, reviewHandler.Update()))
+	router.HandleFunc("/api/films/{id}/reviews/", http.MethodDelete, middleware.AuthMiddleware(false, reviewHandler.Delete()))
+	router.HandleFunc("/api/films/{id}/reviews", http.MethodGet, middleware.AuthMiddleware(false, reviewHandler.GetByFilmID()))
+
+	// Collection endpoints
+	router.HandleFunc("/api/collections/", http.MethodPost, middleware.AuthMiddleware(true, collectionHandler.Create()))
+	router.HandleFunc("/api/collections/{id}/", http.MethodPatch, middleware.AuthMiddleware(true, collectionHandler.Update()))
+	router.HandleFunc("/api/collections/{id}/", http.MethodPut, middleware.AuthMiddleware(true, collectionHandler.Replace()))
+	router.HandleFunc("/api/collections/{id}", http.MethodDelete, middleware.AuthMiddleware(true, collectionHandler.Delete()))
+	router.HandleFunc("/api/collections", http.MethodGet, middleware.AuthMiddleware(false, collectionHandler.GetAll()))
+	router.HandleFunc("/api/collections/{slug}", http.MethodGet, middleware.AuthMiddleware(false, collectionHandler.GetBySlug()))
+
+	// Current user endpoints
+	router.HandleFunc("/api/me/watchlist/", http.MethodPost, middleware.AuthMiddleware(false, watchlistHandler.AddToWatchlist()))
+	router.HandleFunc("/api/me/watchlist/{id}", http.MethodDelete, middleware.AuthMiddleware(false, watchlistHandler.RemoveFromWatchlist()))
+	router.HandleFunc("/api/me/watchlist", http.MethodGet, middleware.AuthMiddleware(false, watchlistHandler.GetWatchlist()))
+	router.HandleFunc("/api/me/watched/", http.MethodPost, middleware.AuthMiddleware(false, watchlistHandler.AddToWatched()))
+	router.HandleFunc("/api/me/watched/{id}", http.MethodDelete, middleware.AuthMiddleware(false, watchlistHandler.RemoveFromWatched()))
+	router.HandleFunc("/api/me/watched", http.MethodGet, middleware.AuthMiddleware(false, watchlistHandler.GetWatched()))
+	router.HandleFunc("/api/me/stats", http.MethodGet, middleware.AuthMiddleware(false, watchlistHandler.GetStats()))
 
 	// User endpoints
 	router.HandleFunc("/api/auth/register/", http.MethodPost, userHandler.Register())
@@ -66,8 +282,18 @@ func NewRouter(filmHandler FilmHandlerInterface, actorHandler ActorHandlerInterf
 	return
 }
 
-// Register handler.
+// Register a handler of JSON responses, which can be sent as MessagePack as well.
 func (r *Router) HandleFunc(path string, method string, handler http.HandlerFunc) {
+	r.HandleRawFunc(path, method, middleware.NegotiationMiddleware(middleware.ObjectMediaTypes, handler))
+}
+
+// Register a handler of a list, which can be sent as CSV and NDJSON as well.
+func (r *Router) HandleListFunc(path string, method string, handler http.HandlerFunc) {
+	r.HandleRawFunc(path, method, middleware.NegotiationMiddleware(middleware.ListMediaTypes, handler))
+}
+
+// Register a handler that chooses media type of its responses itself.
+func (r *Router) HandleRawFunc(path string, method string, handler http.HandlerFunc) {
 	if _, ok := r.routes[path]; !ok {
 		r.routes[path] = make(map[string]http.HandlerFunc)
 	}
@@ -79,19 +305,21 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	path := req.URL.Path
 	method := req.Method
 
+	// Paths with fewer parameters are more specific, so /api/actors/path wins over /api/actors/{id}.
 	var handler http.HandlerFunc
+	var handlerParams map[string]string
 	for registeredPath, methodHandlers := range r.routes {
 		if matched, params := pathMatch(registeredPath, path); matched {
-			if h, ok := methodHandlers[method]; ok {
+			if h, ok := methodHandlers[method]; ok && (handler == nil || len(params) < len(handlerParams)) {
 				handler = h
-				for key, value := range params {
-					req = withValue(req, key, value)
-				}
-				break
+				handlerParams = params
 			}
 		}
 	}
 	if handler != nil {
+		for key, value := range handlerParams {
+			req = withValue(req, key, value)
+		}
 		handler(w, req)
 	} else {
 		http.NotFound(w, req)
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/internal/usecase"
+)
+
+// ActorRepo decorator caching the list of actors.
+type ActorRepoCache struct {
+	usecase.ActorRepoInterface
+	cache *ReadCache
+}
+
+// Create new ActorRepoCache over actorRepo.
+func NewActorRepoCache(actorRepo usecase.ActorRepoInterface, cache *ReadCache) *ActorRepoCache {
+	return &ActorRepoCache{actorRepo, cache}
+}
+
+// Insert a new Actor and invalidate the cache.
+func (r *ActorRepoCache) Insert(ctx *context.Context, receivedActor *entity.Actor) (createdActor *entity.Actor, err error) {
+	defer r.cache.Invalidate()
+	return r.ActorRepoInterface.Insert(ctx, receivedActor)
+}
+
+// Update an Actor and invalidate the cache.
+func (r *ActorRepoCache) Update(ctx *context.Context, id int, fields map[string]interface{}, versions []int) (err error) {
+	defer r.cache.Invalidate()
+	return r.ActorRepoInterface.Update(ctx, id, fields, versions)
+}
+
+// Move an Actor to trash and invalidate the cache.
+func (r *ActorRepoCache) Delete(ctx *context.Context, id int, versions []int) (err error) {
+	defer r.cache.Invalidate()
+	return r.ActorRepoInterface.Delete(ctx, id, versions)
+}
+
+// Restore an Actor from trash and invalidate the cache.
+func (r *ActorRepoCache) Restore(ctx *context.Context, id int) (err error) {
+	defer r.cache.Invalidate()
+	return r.ActorRepoInterface.Restore(ctx, id)
+}
+
+// Purge Actors from trash and invalidate the cache.
+func (r *ActorRepoCache) Purge(ctx *context.Context, retentionPeriod time.Duration) (cntPurged int64, err error) {
+	defer r.cache.Invalidate()
+	return r.ActorRepoInterface.Purge(ctx, retentionPeriod)
+}
+
+// Get all actors with their films, cached per list state. Actors are copied, so callers may modify them.
+func (r *ActorRepoCache) GetAllWithFilms(ctx *context.Context) (actors []*entity.ActorWithFilms, err error) {
+	state, err := r.ActorRepoInterface.SelectListState(ctx)
+	if err != nil {
+		return
+	}
+	key, err := cacheKey("actors", state)
+	if err != nil {
+		return
+	}
+	cached, generation, ok := r.cache.get(key)
+	if ok {
+		return copyActors(cached.([]*entity.ActorWithFilms)), nil
+	}
+	actors, err = r.ActorRepoInterface.GetAllWithFilms(ctx)
+	if err != nil {
+		return
+	}
+	r.cache.set(key, generation, copyActors(actors))
+	return
+}
+
+// Deep copy actors, so translations attached by callers do not leak into the cache.
+func copyActors(actors []*entity.ActorWithFilms) (copied []*entity.ActorWithFilms) {
+	if actors == nil {
+		return
+	}
+	copied = make([]*entity.ActorWithFilms, len(actors))
+	for i, actor := range actors {
+		actorCopy := *actor
+		actorCopy.Aliases = slices.Clone(actor.Aliases)
+		actorCopy.ExternalIDs = maps.Clone(actor.ExternalIDs)
+		actorCopy.Photo = copyImage(actor.Photo)
+		actorCopy.FilmsIDs = slices.Clone(actor.FilmsIDs)
+		actorCopy.DeletedAt = copyValue(actor.DeletedAt)
+		copied[i] = &actorCopy
+	}
+	return
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/lrucache"
+)
+
+// Read cache of list queries shared by repo decorators. Any write through a decorator invalidates all of it,
+// since lists of films embed their cast and lists of actors embed their films.
+type ReadCache struct {
+	enabled bool
+	lru     *lrucache.Cache[string, interface{}]
+	// Incremented on every invalidation, so that results of reads racing with a write are not stored.
+	generation atomic.Int64
+}
+
+// Create new ReadCache holding at most size lists for ttl each. Disabled cache passes every read through.
+func NewReadCache(enabled bool, size int, ttl time.Duration) *ReadCache {
+	return &ReadCache{enabled: enabled, lru: lrucache.New[string, interface{}](size, ttl)}
+}
+
+// Get a cached list by key and the generation to store a freshly read list with.
+func (c *ReadCache) get(key string) (value interface{}, generation int64, ok bool) {
+	if !c.enabled {
+		return
+	}
+	generation = c.generation.Load()
+	value, ok = c.lru.Get(key)
+	return
+}
+
+// Store a list read in provided generation, unless the cache was invalidated since.
+func (c *ReadCache) set(key string, generation int64, value interface{}) {
+	if !c.enabled || c.generation.Load() != generation {
+		return
+	}
+	c.lru.Set(key, value)
+}
+
+// Drop all cached lists.
+func (c *ReadCache) Invalidate() {
+	if !c.enabled {
+		return
+	}
+	c.generation.Add(1)
+	c.lru.Purge()
+}
+
+// Get usage counters.
+func (c *ReadCache) Stats() (stats *entity.CacheStats) {
+	lruStats := c.lru.Stats()
+	stats = &entity.CacheStats{
+		Enabled:       c.enabled,
+		Hits:          lruStats.Hits,
+		Misses:        lruStats.Misses,
+		Evictions:     lruStats.Evictions,
+		Invalidations: lruStats.Invalidations,
+		Size:          lruStats.Size,
+		Capacity:      lruStats.Capacity,
+	}
+	if lookups := lruStats.Hits + lruStats.Misses; lookups > 0 {
+		stats.HitRatio = float64(lruStats.Hits) / float64(lookups)
+	}
+	return
+}
+
+// Build a cache key from a query name and its params, params are encoded as JSON so that equal values give equal keys.
+func cacheKey(query string, params ...interface{}) (key string, err error) {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	key = query + ":" + string(encoded)
+	return
+}
+
+// Copy a pointed value, nil gives nil. Values must not hold slices or maps.
+func copyValue[T any](value *T) *T {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+	return &copied
+}
+
+// Copy a slice of pointers along with the pointed values, which must not hold slices or maps.
+func copyValues[T any](values []*T) (copied []*T) {
+	if values == nil {
+		return
+	}
+	copied = make([]*T, len(values))
+	for i, value := range values {
+		copied[i] = copyValue(value)
+	}
+	return
+}
+
+// Deep copy an image, nil gives nil.
+func copyImage(image *entity.Image) (copied *entity.Image) {
+	if image == nil {
+		return
+	}
+	copied = copyValue(image)
+	copied.Keys = slices.Clone(image.Keys)
+	copied.Thumbnails = maps.Clone(image.Thumbnails)
+	return
+}
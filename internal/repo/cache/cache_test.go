@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/internal/usecase"
+)
+
+// FilmRepo that counts list reads, other methods are not implemented.
+type testFilmRepo struct {
+	usecase.FilmRepoInterface
+	reads int
+	// Called in the middle of a list read, after it has got its data.
+	duringRead func()
+}
+
+func (r *testFilmRepo) SelectListState(ctx *context.Context) (state *entity.ListState, err error) {
+	return &entity.ListState{Count: 1, UpdatedAt: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)}, nil
+}
+
+func (r *testFilmRepo) GetAllWithActors(ctx *context.Context, sortParams *entity.FilmSortParams, searchParams *entity.FilmSearchParams) (films []*entity.FilmWithActors, err error) {
+	r.reads++
+	films = []*entity.FilmWithActors{{
+		ID:        1,
+		Title:     "Up",
+		ActorsIDs: []int{2, 3},
+		Poster:    &entity.Image{Keys: []string{"poster"}, Thumbnails: map[string]string{"small": "small.jpg"}},
+		Releases:  []*entity.FilmRelease{{Country: "US"}},
+	}}
+	if r.duringRead != nil {
+		r.duringRead()
+	}
+	return
+}
+
+func (r *testFilmRepo) Update(ctx *context.Context, id int, fields map[string]interface{}, versions []int) (err error) {
+	return
+}
+
+// ActorRepo that counts list reads, other methods are not implemented.
+type testActorRepo struct {
+	usecase.ActorRepoInterface
+	reads int
+}
+
+func (r *testActorRepo) SelectListState(ctx *context.Context) (state *entity.ListState, err error) {
+	return &entity.ListState{Count: 1}, nil
+}
+
+func (r *testActorRepo) GetAllWithFilms(ctx *context.Context) (actors []*entity.ActorWithFilms, err error) {
+	r.reads++
+	actors = []*entity.ActorWithFilms{{
+		Actor: entity.Actor{
+			ID:          2,
+			Name:        "Ed Asner",
+			Aliases:     []string{"Edward Asner"},
+			ExternalIDs: entity.ExternalIDs{"imdb": "nm0039142"},
+			Photo:       &entity.Image{Thumbnails: map[string]string{"small": "small.jpg"}},
+		},
+		FilmsIDs: []int{1},
+	}}
+	return
+}
+
+func TestReadCacheGeneration(t *testing.T) {
+	c := NewReadCache(true, 10, time.Minute)
+
+	_, generation, ok := c.get("films")
+	if ok {
+		t.Fatalf("get() hit on an empty cache")
+	}
+	c.Invalidate()
+	c.set("films", generation, "stale")
+	if value, _, ok := c.get("films"); ok {
+		t.Errorf("get() = %v, list read before Invalidate() must not be stored", value)
+	}
+
+	_, generation, _ = c.get("films")
+	c.set("films", generation, "fresh")
+	if value, _, ok := c.get("films"); !ok || value != "fresh" {
+		t.Errorf("get() = %v, %t, want fresh", value, ok)
+	}
+}
+
+func TestFilmRepoCacheReads(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		reads     []*entity.FilmSearchParams
+		wantReads int
+	}{
+		{"cached", true, []*entity.FilmSearchParams{nil, nil}, 1},
+		{"case of substrings is ignored", true, []*entity.FilmSearchParams{{Title: "UP"}, {Title: "up"}}, 1},
+		{"other params are read", true, []*entity.FilmSearchParams{{Title: "up"}, {ActorName: "up"}}, 2},
+		{"disabled cache", false, []*entity.FilmSearchParams{nil, nil}, 2},
+		{"watchlist is not cached", true, []*entity.FilmSearchParams{{InWatchlistOf: 1}, {InWatchlistOf: 1}}, 2},
+		{"collection is not cached", true, []*entity.FilmSearchParams{{Collection: "pixar"}, {Collection: "pixar"}}, 2},
+		{"franchise is not cached", true, []*entity.FilmSearchParams{{Franchise: 1}, {Franchise: 1}}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &testFilmRepo{}
+			r := NewFilmRepoCache(repo, NewReadCache(tt.enabled, 10, time.Minute))
+			ctx := context.Background()
+			for _, searchParams := range tt.reads {
+				if _, err := r.GetAllWithActors(&ctx, &entity.FilmSortParams{}, searchParams); err != nil {
+					t.Fatalf("GetAllWithActors() error = %v", err)
+				}
+			}
+			if repo.reads != tt.wantReads {
+				t.Errorf("repo reads = %d, want %d", repo.reads, tt.wantReads)
+			}
+		})
+	}
+}
+
+func TestFilmRepoCacheUpdateDuringRead(t *testing.T) {
+	repo := &testFilmRepo{}
+	r := NewFilmRepoCache(repo, NewReadCache(true, 10, time.Minute))
+	ctx := context.Background()
+
+	// The list state is unchanged, so only the generation tells that the first read is stale.
+	repo.duringRead = func() {
+		repo.duringRead = nil
+		if err := r.Update(&ctx, 1, map[string]interface{}{"title": "Coco"}, nil); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := r.GetAllWithActors(&ctx, &entity.FilmSortParams{}, nil); err != nil {
+			t.Fatalf("GetAllWithActors() error = %v", err)
+		}
+	}
+	if repo.reads != 2 {
+		t.Errorf("repo reads = %d, want 2", repo.reads)
+	}
+}
+
+func TestFilmRepoCacheCopies(t *testing.T) {
+	repo := &testFilmRepo{}
+	r := NewFilmRepoCache(repo, NewReadCache(true, 10, time.Minute))
+	ctx := context.Background()
+	want, _ := repo.GetAllWithActors(&ctx, nil, nil)
+	repo.reads = 0
+
+	for i := 0; i < 2; i++ {
+		films, err := r.GetAllWithActors(&ctx, &entity.FilmSortParams{}, nil)
+		if err != nil {
+			t.Fatalf("GetAllWithActors() error = %v", err)
+		}
+		if !reflect.DeepEqual(films, want) {
+			t.Fatalf("GetAllWithActors() = %+v, want %+v", films[0], want[0])
+		}
+		films[0].Title = "Coco"
+		films[0].ActorsIDs[0] = 4
+		films[0].Poster.Keys[0] = "other"
+		films[0].Poster.Thumbnails["small"] = "other.jpg"
+		films[0].Releases[0].Country = "RU"
+		films[0].Releases = append(films[0].Releases, &entity.FilmRelease{Country: "FR"})
+	}
+	if repo.reads != 1 {
+		t.Errorf("repo reads = %d, want 1", repo.reads)
+	}
+}
+
+func TestActorRepoCacheCopies(t *testing.T) {
+	repo := &testActorRepo{}
+	r := NewActorRepoCache(repo, NewReadCache(true, 10, time.Minute))
+	ctx := context.Background()
+	want, _ := repo.GetAllWithFilms(&ctx)
+	repo.reads = 0
+
+	for i := 0; i < 2; i++ {
+		actors, err := r.GetAllWithFilms(&ctx)
+		if err != nil {
+			t.Fatalf("GetAllWithFilms() error = %v", err)
+		}
+		if !reflect.DeepEqual(actors, want) {
+			t.Fatalf("GetAllWithFilms() = %+v, want %+v", actors[0], want[0])
+		}
+		actors[0].Name = "Jordan Nagai"
+		actors[0].Aliases[0] = "J. Nagai"
+		actors[0].ExternalIDs["imdb"] = "nm2296528"
+		actors[0].Photo.Thumbnails["small"] = "other.jpg"
+		actors[0].FilmsIDs[0] = 2
+	}
+	if repo.reads != 1 {
+		t.Errorf("repo reads = %d, want 1", repo.reads)
+	}
+}
@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/internal/usecase"
+)
+
+// FilmRepo decorator caching film lists and facets.
+type FilmRepoCache struct {
+	usecase.FilmRepoInterface
+	cache *ReadCache
+}
+
+// Create new FilmRepoCache over filmRepo.
+func NewFilmRepoCache(filmRepo usecase.FilmRepoInterface, cache *ReadCache) *FilmRepoCache {
+	return &FilmRepoCache{filmRepo, cache}
+}
+
+// Insert a new Film and invalidate the cache.
+func (r *FilmRepoCache) Insert(ctx *context.Context, receivedFilm *entity.Film) (createdFilm *entity.Film, err error) {
+	defer r.cache.Invalidate()
+	return r.FilmRepoInterface.Insert(ctx, receivedFilm)
+}
+
+// Update a Film and invalidate the cache.
+func (r *FilmRepoCache) Update(ctx *context.Context, id int, fields map[string]interface{}, versions []int) (err error) {
+	defer r.cache.Invalidate()
+	return r.FilmRepoInterface.Update(ctx, id, fields, versions)
+}
+
+// Move a Film to trash and invalidate the cache.
+func (r *FilmRepoCache) Delete(ctx *context.Context, id int, versions []int) (err error) {
+	defer r.cache.Invalidate()
+	return r.FilmRepoInterface.Delete(ctx, id, versions)
+}
+
+// Restore a Film from trash and invalidate the cache.
+func (r *FilmRepoCache) Restore(ctx *context.Context, id int) (err error) {
+	defer r.cache.Invalidate()
+	return r.FilmRepoInterface.Restore(ctx, id)
+}
+
+// Purge Films from trash and invalidate the cache.
+func (r *FilmRepoCache) Purge(ctx *context.Context, retentionPeriod time.Duration) (cntPurged int64, err error) {
+	defer r.cache.Invalidate()
+	return r.FilmRepoInterface.Purge(ctx, retentionPeriod)
+}
+
+// Get all films, cached unless they are filtered by a watchlist, collection or franchise.
+// Lists are cached per list state, so changes made without invalidating the cache, like new ratings, are never served stale.
+// Films are copied, so callers may modify them.
+func (r *FilmRepoCache) GetAllWithActors(ctx *context.Context, sortParams *entity.FilmSortParams, searchParams *entity.FilmSearchParams) (films []*entity.FilmWithActors, err error) {
+	if !isFilmSearchCacheable(searchParams) {
+		return r.FilmRepoInterface.GetAllWithActors(ctx, sortParams, searchParams)
+	}
+	state, err := r.FilmRepoInterface.SelectListState(ctx)
+	if err != nil {
+		return
+	}
+	key, err := cacheKey("films", state, sortParams, normalizeFilmSearchParams(searchParams))
+	if err != nil {
+		return
+	}
+	cached, generation, ok := r.cache.get(key)
+	if ok {
+		return copyFilms(cached.([]*entity.FilmWithActors)), nil
+	}
+	films, err = r.FilmRepoInterface.GetAllWithActors(ctx, sortParams, searchParams)
+	if err != nil {
+		return
+	}
+	r.cache.set(key, generation, copyFilms(films))
+	return
+}
+
+// Count films matching search params per facet bucket, cached like GetAllWithActors.
+// Buckets are shared between callers and must not be modified.
+func (r *FilmRepoCache) SelectFacets(ctx *context.Context, searchParams *entity.FilmSearchParams, facets []string) (buckets map[string][]*entity.FacetBucket, err error) {
+	if !isFilmSearchCacheable(searchParams) {
+		return r.FilmRepoInterface.SelectFacets(ctx, searchParams, facets)
+	}
+	state, err := r.FilmRepoInterface.SelectListState(ctx)
+	if err != nil {
+		return
+	}
+	key, err := cacheKey("facets", state, normalizeFilmSearchParams(searchParams), facets)
+	if err != nil {
+		return
+	}
+	cached, generation, ok := r.cache.get(key)
+	if ok {
+		return cached.(map[string][]*entity.FacetBucket), nil
+	}
+	buckets, err = r.FilmRepoInterface.SelectFacets(ctx, searchParams, facets)
+	if err != nil {
+		return
+	}
+	r.cache.set(key, generation, buckets)
+	return
+}
+
+// Check that films matched by search params change only on film, actor or cast writes.
+// Watchlists, collections and franchises are changed without invalidating the cache.
+func isFilmSearchCacheable(searchParams *entity.FilmSearchParams) bool {
+	return searchParams == nil || (searchParams.InWatchlistOf == 0 && searchParams.Collection == "" && searchParams.Franchise == 0)
+}
+
+// Copy search params with substring filters in lower case, since they are matched case-insensitively.
+func normalizeFilmSearchParams(searchParams *entity.FilmSearchParams) *entity.FilmSearchParams {
+	if searchParams == nil {
+		return nil
+	}
+	normalized := *searchParams
+	normalized.Title = strings.ToLower(normalized.Title)
+	normalized.ActorName = strings.ToLower(normalized.ActorName)
+	return &normalized
+}
+
+// Deep copy films, so translations and release info attached by callers do not leak into the cache.
+func copyFilms(films []*entity.FilmWithActors) (copied []*entity.FilmWithActors) {
+	if films == nil {
+		return
+	}
+	copied = make([]*entity.FilmWithActors, len(films))
+	for i, film := range films {
+		filmCopy := *film
+		filmCopy.ActorsIDs = slices.Clone(film.ActorsIDs)
+		filmCopy.Poster = copyImage(film.Poster)
+		filmCopy.DeletedAt = copyValue(film.DeletedAt)
+		filmCopy.Releases = copyValues(film.Releases)
+		filmCopy.Certifications = copyValues(film.Certifications)
+		filmCopy.Relations = copyValues(film.Relations)
+		filmCopy.Franchises = copyValues(film.Franchises)
+		filmCopy.Relevance = copyValue(film.Relevance)
+		filmCopy.Highlights = copyValue(film.Highlights)
+		copied[i] = &filmCopy
+	}
+	return
+}
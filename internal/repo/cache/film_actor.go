@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/internal/usecase"
+)
+
+// FilmsActorsRepo decorator invalidating the cache on cast changes.
+type FilmsActorsRepoCache struct {
+	usecase.FilmsActorsRepoInterface
+	cache *ReadCache
+}
+
+// Create new FilmsActorsRepoCache over filmsActorsRepo.
+func NewFilmsActorsRepoCache(filmsActorsRepo usecase.FilmsActorsRepoInterface, cache *ReadCache) *FilmsActorsRepoCache {
+	return &FilmsActorsRepoCache{filmsActorsRepo, cache}
+}
+
+// Add an Actor to cast of a Film and invalidate the cache.
+func (r *FilmsActorsRepoCache) Insert(ctx *context.Context, receivedFilmActor *entity.FilmActor) (createdFilmActor *entity.FilmActor, err error) {
+	defer r.cache.Invalidate()
+	return r.FilmsActorsRepoInterface.Insert(ctx, receivedFilmActor)
+}
+
+// Remove an Actor from cast of a Film and invalidate the cache.
+func (r *FilmsActorsRepoCache) Delete(ctx *context.Context, filmID, actorID int) (err error) {
+	defer r.cache.Invalidate()
+	return r.FilmsActorsRepoInterface.Delete(ctx, filmID, actorID)
+}
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/internal/usecase"
+)
+
+// ImportRepo decorator invalidating the cache on imports, which write films, actors and cast in bulk.
+type ImportRepoCache struct {
+	usecase.ImportRepoInterface
+	cache *ReadCache
+}
+
+// Create new ImportRepoCache over importRepo.
+func NewImportRepoCache(importRepo usecase.ImportRepoInterface, cache *ReadCache) *ImportRepoCache {
+	return &ImportRepoCache{importRepo, cache}
+}
+
+// Import Actors and invalidate the cache unless it is a dry run.
+func (r *ImportRepoCache) ImportActors(ctx *context.Context, rows []*entity.ActorImportRow, dryRun bool) (results []*entity.ImportRowResult, err error) {
+	if !dryRun {
+		defer r.cache.Invalidate()
+	}
+	return r.ImportRepoInterface.ImportActors(ctx, rows, dryRun)
+}
+
+// Import Films with their cast and invalidate the cache unless it is a dry run.
+func (r *ImportRepoCache) ImportFilms(ctx *context.Context, rows []*entity.FilmImportRow, dryRun bool) (results []*entity.ImportRowResult, err error) {
+	if !dryRun {
+		defer r.cache.Invalidate()
+	}
+	return r.ImportRepoInterface.ImportFilms(ctx, rows, dryRun)
+}
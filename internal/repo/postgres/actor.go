@@ -2,12 +2,14 @@ package repo
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"strconv"
-	"strings"
+	"time"
 
 	"github.com/itmosha/vk-internship-2024/internal/entity"
 	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+	"github.com/lib/pq"
 )
 
 type ActorRepoPostgres struct {
@@ -19,39 +21,71 @@ func NewActorRepoPostgres(store *postgres.Postgres) *ActorRepoPostgres {
 	return &ActorRepoPostgres{store}
 }
 
+// Columns of an Actor in the order of actorScanDest, a is the alias of actor table.
+const actorColumns = `a.id, a.name, a.gender, a.birth_date, a.death_date, a.biography, a.birthplace, a.nationality,
+	a.aliases, a.external_ids, a.photo, a.version`
+
+// Scan destinations for actorColumns.
+func actorScanDest(actor *entity.Actor) []interface{} {
+	return []interface{}{&actor.ID, &actor.Name, &actor.Gender, &actor.BirthDate, &actor.DeathDate, &actor.Biography,
+		&actor.Birthplace, &actor.Nationality, (*pq.StringArray)(&actor.Aliases), &actor.ExternalIDs, &actor.Photo, &actor.Version}
+}
+
+// Convert values to a text array, nil is stored as an empty array.
+func textArray(values []string) interface{} {
+	if values == nil {
+		values = []string{}
+	}
+	return pq.Array(values)
+}
+
+// Start a new transaction.
+func (r *ActorRepoPostgres) NewTransaction(ctx *context.Context) (tx *sql.Tx, err error) {
+	return r.store.DB.BeginTx(*ctx, nil)
+}
+
 // Insert a new Actor with provided fields.
 func (r *ActorRepoPostgres) Insert(ctx *context.Context, receivedActor *entity.Actor) (createdActor *entity.Actor, err error) {
 	createdActor = &entity.Actor{}
-	stmt, err := r.store.DB.PrepareContext(*ctx, `
-		INSERT INTO actor (name, gender, birth_date)
-		VALUES ($1, $2, $3)
-		RETURNING id, name, gender, birth_date;
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
+		INSERT INTO actor AS a (name, gender, birth_date, death_date, biography, birthplace, nationality, aliases, external_ids)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING `+actorColumns+`;
 	`)
 	if err != nil {
 		return
 	}
 	defer stmt.Close()
 
-	err = stmt.QueryRowContext(*ctx, receivedActor.Name, receivedActor.Gender, receivedActor.BirthDate).
-		Scan(&createdActor.ID, &createdActor.Name, &createdActor.Gender, &createdActor.BirthDate)
+	err = stmt.QueryRowContext(*ctx, receivedActor.Name, receivedActor.Gender, receivedActor.BirthDate, receivedActor.DeathDate,
+		receivedActor.Biography, receivedActor.Birthplace, receivedActor.Nationality, textArray(receivedActor.Aliases), receivedActor.ExternalIDs).
+		Scan(actorScanDest(createdActor)...)
 	return
 }
 
-// Update provided fields of an Actor by id.
-func (r *ActorRepoPostgres) Update(ctx *context.Context, id int, fields map[string]interface{}) (err error) {
+// Update provided fields of an Actor by id and bump its version.
+// If versions are provided, the Actor is updated only if its current version is one of them.
+func (r *ActorRepoPostgres) Update(ctx *context.Context, id int, fields map[string]interface{}, versions []int) (err error) {
 	// TODO: Use prepared statements
-	query := `UPDATE actor SET id = id, `
+	query := `UPDATE actor SET id = id, version = version + 1, `
 	values := make([]interface{}, 0)
 	idx := 1
 	for field, value := range fields {
 		query += field + "=$" + fmt.Sprint(idx) + ", "
+		if list, ok := value.([]string); ok {
+			value = textArray(list)
+		}
 		values = append(values, value)
 		idx++
 	}
-	query = query[:len(query)-2] + " WHERE id=$" + fmt.Sprint(idx)
+	query = query[:len(query)-2] + " WHERE id=$" + fmt.Sprint(idx) + " AND deleted_at IS NULL"
 	values = append(values, id)
+	if versions != nil {
+		query += " AND version = ANY($" + fmt.Sprint(idx+1) + ")"
+		values = append(values, pq.Array(versions))
+	}
 
-	res, err := r.store.DB.ExecContext(*ctx, query, values...)
+	res, err := r.store.Conn(*ctx).ExecContext(*ctx, query, values...)
 	if err != nil {
 		return
 	}
@@ -60,38 +94,71 @@ func (r *ActorRepoPostgres) Update(ctx *context.Context, id int, fields map[stri
 	if err != nil {
 		return
 	} else if cntRows == 0 {
-		err = ErrActorNotFound
+		err = r.notUpdatedError(ctx, id, versions)
 	}
 	return
 }
 
-// Delete an Actor by id.
-func (r *ActorRepoPostgres) Delete(ctx *context.Context, id int) (err error) {
-	stmt, err := r.store.DB.PrepareContext(*ctx, `
-		DELETE FROM actor
-		WHERE id = $1;`)
+// Soft-delete an Actor by id and bump versions of its films.
+// If versions are provided, the Actor is deleted only if its current version is one of them.
+func (r *ActorRepoPostgres) Delete(ctx *context.Context, id int, versions []int) (err error) {
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
+		UPDATE actor
+		SET deleted_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL AND ($2::INTEGER[] IS NULL OR version = ANY($2));`)
 	if err != nil {
 		return
 	}
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(*ctx, id)
+	res, err := stmt.ExecContext(*ctx, id, pq.Array(versions))
 	if err != nil {
 		return
 	}
 	if cntRows, _ := res.RowsAffected(); cntRows == 0 {
+		err = r.notUpdatedError(ctx, id, versions)
+		return
+	}
+	err = r.bumpFilmsVersions(ctx, id)
+	return
+}
+
+// Lock an Actor by id until the end of the transaction carried by ctx, so that it is not changed concurrently.
+// Actors in trash are locked as well.
+func (r *ActorRepoPostgres) Lock(ctx *context.Context, id int) (err error) {
+	err = r.store.Conn(*ctx).QueryRowContext(*ctx, `
+		SELECT id FROM actor WHERE id = $1 FOR UPDATE;`, id).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
 		err = ErrActorNotFound
 	}
 	return
 }
 
+// Explain why a conditional write of an Actor affected no rows.
+func (r *ActorRepoPostgres) notUpdatedError(ctx *context.Context, id int, versions []int) (err error) {
+	if versions == nil {
+		return ErrActorNotFound
+	}
+	var exists bool
+	err = r.store.Conn(*ctx).QueryRowContext(*ctx, `
+		SELECT EXISTS (SELECT 1 FROM actor WHERE id = $1 AND deleted_at IS NULL);`, id).Scan(&exists)
+	if err != nil {
+		return
+	}
+	if exists {
+		return ErrVersionMismatch
+	}
+	return ErrActorNotFound
+}
+
 // Get all Actors.
 func (r *ActorRepoPostgres) GetAllWithFilms(ctx *context.Context) (actors []*entity.ActorWithFilms, err error) {
-	stmt, err := r.store.DB.PrepareContext(*ctx, `
-		SELECT a.id, a.name, a.gender, a.birth_date, ARRAY_AGG(f.id) AS films_ids
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
+		SELECT `+actorColumns+`, ARRAY_REMOVE(ARRAY_AGG(f.id), NULL) AS films_ids
 		FROM actor a
 		LEFT JOIN films_actors fa ON a.id = fa.actor_id
-		LEFT JOIN film f ON fa.film_id = f.id
+		LEFT JOIN film f ON fa.film_id = f.id AND f.deleted_at IS NULL
+		WHERE a.deleted_at IS NULL
 		GROUP BY a.id;`)
 
 	if err != nil {
@@ -103,27 +170,145 @@ func (r *ActorRepoPostgres) GetAllWithFilms(ctx *context.Context) (actors []*ent
 	if err != nil {
 		return
 	}
+	defer rows.Close()
 	for rows.Next() {
 		actor := &entity.ActorWithFilms{}
-		var filmsIDsRaw []byte
-		err = rows.Scan(&actor.ID, &actor.Name, &actor.Gender, &actor.BirthDate, &filmsIDsRaw)
+		var filmsIDs pq.Int64Array
+		err = rows.Scan(append(actorScanDest(&actor.Actor), &filmsIDs)...)
 		if err != nil {
 			return
 		}
-		// TODO: Refactor this
-		filmsIDsStr := string(filmsIDsRaw)
-		if filmsIDsStr == "{NULL}" {
-			actor.FilmsIDs = []int{}
-		} else {
-			filmsIDsStr = strings.Trim(filmsIDsStr, "{}")
-			filmsIDsStrSlice := strings.Split(filmsIDsStr, ",")
-			filmsIDs := make([]int, len(filmsIDsStrSlice))
-			for i, idStr := range filmsIDsStrSlice {
-				filmsIDs[i], _ = strconv.Atoi(idStr)
-			}
-			actor.FilmsIDs = filmsIDs
+		actor.FilmsIDs = int64sToInts(filmsIDs)
+		actors = append(actors, actor)
+	}
+	return
+}
+
+// Get state of lists of actors.
+func (r *ActorRepoPostgres) SelectListState(ctx *context.Context) (state *entity.ListState, err error) {
+	return selectListState(ctx, r.store)
+}
+
+// Get an Actor by id.
+func (r *ActorRepoPostgres) SelectByID(ctx *context.Context, id int) (actor *entity.Actor, err error) {
+	actor = &entity.Actor{}
+	err = r.store.Conn(*ctx).QueryRowContext(*ctx, `
+		SELECT `+actorColumns+`
+		FROM actor a
+		WHERE a.id = $1 AND a.deleted_at IS NULL;`, id).
+		Scan(actorScanDest(actor)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = ErrActorNotFound
+	}
+	return
+}
+
+// Get Actors with their films by ids, missing and soft-deleted actors are skipped.
+func (r *ActorRepoPostgres) SelectByIDs(ctx *context.Context, ids []int) (actors []*entity.ActorWithFilms, err error) {
+	rows, err := r.store.Conn(*ctx).QueryContext(*ctx, `
+		SELECT `+actorColumns+`, ARRAY_REMOVE(ARRAY_AGG(f.id ORDER BY f.id), NULL) AS films_ids
+		FROM actor a
+		LEFT JOIN films_actors fa ON a.id = fa.actor_id
+		LEFT JOIN film f ON fa.film_id = f.id AND f.deleted_at IS NULL
+		WHERE a.id = ANY($1) AND a.deleted_at IS NULL
+		GROUP BY a.id;`, pq.Array(ids))
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		actor := &entity.ActorWithFilms{}
+		var filmsIDs pq.Int64Array
+		err = rows.Scan(append(actorScanDest(&actor.Actor), &filmsIDs)...)
+		if err != nil {
+			return
 		}
+		actor.FilmsIDs = int64sToInts(filmsIDs)
 		actors = append(actors, actor)
 	}
+	err = rows.Err()
+	return
+}
+
+// Get all soft-deleted Actors, recently deleted first.
+func (r *ActorRepoPostgres) SelectDeleted(ctx *context.Context) (actors []*entity.ActorWithFilms, err error) {
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
+		SELECT `+actorColumns+`, a.deleted_at, ARRAY_REMOVE(ARRAY_AGG(fa.film_id), NULL) AS films_ids
+		FROM actor a
+		LEFT JOIN films_actors fa ON a.id = fa.actor_id
+		WHERE a.deleted_at IS NOT NULL
+		GROUP BY a.id
+		ORDER BY a.deleted_at DESC;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		actor := &entity.ActorWithFilms{}
+		var filmsIDs pq.Int64Array
+		err = rows.Scan(append(actorScanDest(&actor.Actor), &actor.DeletedAt, &filmsIDs)...)
+		if err != nil {
+			return
+		}
+		actor.FilmsIDs = int64sToInts(filmsIDs)
+		actors = append(actors, actor)
+	}
+	err = rows.Err()
+	return
+}
+
+// Restore a soft-deleted Actor by id and bump versions of its films. Its film links are kept while in trash, so they come back as well.
+func (r *ActorRepoPostgres) Restore(ctx *context.Context, id int) (err error) {
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
+		UPDATE actor
+		SET deleted_at = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(*ctx, id)
+	if err != nil {
+		return
+	}
+	if cntRows, _ := res.RowsAffected(); cntRows == 0 {
+		err = ErrActorNotFound
+		return
+	}
+	err = r.bumpFilmsVersions(ctx, id)
+	return
+}
+
+// Bump versions of Films linked to the Actor, since their responses list only linked Actors that are not deleted.
+func (r *ActorRepoPostgres) bumpFilmsVersions(ctx *context.Context, id int) (err error) {
+	_, err = r.store.Conn(*ctx).ExecContext(*ctx, `
+		UPDATE film SET version = version + 1
+		WHERE deleted_at IS NULL AND id IN (SELECT film_id FROM films_actors WHERE actor_id = $1);`, id)
+	return
+}
+
+// Hard-delete Actors that were soft-deleted longer than retention period ago.
+// deleted_at is set by NOW() without time zone, so it is compared with the database clock rather than a time from Go.
+func (r *ActorRepoPostgres) Purge(ctx *context.Context, retentionPeriod time.Duration) (cntPurged int64, err error) {
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
+		DELETE FROM actor
+		WHERE deleted_at IS NOT NULL AND deleted_at < NOW() - $1::interval;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(*ctx, formatInterval(retentionPeriod))
+	if err != nil {
+		return
+	}
+	cntPurged, err = res.RowsAffected()
 	return
 }
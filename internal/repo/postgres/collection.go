@@ -0,0 +1,203 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+	"github.com/lib/pq"
+)
+
+type CollectionRepoPostgres struct {
+	store *postgres.Postgres
+}
+
+// Create new CollectionRepoPostgres.
+func NewCollectionRepoPostgres(store *postgres.Postgres) *CollectionRepoPostgres {
+	return &CollectionRepoPostgres{store}
+}
+
+// Insert a new Collection with its films.
+func (r *CollectionRepoPostgres) Insert(ctx *context.Context, receivedCollection *entity.Collection) (createdCollection *entity.Collection, err error) {
+	tx, err := r.store.DB.BeginTx(*ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	createdCollection = &entity.Collection{}
+	err = tx.QueryRowContext(*ctx, `
+		INSERT INTO collection (slug, title, description, status)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, slug, title, description, status, created_at, updated_at;`,
+		receivedCollection.Slug, receivedCollection.Title, receivedCollection.Description, receivedCollection.Status).
+		Scan(&createdCollection.ID, &createdCollection.Slug, &createdCollection.Title, &createdCollection.Description,
+			&createdCollection.Status, &createdCollection.CreatedAt, &createdCollection.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			err = ErrNonUniqueSlug
+		}
+		return
+	}
+	err = replaceCollectionFilms(ctx, tx, createdCollection.ID, receivedCollection.FilmsIDs)
+	createdCollection.FilmsIDs = receivedCollection.FilmsIDs
+	return
+}
+
+// Update provided fields of a Collection by id. Films are replaced if filmsIDs is not nil.
+func (r *CollectionRepoPostgres) Update(ctx *context.Context, id int, fields map[string]interface{}, filmsIDs []int) (err error) {
+	tx, err := r.store.DB.BeginTx(*ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	query := `UPDATE collection SET updated_at = NOW(), `
+	values := make([]interface{}, 0)
+	idx := 1
+	for field, value := range fields {
+		query += field + "=$" + fmt.Sprint(idx) + ", "
+		values = append(values, value)
+		idx++
+	}
+	query = query[:len(query)-2] + " WHERE id=$" + fmt.Sprint(idx)
+	values = append(values, id)
+
+	res, err := tx.ExecContext(*ctx, query, values...)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			err = ErrNonUniqueSlug
+		}
+		return
+	}
+	if cntRows, _ := res.RowsAffected(); cntRows == 0 {
+		err = ErrCollectionNotFound
+		return
+	}
+	if filmsIDs != nil {
+		err = replaceCollectionFilms(ctx, tx, id, filmsIDs)
+	}
+	return
+}
+
+// Delete a Collection by id.
+func (r *CollectionRepoPostgres) Delete(ctx *context.Context, id int) (err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		DELETE FROM collection
+		WHERE id = $1;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(*ctx, id)
+	if err != nil {
+		return
+	}
+	if cntRows, _ := res.RowsAffected(); cntRows == 0 {
+		err = ErrCollectionNotFound
+	}
+	return
+}
+
+// Get all Collections, drafts are included only if withDrafts is set.
+func (r *CollectionRepoPostgres) SelectAll(ctx *context.Context, withDrafts bool) (collections []*entity.Collection, err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		SELECT c.id, c.slug, c.title, c.description, c.status, c.created_at, c.updated_at,
+			ARRAY_REMOVE(ARRAY_AGG(cf.film_id ORDER BY cf.position), NULL) AS films_ids
+		FROM collection c
+		LEFT JOIN collections_films cf ON c.id = cf.collection_id
+		WHERE $1 OR c.status = 'published'
+		GROUP BY c.id
+		ORDER BY c.created_at DESC;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx, withDrafts)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		collection := &entity.Collection{}
+		var filmsIDs pq.Int64Array
+		err = rows.Scan(&collection.ID, &collection.Slug, &collection.Title, &collection.Description,
+			&collection.Status, &collection.CreatedAt, &collection.UpdatedAt, &filmsIDs)
+		if err != nil {
+			return
+		}
+		collection.FilmsIDs = int64sToInts(filmsIDs)
+		collections = append(collections, collection)
+	}
+	err = rows.Err()
+	return
+}
+
+// Get a Collection by slug.
+func (r *CollectionRepoPostgres) SelectBySlug(ctx *context.Context, slug string) (collection *entity.Collection, err error) {
+	collection = &entity.Collection{}
+	var filmsIDs pq.Int64Array
+	err = r.store.DB.QueryRowContext(*ctx, `
+		SELECT c.id, c.slug, c.title, c.description, c.status, c.created_at, c.updated_at,
+			ARRAY_REMOVE(ARRAY_AGG(cf.film_id ORDER BY cf.position), NULL) AS films_ids
+		FROM collection c
+		LEFT JOIN collections_films cf ON c.id = cf.collection_id
+		WHERE c.slug = $1
+		GROUP BY c.id;`, slug).
+		Scan(&collection.ID, &collection.Slug, &collection.Title, &collection.Description,
+			&collection.Status, &collection.CreatedAt, &collection.UpdatedAt, &filmsIDs)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = ErrCollectionNotFound
+		}
+		return
+	}
+	collection.FilmsIDs = int64sToInts(filmsIDs)
+	return
+}
+
+// Replace all films of a collection keeping the provided order.
+func replaceCollectionFilms(ctx *context.Context, tx *sql.Tx, collectionID int, filmsIDs []int) (err error) {
+	_, err = tx.ExecContext(*ctx, `DELETE FROM collections_films WHERE collection_id = $1;`, collectionID)
+	if err != nil {
+		return
+	}
+	for position, filmID := range filmsIDs {
+		_, err = tx.ExecContext(*ctx, `
+			INSERT INTO collections_films (collection_id, film_id, position)
+			VALUES ($1, $2, $3);`, collectionID, filmID, position)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Constraint == "collections_films_film_id_fkey" {
+				err = ErrFilmNotFound
+			}
+			return
+		}
+	}
+	return
+}
+
+// Convert ids scanned from a postgres array.
+func int64sToInts(values []int64) (ints []int) {
+	ints = make([]int, len(values))
+	for i, v := range values {
+		ints[i] = int(v)
+	}
+	return
+}
@@ -0,0 +1,82 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+	"github.com/lib/pq"
+)
+
+type CostarRepoPostgres struct {
+	store *postgres.Postgres
+}
+
+// Create new CostarRepoPostgres.
+func NewCostarRepoPostgres(store *postgres.Postgres) *CostarRepoPostgres {
+	return &CostarRepoPostgres{store}
+}
+
+// Get a page of co-stars of an Actor ranked by the number of shared Films, deleted Actors and Films are skipped.
+func (r *CostarRepoPostgres) SelectByActorID(ctx *context.Context, actorID int, pagination *entity.PaginationParams) (costars []*entity.Costar, err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		SELECT a.id, a.name, COUNT(*) AS shared_films_count, ARRAY_AGG(f.id ORDER BY f.release_date, f.id) AS shared_films_ids
+		FROM films_actors fa
+		JOIN films_actors costar_fa ON fa.film_id = costar_fa.film_id AND costar_fa.actor_id <> fa.actor_id
+		JOIN film f ON fa.film_id = f.id AND f.deleted_at IS NULL
+		JOIN actor a ON costar_fa.actor_id = a.id AND a.deleted_at IS NULL
+		WHERE fa.actor_id = $1
+		GROUP BY a.id
+		ORDER BY shared_films_count DESC, a.name, a.id
+		LIMIT $2 OFFSET $3;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx, actorID, pagination.Limit, pagination.Offset)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		costar := &entity.Costar{}
+		var filmsIDs pq.Int64Array
+		if err = rows.Scan(&costar.ID, &costar.Name, &costar.SharedFilmsCount, &filmsIDs); err != nil {
+			return
+		}
+		costar.SharedFilmsIDs = int64sToInts(filmsIDs)
+		costars = append(costars, costar)
+	}
+	err = rows.Err()
+	return
+}
+
+// Get all cast entries of not deleted Films and Actors, ordered by actor and film ids.
+func (r *CostarRepoPostgres) SelectCastGraph(ctx *context.Context) (filmsActors []*entity.FilmActor, err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		SELECT fa.film_id, fa.actor_id
+		FROM films_actors fa
+		JOIN film f ON fa.film_id = f.id AND f.deleted_at IS NULL
+		JOIN actor a ON fa.actor_id = a.id AND a.deleted_at IS NULL
+		ORDER BY fa.actor_id, fa.film_id;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		filmActor := &entity.FilmActor{}
+		if err = rows.Scan(&filmActor.FilmID, &filmActor.ActorID); err != nil {
+			return
+		}
+		filmsActors = append(filmsActors, filmActor)
+	}
+	err = rows.Err()
+	return
+}
@@ -0,0 +1,143 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+	"github.com/lib/pq"
+)
+
+// Number of rows fetched from an export cursor at once.
+const exportFetchSize = 1000
+
+type ExportRepoPostgres struct {
+	store *postgres.Postgres
+}
+
+// Create new ExportRepoPostgres.
+func NewExportRepoPostgres(store *postgres.Postgres) *ExportRepoPostgres {
+	return &ExportRepoPostgres{store}
+}
+
+// Stream all not deleted Actors ordered by id. Dates are formatted in ISO 8601 the way the importer accepts them.
+func (r *ExportRepoPostgres) ExportActors(ctx *context.Context, handle func(actor *entity.ActorImportRow) error) (err error) {
+	err = r.streamRows(ctx, `
+		SELECT id, name, gender, TO_CHAR(birth_date, 'YYYY-MM-DD'), COALESCE(TO_CHAR(death_date, 'YYYY-MM-DD'), ''),
+			biography, birthplace, nationality, aliases, external_ids
+		FROM actor
+		WHERE deleted_at IS NULL
+		ORDER BY id`,
+		func(rows *sql.Rows) (err error) {
+			actor := &entity.ActorImportRow{ID: new(int), Gender: new(entity.Gender)}
+			var externalIDs entity.ExternalIDs
+			err = rows.Scan(actor.ID, &actor.Name, actor.Gender, &actor.BirthDate, &actor.DeathDate,
+				&actor.Biography, &actor.Birthplace, &actor.Nationality, (*pq.StringArray)(&actor.Aliases), &externalIDs)
+			if err != nil {
+				return
+			}
+			actor.ExternalIDs = externalIDs
+			return handle(actor)
+		})
+	return
+}
+
+// Stream all not deleted Films ordered by id with their cast referenced by both ids and names.
+func (r *ExportRepoPostgres) ExportFilms(ctx *context.Context, handle func(film *entity.FilmImportRow) error) (err error) {
+	err = r.streamRows(ctx, `
+		SELECT f.id, f.title, f.description,
+			TO_CHAR(f.release_date, CASE f.release_date_precision WHEN 'year' THEN 'YYYY' WHEN 'month' THEN 'YYYY-MM' ELSE 'YYYY-MM-DD' END),
+			f.rating,
+			ARRAY_REMOVE(ARRAY_AGG(a.id ORDER BY a.id), NULL),
+			ARRAY_REMOVE(ARRAY_AGG(a.name ORDER BY a.id), NULL)
+		FROM film f
+		LEFT JOIN films_actors fa ON fa.film_id = f.id
+		LEFT JOIN actor a ON a.id = fa.actor_id AND a.deleted_at IS NULL
+		WHERE f.deleted_at IS NULL
+		GROUP BY f.id
+		ORDER BY f.id`,
+		func(rows *sql.Rows) (err error) {
+			film := &entity.FilmImportRow{ID: new(int), Rating: new(int)}
+			var actorsIDs pq.Int64Array
+			var actorsNames pq.StringArray
+			err = rows.Scan(film.ID, &film.Title, &film.Description, &film.ReleaseDate, film.Rating, &actorsIDs, &actorsNames)
+			if err != nil {
+				return
+			}
+			film.ActorsIDs = int64sToInts(actorsIDs)
+			film.ActorsNames = actorsNames
+			return handle(film)
+		})
+	return
+}
+
+// Stream all cast links between not deleted Films and Actors.
+func (r *ExportRepoPostgres) ExportCast(ctx *context.Context, handle func(filmActor *entity.FilmActor) error) (err error) {
+	err = r.streamRows(ctx, `
+		SELECT fa.film_id, fa.actor_id
+		FROM films_actors fa
+		JOIN film f ON f.id = fa.film_id AND f.deleted_at IS NULL
+		JOIN actor a ON a.id = fa.actor_id AND a.deleted_at IS NULL
+		ORDER BY fa.film_id, fa.actor_id`,
+		func(rows *sql.Rows) (err error) {
+			filmActor := &entity.FilmActor{}
+			if err = rows.Scan(&filmActor.FilmID, &filmActor.ActorID); err != nil {
+				return
+			}
+			return handle(filmActor)
+		})
+	return
+}
+
+// Stream all Users ordered by id.
+func (r *ExportRepoPostgres) ExportUsers(ctx *context.Context, handle func(user *entity.User) error) (err error) {
+	err = r.streamRows(ctx, `
+		SELECT id, username, is_admin
+		FROM users
+		ORDER BY id`,
+		func(rows *sql.Rows) (err error) {
+			user := &entity.User{}
+			if err = rows.Scan(&user.ID, &user.Username, &user.IsAdmin); err != nil {
+				return
+			}
+			return handle(user)
+		})
+	return
+}
+
+// Run a query with a server-side cursor in a read-only transaction and scan its rows in chunks,
+// so that the whole result is never loaded into memory.
+func (r *ExportRepoPostgres) streamRows(ctx *context.Context, query string, scanRow func(rows *sql.Rows) error) (err error) {
+	tx, err := r.store.DB.BeginTx(*ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(*ctx, "DECLARE export_cursor NO SCROLL CURSOR FOR "+query+";")
+	if err != nil {
+		return
+	}
+	fetchQuery := fmt.Sprintf("FETCH FORWARD %d FROM export_cursor;", exportFetchSize)
+	for {
+		var rows *sql.Rows
+		rows, err = tx.QueryContext(*ctx, fetchQuery)
+		if err != nil {
+			return
+		}
+		cntRows := 0
+		for rows.Next() {
+			cntRows++
+			if err = scanRow(rows); err != nil {
+				rows.Close()
+				return
+			}
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil || cntRows < exportFetchSize {
+			return
+		}
+	}
+}
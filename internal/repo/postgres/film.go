@@ -3,12 +3,15 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/itmosha/vk-internship-2024/internal/entity"
 	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+	"github.com/lib/pq"
 )
 
 type FilmRepoPostgres struct {
@@ -28,25 +31,26 @@ func (r *FilmRepoPostgres) NewTransaction(ctx *context.Context) (tx *sql.Tx, err
 // Insert a new Film with provided fields.
 func (r *FilmRepoPostgres) Insert(ctx *context.Context, receivedFilm *entity.Film) (createdFilm *entity.Film, err error) {
 	createdFilm = &entity.Film{}
-	stmt, err := r.store.DB.PrepareContext(*ctx, `
-		INSERT INTO film (title, description, release_date, rating)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, title, description, release_date, rating;
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
+		INSERT INTO film (title, description, release_date, release_date_precision, rating)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, title, description, release_date, release_date_precision, rating, version;
 	`)
 	if err != nil {
 		return
 	}
 	defer stmt.Close()
 
-	err = stmt.QueryRowContext(*ctx, receivedFilm.Title, receivedFilm.Description, receivedFilm.ReleaseDate, receivedFilm.Rating).
-		Scan(&createdFilm.ID, &createdFilm.Title, &createdFilm.Description, &createdFilm.ReleaseDate, &createdFilm.Rating)
+	err = stmt.QueryRowContext(*ctx, receivedFilm.Title, receivedFilm.Description, receivedFilm.ReleaseDate, receivedFilm.ReleaseDate.Precision, receivedFilm.Rating).
+		Scan(&createdFilm.ID, &createdFilm.Title, &createdFilm.Description, &createdFilm.ReleaseDate, &createdFilm.ReleaseDate.Precision, &createdFilm.Rating, &createdFilm.Version)
 	return
 }
 
-// Update provided fields of a Film by id.
-func (r *FilmRepoPostgres) Update(ctx *context.Context, id int, fields map[string]interface{}) (err error) {
+// Update provided fields of a Film by id and bump its version.
+// If versions are provided, the Film is updated only if its current version is one of them.
+func (r *FilmRepoPostgres) Update(ctx *context.Context, id int, fields map[string]interface{}, versions []int) (err error) {
 	// TODO: Use prepared statements
-	query := `UPDATE film SET id = id, `
+	query := `UPDATE film SET id = id, version = version + 1, `
 	values := make([]interface{}, 0)
 	idx := 1
 	for field, value := range fields {
@@ -54,10 +58,14 @@ func (r *FilmRepoPostgres) Update(ctx *context.Context, id int, fields map[strin
 		values = append(values, value)
 		idx++
 	}
-	query = query[:len(query)-2] + " WHERE id=$" + fmt.Sprint(idx)
+	query = query[:len(query)-2] + " WHERE id=$" + fmt.Sprint(idx) + " AND deleted_at IS NULL"
 	values = append(values, id)
+	if versions != nil {
+		query += " AND version = ANY($" + fmt.Sprint(idx+1) + ")"
+		values = append(values, pq.Array(versions))
+	}
 
-	res, err := r.store.DB.ExecContext(*ctx, query, values...)
+	res, err := r.store.Conn(*ctx).ExecContext(*ctx, query, values...)
 	if err != nil {
 		return
 	}
@@ -66,86 +74,434 @@ func (r *FilmRepoPostgres) Update(ctx *context.Context, id int, fields map[strin
 	if err != nil {
 		return
 	} else if cntRows == 0 {
-		err = ErrFilmNotFound
+		err = r.notUpdatedError(ctx, id, versions)
 	}
 	return
 }
 
-// Delete a Film by id.
-func (r *FilmRepoPostgres) Delete(ctx *context.Context, id int) (err error) {
-	stmt, err := r.store.DB.PrepareContext(*ctx, `
-		DELETE FROM film
-		WHERE id = $1;`)
+// Soft-delete a Film by id and bump versions of its actors.
+// If versions are provided, the Film is deleted only if its current version is one of them.
+func (r *FilmRepoPostgres) Delete(ctx *context.Context, id int, versions []int) (err error) {
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
+		UPDATE film
+		SET deleted_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL AND ($2::INTEGER[] IS NULL OR version = ANY($2));`)
 	if err != nil {
 		return
 	}
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(*ctx, id)
+	res, err := stmt.ExecContext(*ctx, id, pq.Array(versions))
 	if err != nil {
 		return
 	}
 	if cntRows, _ := res.RowsAffected(); cntRows == 0 {
+		err = r.notUpdatedError(ctx, id, versions)
+		return
+	}
+	err = r.bumpActorsVersions(ctx, id)
+	return
+}
+
+// Lock a Film by id until the end of the transaction carried by ctx, so that it is not changed concurrently.
+// Films in trash are locked as well.
+func (r *FilmRepoPostgres) Lock(ctx *context.Context, id int) (err error) {
+	err = r.store.Conn(*ctx).QueryRowContext(*ctx, `
+		SELECT id FROM film WHERE id = $1 FOR UPDATE;`, id).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
 		err = ErrFilmNotFound
 	}
 	return
 }
 
+// Explain why a conditional write of a Film affected no rows.
+func (r *FilmRepoPostgres) notUpdatedError(ctx *context.Context, id int, versions []int) (err error) {
+	if versions == nil {
+		return ErrFilmNotFound
+	}
+	var exists bool
+	err = r.store.Conn(*ctx).QueryRowContext(*ctx, `
+		SELECT EXISTS (SELECT 1 FROM film WHERE id = $1 AND deleted_at IS NULL);`, id).Scan(&exists)
+	if err != nil {
+		return
+	}
+	if exists {
+		return ErrVersionMismatch
+	}
+	return ErrFilmNotFound
+}
+
 // Get all films.
 func (r *FilmRepoPostgres) GetAllWithActors(ctx *context.Context, sortParams *entity.FilmSortParams, searchParams *entity.FilmSearchParams) (films []*entity.FilmWithActors, err error) {
-	// TODO: Refactor this mess
 	query := `
-		SELECT f.id, f.title, f.description, f.release_date, f.rating, ARRAY_AGG(a.id) AS actor_ids
+		SELECT f.id, f.title, f.description, f.release_date, f.release_date_precision, f.rating, f.user_rating_avg, f.user_rating_count, f.version, f.poster, ARRAY_REMOVE(ARRAY_AGG(a.id), NULL) AS actor_ids`
+	isFullTextSearch := searchParams != nil && searchParams.Query != ""
+	if isFullTextSearch {
+		query += `, TS_RANK(f.search_vector, ` + filmTSQuery + `) AS relevance,
+			TS_HEADLINE('russian', f.title, ` + filmTSQuery + `, 'HighlightAll=true'),
+			TS_HEADLINE('russian', f.description, ` + filmTSQuery + `, 'MaxFragments=2, MaxWords=30, MinWords=10')`
+	}
+	conditions, args := filmSearchConditions(searchParams)
+	query += `
         FROM film f
         LEFT JOIN films_actors fa ON f.id = fa.film_id
-        LEFT JOIN actor a ON fa.actor_id = a.id`
-	var conditions []string
-	var args []interface{}
+        LEFT JOIN actor a ON fa.actor_id = a.id AND a.deleted_at IS NULL`
+	query += " WHERE " + strings.Join(conditions, " AND ")
+	query += " GROUP BY f.id"
+	if sortParams != nil {
+		query += " ORDER BY " + filmOrderBy(sortParams)
+	}
+	rows, err := r.store.Conn(*ctx).QueryContext(*ctx, query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		film := entity.FilmWithActors{}
+		var actorsIDs pq.Int64Array
+		dest := []interface{}{&film.ID, &film.Title, &film.Description, &film.ReleaseDate, &film.ReleaseDate.Precision, &film.Rating, &film.UserRatingAvg, &film.UserRatingCount, &film.Version, &film.Poster, &actorsIDs}
+		if isFullTextSearch {
+			film.Relevance = new(float64)
+			film.Highlights = &entity.FilmHighlights{}
+			dest = append(dest, film.Relevance, &film.Highlights.Title, &film.Highlights.Description)
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return
+		}
+		film.ActorsIDs = int64sToInts(actorsIDs)
+		films = append(films, &film)
+	}
+	return
+}
+
+// Get state of lists of films.
+func (r *FilmRepoPostgres) SelectListState(ctx *context.Context) (state *entity.ListState, err error) {
+	return selectListState(ctx, r.store)
+}
+
+// Count films and actors not in trash and get the latest time any of them changed, including ones in trash.
+// Films and actors moved to trash are changed when they are moved, so removing them from lists changes the state.
+func selectListState(ctx *context.Context, store *postgres.Postgres) (state *entity.ListState, err error) {
+	state = &entity.ListState{}
+	err = store.DB.QueryRowContext(*ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM film WHERE deleted_at IS NULL) + (SELECT COUNT(*) FROM actor WHERE deleted_at IS NULL),
+			COALESCE(GREATEST((SELECT MAX(updated_at) FROM film), (SELECT MAX(updated_at) FROM actor)), 'epoch'::TIMESTAMPTZ);`).
+		Scan(&state.Count, &state.UpdatedAt)
+	return
+}
+
+// Expressions of sort fields in films query.
+var filmSortExpressions = map[entity.FilmSortField]string{
+	entity.FilmTitleSortField:           "f.title",
+	entity.FilmRatingSortField:          "f.rating",
+	entity.FilmReleaseDateSortField:     "f.release_date",
+	entity.FilmUserRatingAvgSortField:   "f.user_rating_avg",
+	entity.FilmUserRatingCountSortField: "f.user_rating_count",
+	entity.FilmActorsCountSortField:     "COUNT(a.id)",
+	entity.FilmRelevanceSortField:       "relevance",
+}
+
+// Build ORDER BY list of films query from sort keys, id is appended as a tie-breaker to keep the order stable.
+func filmOrderBy(sortParams *entity.FilmSortParams) string {
+	orderBy := make([]string, 0, len(sortParams.Keys)+1)
+	for _, key := range sortParams.Keys {
+		direction := " ASC"
+		if key.Order == entity.SortOrderDesc {
+			direction = " DESC"
+		}
+		orderBy = append(orderBy, filmSortExpressions[key.Field]+direction)
+	}
+	return strings.Join(append(orderBy, "f.id ASC"), ", ")
+}
+
+// Queries of facet buckets over matched films, each returns value, label, count and position of a bucket.
+var filmFacetQueries = map[string]string{
+	entity.FilmDecadeFacet: `
+		SELECT decade::TEXT, decade::TEXT || 's', COUNT(*), ROW_NUMBER() OVER (ORDER BY decade)
+		FROM (SELECT EXTRACT(YEAR FROM release_date)::INTEGER / 10 * 10 AS decade FROM matched) m
+		GROUP BY decade`,
+	entity.FilmRatingFacet: `
+		SELECT rating::TEXT, '', COUNT(*), ROW_NUMBER() OVER (ORDER BY rating DESC)
+		FROM matched
+		GROUP BY rating`,
+	entity.FilmActorFacet: `
+		SELECT * FROM (
+			SELECT a.id::TEXT, a.name, COUNT(*), ROW_NUMBER() OVER (ORDER BY COUNT(*) DESC, a.name, a.id) AS position
+			FROM matched m
+			JOIN films_actors fa ON m.id = fa.film_id
+			JOIN actor a ON fa.actor_id = a.id AND a.deleted_at IS NULL
+			GROUP BY a.id
+		) top
+		WHERE position <= ` + strconv.Itoa(entity.FilmActorFacetLimit),
+}
+
+// Count films matching search params per bucket of each provided facet. Every provided facet is present in the result.
+func (r *FilmRepoPostgres) SelectFacets(ctx *context.Context, searchParams *entity.FilmSearchParams, facets []string) (buckets map[string][]*entity.FacetBucket, err error) {
+	conditions, args := filmSearchConditions(searchParams)
+	parts := make([]string, 0, len(facets))
+	buckets = make(map[string][]*entity.FacetBucket, len(facets))
+	for _, facet := range facets {
+		parts = append(parts, "SELECT '"+facet+"' AS facet, * FROM ("+filmFacetQueries[facet]+") "+facet+"_buckets")
+		buckets[facet] = []*entity.FacetBucket{}
+	}
+	query := `
+		WITH matched AS (
+			SELECT DISTINCT f.id, f.release_date, f.rating
+			FROM film f
+			LEFT JOIN films_actors fa ON f.id = fa.film_id
+			LEFT JOIN actor a ON fa.actor_id = a.id AND a.deleted_at IS NULL
+			WHERE ` + strings.Join(conditions, " AND ") + `
+		)
+		SELECT facet, value, label, count FROM (` + strings.Join(parts, " UNION ALL ") + `) facets (facet, value, label, count, position)
+		ORDER BY facet, position;`
+	rows, err := r.store.Conn(*ctx).QueryContext(*ctx, query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var facet string
+		bucket := &entity.FacetBucket{}
+		if err = rows.Scan(&facet, &bucket.Value, &bucket.Label, &bucket.Count); err != nil {
+			return
+		}
+		buckets[facet] = append(buckets[facet], bucket)
+	}
+	err = rows.Err()
+	return
+}
+
+// Full-text search query, its text is always the first query argument.
+// Russian configuration stems latin words as English, so both queries are combined to match both indexed configurations.
+const filmTSQuery = "(WEBSEARCH_TO_TSQUERY('english', $1) || WEBSEARCH_TO_TSQUERY('russian', $1))"
+
+// Build conditions and their arguments for searching films. Conditions use aliases f for film and a for actor.
+func filmSearchConditions(searchParams *entity.FilmSearchParams) (conditions []string, args []interface{}) {
+	conditions = []string{"f.deleted_at IS NULL"}
+	if searchParams == nil {
+		return
+	}
 	paramIndex := 1
-	if searchParams != nil {
-		if searchParams.Title != "" {
-			conditions = append(conditions, "f.title ILIKE $"+strconv.Itoa(paramIndex))
-			args = append(args, "%"+searchParams.Title+"%")
+	if searchParams.Query != "" {
+		conditions = append(conditions, "f.search_vector @@ "+filmTSQuery)
+		args = append(args, searchParams.Query)
+		paramIndex++
+	}
+	if searchParams.Title != "" {
+		conditions = append(conditions, "(f.title ILIKE $"+strconv.Itoa(paramIndex)+
+			" OR EXISTS (SELECT 1 FROM film_translation ft WHERE ft.film_id = f.id AND ft.title ILIKE $"+strconv.Itoa(paramIndex)+"))")
+		args = append(args, "%"+searchParams.Title+"%")
+		paramIndex++
+	}
+	if searchParams.ActorName != "" {
+		conditions = append(conditions, "(a.name ILIKE $"+strconv.Itoa(paramIndex)+
+			" OR EXISTS (SELECT 1 FROM UNNEST(a.aliases) AS alias WHERE alias ILIKE $"+strconv.Itoa(paramIndex)+")"+
+			" OR EXISTS (SELECT 1 FROM actor_translation t WHERE t.actor_id = a.id AND t.name ILIKE $"+strconv.Itoa(paramIndex)+"))")
+		args = append(args, "%"+searchParams.ActorName+"%")
+		paramIndex++
+	}
+	if searchParams.InWatchlistOf != 0 {
+		conditions = append(conditions, "f.id IN (SELECT film_id FROM watchlist WHERE user_id = $"+strconv.Itoa(paramIndex)+")")
+		args = append(args, searchParams.InWatchlistOf)
+		paramIndex++
+	}
+	if searchParams.Collection != "" {
+		condition := "f.id IN (SELECT cf.film_id FROM collections_films cf JOIN collection c ON cf.collection_id = c.id WHERE c.slug = $" + strconv.Itoa(paramIndex)
+		if !searchParams.WithDraftCollections {
+			condition += " AND c.status = 'published'"
+		}
+		conditions = append(conditions, condition+")")
+		args = append(args, searchParams.Collection)
+		paramIndex++
+	}
+	if searchParams.Franchise != 0 {
+		conditions = append(conditions, "f.id IN (SELECT film_id FROM franchises_films WHERE franchise_id = $"+strconv.Itoa(paramIndex)+")")
+		args = append(args, searchParams.Franchise)
+		paramIndex++
+	}
+	if searchParams.Country != "" || searchParams.ReleaseType != "" {
+		condition := "EXISTS (SELECT 1 FROM film_release fr WHERE fr.film_id = f.id"
+		if searchParams.Country != "" {
+			condition += " AND fr.country = $" + strconv.Itoa(paramIndex)
+			args = append(args, searchParams.Country)
 			paramIndex++
 		}
-		if searchParams.ActorName != "" {
-			conditions = append(conditions, "a.name ILIKE $"+strconv.Itoa(paramIndex))
-			args = append(args, "%"+searchParams.ActorName+"%")
+		if searchParams.ReleaseType != "" {
+			condition += " AND fr.release_type = $" + strconv.Itoa(paramIndex)
+			args = append(args, searchParams.ReleaseType)
+			paramIndex++
+		}
+		if !searchParams.ReleasedAfter.IsZero() {
+			condition += " AND fr.release_date >= $" + strconv.Itoa(paramIndex)
+			args = append(args, searchParams.ReleasedAfter.Time)
+			paramIndex++
+		}
+		if !searchParams.ReleasedBefore.IsZero() {
+			condition += " AND fr.release_date < $" + strconv.Itoa(paramIndex)
+			args = append(args, searchParams.ReleasedBefore.End())
+			paramIndex++
+		}
+		conditions = append(conditions, condition+")")
+	} else {
+		if !searchParams.ReleasedAfter.IsZero() {
+			conditions = append(conditions, "f.release_date >= $"+strconv.Itoa(paramIndex))
+			args = append(args, searchParams.ReleasedAfter.Time)
+			paramIndex++
+		}
+		if !searchParams.ReleasedBefore.IsZero() {
+			conditions = append(conditions, "f.release_date < $"+strconv.Itoa(paramIndex))
+			args = append(args, searchParams.ReleasedBefore.End())
 			paramIndex++
 		}
 	}
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
+	if searchParams.MaxCertificationRank != nil {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM film_certification fc WHERE fc.film_id = f.id AND fc.country = $"+
+			strconv.Itoa(paramIndex)+" AND fc.rank <= $"+strconv.Itoa(paramIndex+1)+")")
+		args = append(args, searchParams.Country, *searchParams.MaxCertificationRank)
 	}
-	query += " GROUP BY f.id"
-	if sortParams != nil {
-		query += " ORDER BY " + sortParams.Field + " " + sortParams.Order
+	return
+}
+
+// Get a Film with its actors by id.
+func (r *FilmRepoPostgres) SelectByID(ctx *context.Context, id int) (film *entity.FilmWithActors, err error) {
+	film = &entity.FilmWithActors{}
+	var actorsIDs pq.Int64Array
+	err = r.store.Conn(*ctx).QueryRowContext(*ctx, `
+		SELECT f.id, f.title, f.description, f.release_date, f.release_date_precision, f.rating, f.user_rating_avg, f.user_rating_count, f.version,
+			f.poster, ARRAY_REMOVE(ARRAY_AGG(a.id ORDER BY a.id), NULL) AS actor_ids
+		FROM film f
+		LEFT JOIN films_actors fa ON f.id = fa.film_id
+		LEFT JOIN actor a ON fa.actor_id = a.id AND a.deleted_at IS NULL
+		WHERE f.id = $1 AND f.deleted_at IS NULL
+		GROUP BY f.id;`, id).
+		Scan(&film.ID, &film.Title, &film.Description, &film.ReleaseDate, &film.ReleaseDate.Precision, &film.Rating,
+			&film.UserRatingAvg, &film.UserRatingCount, &film.Version, &film.Poster, &actorsIDs)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = ErrFilmNotFound
+		}
+		return
 	}
-	rows, err := r.store.DB.QueryContext(*ctx, query, args...)
+	film.ActorsIDs = int64sToInts(actorsIDs)
+	return
+}
+
+// Get Films with their actors by ids, missing and soft-deleted films are skipped.
+func (r *FilmRepoPostgres) SelectByIDs(ctx *context.Context, ids []int) (films []*entity.FilmWithActors, err error) {
+	rows, err := r.store.Conn(*ctx).QueryContext(*ctx, `
+		SELECT f.id, f.title, f.description, f.release_date, f.release_date_precision, f.rating, f.user_rating_avg, f.user_rating_count, f.version,
+			f.poster, ARRAY_REMOVE(ARRAY_AGG(a.id ORDER BY a.id), NULL) AS actor_ids
+		FROM film f
+		LEFT JOIN films_actors fa ON f.id = fa.film_id
+		LEFT JOIN actor a ON fa.actor_id = a.id AND a.deleted_at IS NULL
+		WHERE f.id = ANY($1) AND f.deleted_at IS NULL
+		GROUP BY f.id;`, pq.Array(ids))
 	if err != nil {
 		return
 	}
 	defer rows.Close()
 	for rows.Next() {
-		film := entity.FilmWithActors{}
-		var actorsIDsRaw []byte
-		if err = rows.Scan(&film.ID, &film.Title, &film.Description, &film.ReleaseDate, &film.Rating, &actorsIDsRaw); err != nil {
+		film := &entity.FilmWithActors{}
+		var actorsIDs pq.Int64Array
+		err = rows.Scan(&film.ID, &film.Title, &film.Description, &film.ReleaseDate, &film.ReleaseDate.Precision, &film.Rating,
+			&film.UserRatingAvg, &film.UserRatingCount, &film.Version, &film.Poster, &actorsIDs)
+		if err != nil {
 			return
 		}
-		// TODO: Refactor this also
-		actorsIDsStr := string(actorsIDsRaw)
-		if actorsIDsStr == "{NULL}" {
-			film.ActorsIDs = []int{}
-		} else {
-			actorsIDsStr = strings.Trim(actorsIDsStr, "{}")
-			actorsIDsStrSlice := strings.Split(actorsIDsStr, ",")
-			actorsIDs := make([]int, len(actorsIDsStrSlice))
-			for i, idStr := range actorsIDsStrSlice {
-				actorsIDs[i], _ = strconv.Atoi(idStr)
-			}
-			film.ActorsIDs = actorsIDs
+		film.ActorsIDs = int64sToInts(actorsIDs)
+		films = append(films, film)
+	}
+	err = rows.Err()
+	return
+}
+
+// Get all soft-deleted films, recently deleted first.
+func (r *FilmRepoPostgres) SelectDeleted(ctx *context.Context) (films []*entity.FilmWithActors, err error) {
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
+		SELECT f.id, f.title, f.description, f.release_date, f.release_date_precision, f.rating, f.user_rating_avg, f.user_rating_count,
+			f.version, f.poster, f.deleted_at, ARRAY_REMOVE(ARRAY_AGG(fa.actor_id), NULL) AS actor_ids
+		FROM film f
+		LEFT JOIN films_actors fa ON f.id = fa.film_id
+		WHERE f.deleted_at IS NOT NULL
+		GROUP BY f.id
+		ORDER BY f.deleted_at DESC;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		film := &entity.FilmWithActors{}
+		var actorsIDs pq.Int64Array
+		err = rows.Scan(&film.ID, &film.Title, &film.Description, &film.ReleaseDate, &film.ReleaseDate.Precision, &film.Rating,
+			&film.UserRatingAvg, &film.UserRatingCount, &film.Version, &film.Poster, &film.DeletedAt, &actorsIDs)
+		if err != nil {
+			return
 		}
-		films = append(films, &film)
+		film.ActorsIDs = int64sToInts(actorsIDs)
+		films = append(films, film)
+	}
+	err = rows.Err()
+	return
+}
+
+// Restore a soft-deleted Film by id and bump versions of its actors. Its cast links are kept while in trash, so they come back as well.
+func (r *FilmRepoPostgres) Restore(ctx *context.Context, id int) (err error) {
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
+		UPDATE film
+		SET deleted_at = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(*ctx, id)
+	if err != nil {
+		return
+	}
+	if cntRows, _ := res.RowsAffected(); cntRows == 0 {
+		err = ErrFilmNotFound
+		return
+	}
+	err = r.bumpActorsVersions(ctx, id)
+	return
+}
+
+// Bump versions of Actors linked to the Film, since their responses list only linked Films that are not deleted.
+func (r *FilmRepoPostgres) bumpActorsVersions(ctx *context.Context, id int) (err error) {
+	_, err = r.store.Conn(*ctx).ExecContext(*ctx, `
+		UPDATE actor SET version = version + 1
+		WHERE deleted_at IS NULL AND id IN (SELECT actor_id FROM films_actors WHERE film_id = $1);`, id)
+	return
+}
+
+// Format a duration as a PostgreSQL interval.
+func formatInterval(d time.Duration) string {
+	return strconv.FormatInt(d.Microseconds(), 10) + " microseconds"
+}
+
+// Hard-delete Films that were soft-deleted longer than retention period ago.
+// deleted_at is set by NOW() without time zone, so it is compared with the database clock rather than a time from Go.
+func (r *FilmRepoPostgres) Purge(ctx *context.Context, retentionPeriod time.Duration) (cntPurged int64, err error) {
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
+		DELETE FROM film
+		WHERE deleted_at IS NOT NULL AND deleted_at < NOW() - $1::interval;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(*ctx, formatInterval(retentionPeriod))
+	if err != nil {
+		return
 	}
+	cntPurged, err = res.RowsAffected()
 	return
 }
@@ -2,6 +2,8 @@ package repo
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	"github.com/itmosha/vk-internship-2024/internal/entity"
 	"github.com/itmosha/vk-internship-2024/pkg/postgres"
@@ -20,9 +22,10 @@ func NewFilmsActorsRepoPostgres(store *postgres.Postgres) *FilmsActorsRepoPostgr
 // Insert a new FilmActor with provided fields.
 func (r *FilmsActorsRepoPostgres) Insert(ctx *context.Context, receivedFilmActor *entity.FilmActor) (createdFilmActor *entity.FilmActor, err error) {
 	createdFilmActor = &entity.FilmActor{}
-	stmt, err := r.store.DB.PrepareContext(*ctx, `
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
 		INSERT INTO films_actors (film_id, actor_id)
-		VALUES ($1, $2)
+		SELECT $1::INTEGER, $2::INTEGER
+		WHERE NOT EXISTS (SELECT 1 FROM actor WHERE id = $2 AND deleted_at IS NOT NULL)
 		RETURNING film_id, actor_id;`)
 	if err != nil {
 		return
@@ -31,7 +34,9 @@ func (r *FilmsActorsRepoPostgres) Insert(ctx *context.Context, receivedFilmActor
 
 	err = stmt.QueryRowContext(*ctx, receivedFilmActor.FilmID, receivedFilmActor.ActorID).
 		Scan(&createdFilmActor.FilmID, &createdFilmActor.ActorID)
-	if err != nil {
+	if errors.Is(err, sql.ErrNoRows) { // Actor is in trash
+		err = ErrActorNotFound
+	} else if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			if pqErr.Constraint == "films_actors_actor_id_fkey" {
 				// TODO: return id of actor that doesn't exist
@@ -47,7 +52,7 @@ func (r *FilmsActorsRepoPostgres) Insert(ctx *context.Context, receivedFilmActor
 
 // Delete a FilmActor by film_id and actor_id.
 func (r *FilmsActorsRepoPostgres) Delete(ctx *context.Context, filmID, actorID int) (err error) {
-	stmt, err := r.store.DB.PrepareContext(*ctx, `
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
 		DELETE FROM films_actors
 		WHERE film_id = $1 AND actor_id = $2`)
 	if err != nil {
@@ -66,7 +71,7 @@ func (r *FilmsActorsRepoPostgres) Delete(ctx *context.Context, filmID, actorID i
 }
 
 func (r *FilmsActorsRepoPostgres) SelectByFilmID(ctx *context.Context, filmID int) (filmsActors []*entity.FilmActor, err error) {
-	stmt, err := r.store.DB.PrepareContext(*ctx, `
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
 		SELECT film_id, actor_id
 		FROM films_actors
 		WHERE film_id = $1;`)
@@ -76,20 +81,24 @@ func (r *FilmsActorsRepoPostgres) SelectByFilmID(ctx *context.Context, filmID in
 	defer stmt.Close()
 
 	rows, err := stmt.QueryContext(*ctx, filmID)
-
+	if err != nil {
+		return
+	}
+	defer rows.Close()
 	for rows.Next() {
 		filmActor := &entity.FilmActor{}
-		err = stmt.QueryRowContext(*ctx, filmID).Scan(&filmActor.FilmID, &filmActor.ActorID)
+		err = rows.Scan(&filmActor.FilmID, &filmActor.ActorID)
 		if err != nil {
 			return
 		}
 		filmsActors = append(filmsActors, filmActor)
 	}
+	err = rows.Err()
 	return
 }
 
 func (r *FilmsActorsRepoPostgres) SelectByActorID(ctx *context.Context, actorID int) (filmsActors []*entity.FilmActor, err error) {
-	stmt, err := r.store.DB.PrepareContext(*ctx, `
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
 		SELECT film_id, actor_id
 		FROM films_actors
 		WHERE actor_id = $1;`)
@@ -99,14 +108,18 @@ func (r *FilmsActorsRepoPostgres) SelectByActorID(ctx *context.Context, actorID
 	defer stmt.Close()
 
 	rows, err := stmt.QueryContext(*ctx, actorID)
-
+	if err != nil {
+		return
+	}
+	defer rows.Close()
 	for rows.Next() {
 		filmActor := &entity.FilmActor{}
-		err = stmt.QueryRowContext(*ctx, actorID).Scan(&filmActor.FilmID, &filmActor.ActorID)
+		err = rows.Scan(&filmActor.FilmID, &filmActor.ActorID)
 		if err != nil {
 			return
 		}
 		filmsActors = append(filmsActors, filmActor)
 	}
+	err = rows.Err()
 	return
 }
@@ -0,0 +1,182 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+	"github.com/lib/pq"
+)
+
+type FranchiseRepoPostgres struct {
+	store *postgres.Postgres
+}
+
+// Create new FranchiseRepoPostgres.
+func NewFranchiseRepoPostgres(store *postgres.Postgres) *FranchiseRepoPostgres {
+	return &FranchiseRepoPostgres{store}
+}
+
+// Insert a new Franchise with its films. Must be called in a transaction.
+func (r *FranchiseRepoPostgres) Insert(ctx *context.Context, receivedFranchise *entity.Franchise) (createdFranchise *entity.Franchise, err error) {
+	conn := r.store.Conn(*ctx)
+	createdFranchise = &entity.Franchise{}
+	err = conn.QueryRowContext(*ctx, `
+		INSERT INTO franchise (name)
+		VALUES ($1)
+		RETURNING id, name;`, receivedFranchise.Name).
+		Scan(&createdFranchise.ID, &createdFranchise.Name)
+	if err != nil {
+		return
+	}
+	err = replaceFranchiseFilms(ctx, conn, createdFranchise.ID, receivedFranchise.FilmsIDs)
+	createdFranchise.FilmsIDs = receivedFranchise.FilmsIDs
+	return
+}
+
+// Replace name and films of a Franchise by id. Must be called in a transaction.
+func (r *FranchiseRepoPostgres) Replace(ctx *context.Context, id int, receivedFranchise *entity.Franchise) (err error) {
+	conn := r.store.Conn(*ctx)
+	res, err := conn.ExecContext(*ctx, `UPDATE franchise SET name = $1 WHERE id = $2;`, receivedFranchise.Name, id)
+	if err != nil {
+		return
+	}
+	if cntRows, _ := res.RowsAffected(); cntRows == 0 {
+		err = ErrFranchiseNotFound
+		return
+	}
+	err = replaceFranchiseFilms(ctx, conn, id, receivedFranchise.FilmsIDs)
+	return
+}
+
+// Delete a Franchise by id. Must be called in a transaction.
+func (r *FranchiseRepoPostgres) Delete(ctx *context.Context, id int) (err error) {
+	conn := r.store.Conn(*ctx)
+	if err = bumpFranchiseFilmsVersions(ctx, conn, id); err != nil {
+		return
+	}
+	res, err := conn.ExecContext(*ctx, `DELETE FROM franchise WHERE id = $1;`, id)
+	if err != nil {
+		return
+	}
+	if cntRows, _ := res.RowsAffected(); cntRows == 0 {
+		err = ErrFranchiseNotFound
+	}
+	return
+}
+
+// Get all Franchises ordered by name.
+func (r *FranchiseRepoPostgres) SelectAll(ctx *context.Context) (franchises []*entity.Franchise, err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		SELECT fr.id, fr.name, ARRAY_REMOVE(ARRAY_AGG(ff.film_id ORDER BY ff.position), NULL) AS films_ids
+		FROM franchise fr
+		LEFT JOIN franchises_films ff ON fr.id = ff.franchise_id
+		GROUP BY fr.id
+		ORDER BY fr.name, fr.id;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		franchise := &entity.Franchise{}
+		var filmsIDs pq.Int64Array
+		if err = rows.Scan(&franchise.ID, &franchise.Name, &filmsIDs); err != nil {
+			return
+		}
+		franchise.FilmsIDs = int64sToInts(filmsIDs)
+		franchises = append(franchises, franchise)
+	}
+	err = rows.Err()
+	return
+}
+
+// Get a Franchise by id.
+func (r *FranchiseRepoPostgres) SelectByID(ctx *context.Context, id int) (franchise *entity.Franchise, err error) {
+	franchise = &entity.Franchise{}
+	var filmsIDs pq.Int64Array
+	err = r.store.DB.QueryRowContext(*ctx, `
+		SELECT fr.id, fr.name, ARRAY_REMOVE(ARRAY_AGG(ff.film_id ORDER BY ff.position), NULL) AS films_ids
+		FROM franchise fr
+		LEFT JOIN franchises_films ff ON fr.id = ff.franchise_id
+		WHERE fr.id = $1
+		GROUP BY fr.id;`, id).
+		Scan(&franchise.ID, &franchise.Name, &filmsIDs)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = ErrFranchiseNotFound
+		}
+		return
+	}
+	franchise.FilmsIDs = int64sToInts(filmsIDs)
+	return
+}
+
+// Get Franchises of a Film with its position in each of them, ordered by name.
+func (r *FranchiseRepoPostgres) SelectByFilmID(ctx *context.Context, filmID int) (franchises []*entity.FilmFranchise, err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		SELECT fr.id, fr.name, ff.position + 1
+		FROM franchises_films ff
+		JOIN franchise fr ON ff.franchise_id = fr.id
+		WHERE ff.film_id = $1
+		ORDER BY fr.name, fr.id;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx, filmID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		franchise := &entity.FilmFranchise{}
+		if err = rows.Scan(&franchise.ID, &franchise.Name, &franchise.Position); err != nil {
+			return
+		}
+		franchises = append(franchises, franchise)
+	}
+	err = rows.Err()
+	return
+}
+
+// Replace all films of a franchise keeping the provided order.
+// Versions of films that were or become part of the franchise are bumped, as their representations change.
+func replaceFranchiseFilms(ctx *context.Context, conn postgres.Conn, franchiseID int, filmsIDs []int) (err error) {
+	if err = bumpFranchiseFilmsVersions(ctx, conn, franchiseID); err != nil {
+		return
+	}
+	_, err = conn.ExecContext(*ctx, `DELETE FROM franchises_films WHERE franchise_id = $1;`, franchiseID)
+	if err != nil {
+		return
+	}
+	for position, filmID := range filmsIDs {
+		_, err = conn.ExecContext(*ctx, `
+			INSERT INTO franchises_films (franchise_id, film_id, position)
+			VALUES ($1, $2, $3);`, franchiseID, filmID, position)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Constraint == "franchises_films_film_id_fkey" {
+				err = ErrFilmNotFound
+			}
+			return
+		}
+	}
+	_, err = conn.ExecContext(*ctx, `UPDATE film SET version = version + 1 WHERE id = ANY($1);`, pq.Array(filmsIDs))
+	return
+}
+
+// Bump versions of all films of a franchise.
+func bumpFranchiseFilmsVersions(ctx *context.Context, conn postgres.Conn, franchiseID int) (err error) {
+	_, err = conn.ExecContext(*ctx, `
+		UPDATE film SET version = version + 1
+		WHERE id IN (SELECT film_id FROM franchises_films WHERE franchise_id = $1);`, franchiseID)
+	return
+}
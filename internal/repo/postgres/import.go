@@ -0,0 +1,292 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+	"github.com/lib/pq"
+)
+
+type ImportRepoPostgres struct {
+	store *postgres.Postgres
+}
+
+// Create new ImportRepoPostgres.
+func NewImportRepoPostgres(store *postgres.Postgres) *ImportRepoPostgres {
+	return &ImportRepoPostgres{store}
+}
+
+// Select ids of not deleted Actors with provided names, grouped by name.
+func (r *ImportRepoPostgres) SelectActorsIDsByNames(ctx *context.Context, names []string) (ids map[string][]int, err error) {
+	ids = make(map[string][]int)
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
+		SELECT name, id
+		FROM actor
+		WHERE deleted_at IS NULL AND name = ANY($1)
+		ORDER BY id;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx, pq.Array(names))
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var id int
+		if err = rows.Scan(&name, &id); err != nil {
+			return
+		}
+		ids[name] = append(ids[name], id)
+	}
+	err = rows.Err()
+	return
+}
+
+// Import a batch of Actors in the transaction of ctx. Rows are copied to a temporary table,
+// then rows with id update existing Actors and rows without id are inserted as new Actors.
+// In dry-run mode new Actors get negative ids, see importNewID.
+func (r *ImportRepoPostgres) ImportActors(ctx *context.Context, rows []*entity.ActorImportRow, dryRun bool) (results []*entity.ImportRowResult, err error) {
+	tx := r.store.Conn(*ctx)
+	_, err = tx.ExecContext(*ctx, `
+		CREATE TEMPORARY TABLE actor_import (
+			row_num INTEGER PRIMARY KEY,
+			id INTEGER,
+			name VARCHAR(100) NOT NULL,
+			gender VARCHAR(20) NOT NULL,
+			birth_date DATE NOT NULL,
+			death_date DATE,
+			biography TEXT NOT NULL,
+			birthplace VARCHAR(150) NOT NULL,
+			nationality VARCHAR(2) NOT NULL,
+			aliases VARCHAR(100)[] NOT NULL,
+			external_ids JSONB NOT NULL,
+			is_new BOOLEAN NOT NULL DEFAULT FALSE
+		) ON COMMIT DROP;`)
+	if err != nil {
+		return
+	}
+	stmt, err := tx.PrepareContext(*ctx, pq.CopyIn("actor_import", "row_num", "id", "name", "gender", "birth_date", "death_date",
+		"biography", "birthplace", "nationality", "aliases", "external_ids"))
+	if err != nil {
+		return
+	}
+	for _, row := range rows {
+		var birthDate, deathDate entity.Date
+		if birthDate, err = entity.ParseFullDate(row.BirthDate); err != nil {
+			stmt.Close()
+			return
+		}
+		if row.DeathDate != "" {
+			if deathDate, err = entity.ParseFullDate(row.DeathDate); err != nil {
+				stmt.Close()
+				return
+			}
+		}
+		_, err = stmt.ExecContext(*ctx, row.Row, row.ID, row.Name, row.Gender, birthDate, deathDate,
+			row.Biography, row.Birthplace, row.Nationality, textArray(row.Aliases), entity.ExternalIDs(row.ExternalIDs))
+		if err != nil {
+			stmt.Close()
+			return
+		}
+	}
+	if _, err = stmt.ExecContext(*ctx); err != nil {
+		stmt.Close()
+		return
+	}
+	if err = stmt.Close(); err != nil {
+		return
+	}
+
+	steps := []importStep{
+		{status: entity.ImportRowStatusFailed, reason: ErrActorNotFound, query: `
+			DELETE FROM actor_import i
+			WHERE i.id IS NOT NULL AND NOT EXISTS (
+				SELECT 1 FROM actor a WHERE a.id = i.id AND a.deleted_at IS NULL
+			)
+			RETURNING i.row_num, i.id;`},
+		{status: entity.ImportRowStatusFailed, reason: entity.ErrDuplicateImportID, query: `
+			DELETE FROM actor_import i
+			WHERE i.id IS NOT NULL AND EXISTS (
+				SELECT 1 FROM actor_import j WHERE j.id = i.id AND j.row_num < i.row_num
+			)
+			RETURNING i.row_num, i.id;`},
+		{status: entity.ImportRowStatusUpdated, query: `
+			UPDATE actor a
+			SET name = i.name, gender = i.gender, birth_date = i.birth_date, death_date = i.death_date,
+				biography = i.biography, birthplace = i.birthplace, nationality = i.nationality,
+				aliases = i.aliases, external_ids = i.external_ids, version = a.version + 1
+			FROM actor_import i
+			WHERE a.id = i.id
+			RETURNING i.row_num, a.id;`},
+		{status: entity.ImportRowStatusCreated, query: `
+			UPDATE actor_import
+			SET id = ` + importNewID("actor", dryRun) + `, is_new = TRUE
+			WHERE id IS NULL
+			RETURNING row_num, id;`},
+	}
+	results, err = runImportSteps(ctx, tx, steps)
+	if err != nil {
+		return
+	}
+	_, err = tx.ExecContext(*ctx, `
+		INSERT INTO actor (id, name, gender, birth_date, death_date, biography, birthplace, nationality, aliases, external_ids)
+		SELECT id, name, gender, birth_date, death_date, biography, birthplace, nationality, aliases, external_ids
+		FROM actor_import
+		WHERE is_new;`)
+	return
+}
+
+// Import a batch of Films with their cast in the transaction of ctx. Rows are copied to a temporary table,
+// then rows with id update existing Films and rows without id are inserted as new Films.
+// Cast of every imported Film is replaced. In dry-run mode new Films get negative ids, see importNewID.
+func (r *ImportRepoPostgres) ImportFilms(ctx *context.Context, rows []*entity.FilmImportRow, dryRun bool) (results []*entity.ImportRowResult, err error) {
+	tx := r.store.Conn(*ctx)
+	_, err = tx.ExecContext(*ctx, `
+		CREATE TEMPORARY TABLE film_import (
+			row_num INTEGER PRIMARY KEY,
+			id INTEGER,
+			title VARCHAR(150) NOT NULL,
+			description VARCHAR(1000) NOT NULL,
+			release_date DATE NOT NULL,
+			release_date_precision VARCHAR(5) NOT NULL,
+			rating INTEGER NOT NULL,
+			actors_ids INTEGER[] NOT NULL,
+			is_new BOOLEAN NOT NULL DEFAULT FALSE
+		) ON COMMIT DROP;`)
+	if err != nil {
+		return
+	}
+	stmt, err := tx.PrepareContext(*ctx, pq.CopyIn("film_import", "row_num", "id", "title", "description", "release_date", "release_date_precision", "rating", "actors_ids"))
+	if err != nil {
+		return
+	}
+	for _, row := range rows {
+		var releaseDate entity.Date
+		if releaseDate, err = entity.ParseDate(row.ReleaseDate); err != nil {
+			stmt.Close()
+			return
+		}
+		_, err = stmt.ExecContext(*ctx, row.Row, row.ID, row.Title, row.Description, releaseDate, releaseDate.Precision, row.Rating, pq.Array(row.ActorsIDs))
+		if err != nil {
+			stmt.Close()
+			return
+		}
+	}
+	if _, err = stmt.ExecContext(*ctx); err != nil {
+		stmt.Close()
+		return
+	}
+	if err = stmt.Close(); err != nil {
+		return
+	}
+
+	steps := []importStep{
+		{status: entity.ImportRowStatusFailed, reason: ErrFilmNotFound, query: `
+			DELETE FROM film_import i
+			WHERE i.id IS NOT NULL AND NOT EXISTS (
+				SELECT 1 FROM film f WHERE f.id = i.id AND f.deleted_at IS NULL
+			)
+			RETURNING i.row_num, i.id;`},
+		{status: entity.ImportRowStatusFailed, reason: entity.ErrDuplicateImportID, query: `
+			DELETE FROM film_import i
+			WHERE i.id IS NOT NULL AND EXISTS (
+				SELECT 1 FROM film_import j WHERE j.id = i.id AND j.row_num < i.row_num
+			)
+			RETURNING i.row_num, i.id;`},
+		{status: entity.ImportRowStatusFailed, reason: ErrActorNotFound, query: `
+			DELETE FROM film_import i
+			WHERE EXISTS (
+				SELECT 1
+				FROM UNNEST(i.actors_ids) AS c(actor_id)
+				WHERE NOT EXISTS (SELECT 1 FROM actor a WHERE a.id = c.actor_id AND a.deleted_at IS NULL)
+			)
+			RETURNING i.row_num, i.id;`},
+		{status: entity.ImportRowStatusUpdated, query: `
+			UPDATE film f
+			SET title = i.title, description = i.description, release_date = i.release_date,
+				release_date_precision = i.release_date_precision, rating = i.rating, version = f.version + 1
+			FROM film_import i
+			WHERE f.id = i.id
+			RETURNING i.row_num, f.id;`},
+		{status: entity.ImportRowStatusCreated, query: `
+			UPDATE film_import
+			SET id = ` + importNewID("film", dryRun) + `, is_new = TRUE
+			WHERE id IS NULL
+			RETURNING row_num, id;`},
+	}
+	results, err = runImportSteps(ctx, tx, steps)
+	if err != nil {
+		return
+	}
+	_, err = tx.ExecContext(*ctx, `
+		INSERT INTO film (id, title, description, release_date, release_date_precision, rating)
+		SELECT id, title, description, release_date, release_date_precision, rating
+		FROM film_import
+		WHERE is_new;`)
+	if err != nil {
+		return
+	}
+	_, err = tx.ExecContext(*ctx, `
+		DELETE FROM films_actors
+		WHERE film_id IN (SELECT id FROM film_import);`)
+	if err != nil {
+		return
+	}
+	_, err = tx.ExecContext(*ctx, `
+		INSERT INTO films_actors (film_id, actor_id)
+		SELECT DISTINCT i.id, c.actor_id
+		FROM film_import i, UNNEST(i.actors_ids) AS c(actor_id);`)
+	return
+}
+
+// Get the expression of ids of new rows in an import table. Sequences are not rolled back with the transaction,
+// so in dry-run mode rows get their negated row numbers, which no existing row has, instead of sequence values.
+func importNewID(table string, dryRun bool) string {
+	if dryRun {
+		return "-row_num"
+	}
+	return "NEXTVAL(PG_GET_SERIAL_SEQUENCE('" + table + "', 'id'))"
+}
+
+// Statement of an import that marks rows returned by the query with status.
+// Query must return row number and entity id of every affected row.
+type importStep struct {
+	status string
+	reason error
+	query  string
+}
+
+// Run import steps one by one in provided transaction and collect results of affected rows.
+func runImportSteps(ctx *context.Context, tx postgres.Conn, steps []importStep) (results []*entity.ImportRowResult, err error) {
+	for _, step := range steps {
+		var rows *sql.Rows
+		rows, err = tx.QueryContext(*ctx, step.query)
+		if err != nil {
+			return
+		}
+		for rows.Next() {
+			result := &entity.ImportRowResult{Status: step.status}
+			var id sql.NullInt64
+			if err = rows.Scan(&result.Row, &id); err != nil {
+				rows.Close()
+				return
+			}
+			result.ID = int(id.Int64)
+			if step.reason != nil {
+				result.Error = step.reason.Error()
+			}
+			results = append(results, result)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return
+		}
+	}
+	return
+}
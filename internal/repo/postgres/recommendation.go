@@ -0,0 +1,194 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+)
+
+type RecommendationRepoPostgres struct {
+	store *postgres.Postgres
+}
+
+// Create new RecommendationRepoPostgres.
+func NewRecommendationRepoPostgres(store *postgres.Postgres) *RecommendationRepoPostgres {
+	return &RecommendationRepoPostgres{store}
+}
+
+// Get a page of Films sharing cast with a Film, most similar first.
+// Score is the cosine similarity of casts weighted by 0.8 plus proximity of ratings weighted by 0.2.
+func (r *RecommendationRepoPostgres) SelectSimilar(ctx *context.Context, filmID int, pagination *entity.PaginationParams) (films []*entity.FilmRecommendation, err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		WITH target_cast AS (
+			SELECT fa.actor_id
+			FROM films_actors fa
+			JOIN actor a ON fa.actor_id = a.id AND a.deleted_at IS NULL
+			WHERE fa.film_id = $1
+		),
+		candidates AS (
+			SELECT fa.film_id, COUNT(*) AS shared_actors_count
+			FROM films_actors fa
+			WHERE fa.actor_id IN (SELECT actor_id FROM target_cast) AND fa.film_id <> $1
+			GROUP BY fa.film_id
+		),
+		cast_sizes AS (
+			SELECT fa.film_id, COUNT(*) AS cast_size
+			FROM films_actors fa
+			JOIN actor a ON fa.actor_id = a.id AND a.deleted_at IS NULL
+			WHERE fa.film_id IN (SELECT film_id FROM candidates)
+			GROUP BY fa.film_id
+		)
+		SELECT f.id, f.title, f.release_date, f.release_date_precision, f.rating, f.user_rating_avg, f.poster, c.shared_actors_count,
+			0.8 * c.shared_actors_count::FLOAT8 / SQRT(cs.cast_size * (SELECT COUNT(*) FROM target_cast))
+				+ 0.2 * (1 - ABS(f.rating - t.rating) / 10.0) AS score
+		FROM candidates c
+		JOIN cast_sizes cs ON c.film_id = cs.film_id
+		JOIN film f ON c.film_id = f.id AND f.deleted_at IS NULL
+		CROSS JOIN (SELECT rating FROM film WHERE id = $1) t
+		ORDER BY score DESC, f.id
+		LIMIT $2 OFFSET $3;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx, filmID, pagination.Limit, pagination.Offset)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		film := &entity.FilmRecommendation{}
+		err = rows.Scan(&film.ID, &film.Title, &film.ReleaseDate, &film.ReleaseDate.Precision, &film.Rating,
+			&film.UserRatingAvg, &film.Poster, &film.SharedActorsCount, &film.Score)
+		if err != nil {
+			return
+		}
+		films = append(films, film)
+	}
+	err = rows.Err()
+	return
+}
+
+// Get a page of Films recommended to a user, with the highest predicted rating first.
+// Ratings are predicted from the user's ratings of the most similar Films, rated and watched Films are skipped.
+func (r *RecommendationRepoPostgres) SelectByUserID(ctx *context.Context, userID int, pagination *entity.PaginationParams) (films []*entity.FilmRecommendation, err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		WITH user_ratings AS (
+			SELECT film_id, score - AVG(score) OVER () AS deviation, AVG(score) OVER () AS average
+			FROM review
+			WHERE user_id = $1
+		)
+		SELECT f.id, f.title, f.release_date, f.release_date_precision, f.rating, f.user_rating_avg, f.poster,
+			LEAST(10, GREATEST(0, MAX(ur.average) + SUM(s.score * ur.deviation) / SUM(s.score)))::FLOAT8 AS predicted_rating
+		FROM user_ratings ur
+		JOIN film_similarity s ON ur.film_id = s.similar_film_id
+		JOIN film f ON s.film_id = f.id AND f.deleted_at IS NULL
+		WHERE NOT EXISTS (SELECT 1 FROM review rv WHERE rv.user_id = $1 AND rv.film_id = f.id)
+			AND NOT EXISTS (SELECT 1 FROM watched w WHERE w.user_id = $1 AND w.film_id = f.id)
+		GROUP BY f.id
+		ORDER BY predicted_rating DESC, SUM(s.score) DESC, f.id
+		LIMIT $2 OFFSET $3;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx, userID, pagination.Limit, pagination.Offset)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	films, err = scanFilmRecommendations(rows)
+	return
+}
+
+// Get a page of Films with the highest average user rating, rated and watched by a user Films are skipped.
+func (r *RecommendationRepoPostgres) SelectPopular(ctx *context.Context, userID int, pagination *entity.PaginationParams) (films []*entity.FilmRecommendation, err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		SELECT f.id, f.title, f.release_date, f.release_date_precision, f.rating, f.user_rating_avg, f.poster, f.user_rating_avg::FLOAT8
+		FROM film f
+		WHERE f.deleted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM review rv WHERE rv.user_id = $1 AND rv.film_id = f.id)
+			AND NOT EXISTS (SELECT 1 FROM watched w WHERE w.user_id = $1 AND w.film_id = f.id)
+		ORDER BY f.user_rating_avg DESC, f.user_rating_count DESC, f.id
+		LIMIT $2 OFFSET $3;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx, userID, pagination.Limit, pagination.Offset)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	films, err = scanFilmRecommendations(rows)
+	return
+}
+
+// Recompute rating-based similarity of all Films.
+// Similarity is the cosine of users' ratings centered by their averages, over users that rated both Films.
+func (r *RecommendationRepoPostgres) RefreshSimilarities(ctx *context.Context) (cntPairs int64, err error) {
+	tx, err := r.store.DB.BeginTx(*ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if _, err = tx.ExecContext(*ctx, `DELETE FROM film_similarity;`); err != nil {
+		return
+	}
+	res, err := tx.ExecContext(*ctx, `
+		WITH centered AS (
+			SELECT r.user_id, r.film_id, r.score - AVG(r.score) OVER (PARTITION BY r.user_id) AS deviation
+			FROM review r
+			JOIN film f ON r.film_id = f.id AND f.deleted_at IS NULL
+		),
+		pairs AS (
+			SELECT a.film_id, b.film_id AS similar_film_id, COUNT(*) AS common_raters,
+				SUM(a.deviation * b.deviation) / NULLIF(SQRT(SUM(a.deviation ^ 2) * SUM(b.deviation ^ 2)), 0) AS score
+			FROM centered a
+			JOIN centered b ON a.user_id = b.user_id AND a.film_id <> b.film_id
+			GROUP BY a.film_id, b.film_id
+			HAVING COUNT(*) >= $1
+		),
+		ranked AS (
+			SELECT film_id, similar_film_id, common_raters, score,
+				ROW_NUMBER() OVER (PARTITION BY film_id ORDER BY score DESC, similar_film_id) AS position
+			FROM pairs
+			WHERE score > 0
+		)
+		INSERT INTO film_similarity (film_id, similar_film_id, score, common_raters)
+		SELECT film_id, similar_film_id, score, common_raters
+		FROM ranked
+		WHERE position <= $2;`, entity.MinCommonRaters, entity.MaxSimilarFilmsPerFilm)
+	if err != nil {
+		return
+	}
+	cntPairs, err = res.RowsAffected()
+	return
+}
+
+// Scan recommended films with their scores.
+func scanFilmRecommendations(rows *sql.Rows) (films []*entity.FilmRecommendation, err error) {
+	for rows.Next() {
+		film := &entity.FilmRecommendation{}
+		err = rows.Scan(&film.ID, &film.Title, &film.ReleaseDate, &film.ReleaseDate.Precision, &film.Rating,
+			&film.UserRatingAvg, &film.Poster, &film.Score)
+		if err != nil {
+			return
+		}
+		films = append(films, film)
+	}
+	err = rows.Err()
+	return
+}
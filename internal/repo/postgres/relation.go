@@ -0,0 +1,126 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+	"github.com/lib/pq"
+)
+
+type RelationRepoPostgres struct {
+	store *postgres.Postgres
+}
+
+// Create new RelationRepoPostgres.
+func NewRelationRepoPostgres(store *postgres.Postgres) *RelationRepoPostgres {
+	return &RelationRepoPostgres{store}
+}
+
+// Get relations of a Film to other not deleted Films, ordered by their release date.
+// Relations stored on the other Film are returned with the inverse type.
+func (r *RelationRepoPostgres) SelectByFilmID(ctx *context.Context, filmID int) (relations []*entity.FilmRelation, err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		SELECT fr.relation_type, FALSE, f.id, f.title, f.release_date, f.release_date_precision
+		FROM film_relation fr
+		JOIN film f ON fr.related_film_id = f.id
+		WHERE fr.film_id = $1 AND f.deleted_at IS NULL
+		UNION ALL
+		SELECT fr.relation_type, TRUE, f.id, f.title, f.release_date, f.release_date_precision
+		FROM film_relation fr
+		JOIN film f ON fr.film_id = f.id
+		WHERE fr.related_film_id = $1 AND f.deleted_at IS NULL
+		ORDER BY 5, 3;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx, filmID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		relation := &entity.FilmRelation{}
+		var isInverse bool
+		err = rows.Scan(&relation.Type, &isInverse, &relation.FilmID, &relation.Title,
+			&relation.ReleaseDate, &relation.ReleaseDate.Precision)
+		if err != nil {
+			return
+		}
+		if isInverse {
+			relation.Type = relation.Type.Inverse()
+		}
+		relations = append(relations, relation)
+	}
+	err = rows.Err()
+	return
+}
+
+// Insert a relation meaning that a Film is of relationType to the related Film, e.g. its sequel.
+// Prequels must be inserted as inverse sequels. Relations that would make a cycle are rejected.
+// Versions of both Films are bumped, as their representations change. Must be called in a transaction.
+func (r *RelationRepoPostgres) Insert(ctx *context.Context, filmID, relatedFilmID int, relationType entity.FilmRelationType) (err error) {
+	conn := r.store.Conn(*ctx)
+	// Concurrent inserts could make a cycle together, though neither of them does it alone.
+	if _, err = conn.ExecContext(*ctx, `LOCK TABLE film_relation IN SHARE ROW EXCLUSIVE MODE;`); err != nil {
+		return
+	}
+	if err = bumpVersion(ctx, conn, "film", filmID, ErrFilmNotFound); err != nil {
+		return
+	}
+	if err = bumpVersion(ctx, conn, "film", relatedFilmID, ErrFilmNotFound); err != nil {
+		return
+	}
+
+	// A cycle is made if the Film is reachable from the related one.
+	var isCycle bool
+	err = conn.QueryRowContext(*ctx, `
+		WITH RECURSIVE reachable (id) AS (
+			SELECT $1::INTEGER
+			UNION
+			SELECT fr.related_film_id
+			FROM film_relation fr
+			JOIN reachable ON fr.film_id = reachable.id
+		)
+		SELECT EXISTS (SELECT 1 FROM reachable WHERE id = $2);`, relatedFilmID, filmID).Scan(&isCycle)
+	if err != nil {
+		return
+	}
+	if isCycle {
+		err = ErrFilmRelationCycle
+		return
+	}
+
+	_, err = conn.ExecContext(*ctx, `
+		INSERT INTO film_relation (film_id, related_film_id, relation_type)
+		VALUES ($1, $2, $3);`, filmID, relatedFilmID, relationType)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		err = ErrNonUniqueFilmRelation
+	}
+	return
+}
+
+// Delete the relation between two Films, whichever of them it is stored on.
+// Versions of both Films are bumped, the related one may be already deleted. Must be called in a transaction.
+func (r *RelationRepoPostgres) Delete(ctx *context.Context, filmID, relatedFilmID int) (err error) {
+	conn := r.store.Conn(*ctx)
+	if err = bumpVersion(ctx, conn, "film", filmID, ErrFilmNotFound); err != nil {
+		return
+	}
+	res, err := conn.ExecContext(*ctx, `
+		DELETE FROM film_relation
+		WHERE (film_id = $1 AND related_film_id = $2) OR (film_id = $2 AND related_film_id = $1);`, filmID, relatedFilmID)
+	if err != nil {
+		return
+	}
+	if cntRows, _ := res.RowsAffected(); cntRows == 0 {
+		err = ErrFilmRelationNotFound
+		return
+	}
+	if err = bumpVersion(ctx, conn, "film", relatedFilmID, ErrFilmNotFound); err == ErrFilmNotFound {
+		err = nil
+	}
+	return
+}
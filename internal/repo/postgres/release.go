@@ -0,0 +1,103 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+	"github.com/lib/pq"
+)
+
+type ReleaseRepoPostgres struct {
+	store *postgres.Postgres
+}
+
+// Create new ReleaseRepoPostgres.
+func NewReleaseRepoPostgres(store *postgres.Postgres) *ReleaseRepoPostgres {
+	return &ReleaseRepoPostgres{store}
+}
+
+// Get releases and certifications of Films by their ids, only in the country if it is not empty.
+// Releases are ordered by country and date, certifications by country.
+func (r *ReleaseRepoPostgres) SelectByFilmsIDs(ctx *context.Context, filmsIDs []int, country string) (infos map[int]*entity.FilmReleaseInfo, err error) {
+	infos = make(map[int]*entity.FilmReleaseInfo)
+	info := func(filmID int) *entity.FilmReleaseInfo {
+		if _, ok := infos[filmID]; !ok {
+			infos[filmID] = &entity.FilmReleaseInfo{Releases: []*entity.FilmRelease{}, Certifications: []*entity.FilmCertification{}}
+		}
+		return infos[filmID]
+	}
+
+	rows, err := r.store.DB.QueryContext(*ctx, `
+		SELECT film_id, country, release_type, release_date
+		FROM film_release
+		WHERE film_id = ANY($1) AND ($2 = '' OR country = $2)
+		ORDER BY film_id, country, release_date, release_type;`, pq.Array(filmsIDs), country)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var filmID int
+		release := &entity.FilmRelease{}
+		if err = rows.Scan(&filmID, &release.Country, &release.Type, &release.Date); err != nil {
+			return
+		}
+		info(filmID).Releases = append(info(filmID).Releases, release)
+	}
+	if err = rows.Err(); err != nil {
+		return
+	}
+
+	rows, err = r.store.DB.QueryContext(*ctx, `
+		SELECT film_id, country, certification, rank
+		FROM film_certification
+		WHERE film_id = ANY($1) AND ($2 = '' OR country = $2)
+		ORDER BY film_id, country;`, pq.Array(filmsIDs), country)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var filmID int
+		certification := &entity.FilmCertification{}
+		if err = rows.Scan(&filmID, &certification.Country, &certification.Certification, &certification.Rank); err != nil {
+			return
+		}
+		info(filmID).Certifications = append(info(filmID).Certifications, certification)
+	}
+	err = rows.Err()
+	return
+}
+
+// Replace all releases and certifications of a Film. The Film's version is bumped, as its representation changes.
+// Must be called in a transaction, so that the film is never seen with a part of its releases.
+func (r *ReleaseRepoPostgres) Replace(ctx *context.Context, filmID int, info *entity.FilmReleaseInfo) (err error) {
+	conn := r.store.Conn(*ctx)
+	if err = bumpVersion(ctx, conn, "film", filmID, ErrFilmNotFound); err != nil {
+		return
+	}
+	if _, err = conn.ExecContext(*ctx, `DELETE FROM film_release WHERE film_id = $1;`, filmID); err != nil {
+		return
+	}
+	if _, err = conn.ExecContext(*ctx, `DELETE FROM film_certification WHERE film_id = $1;`, filmID); err != nil {
+		return
+	}
+	for _, release := range info.Releases {
+		_, err = conn.ExecContext(*ctx, `
+			INSERT INTO film_release (film_id, country, release_type, release_date)
+			VALUES ($1, $2, $3, $4);`, filmID, release.Country, release.Type, release.Date)
+		if err != nil {
+			return
+		}
+	}
+	for _, certification := range info.Certifications {
+		_, err = conn.ExecContext(*ctx, `
+			INSERT INTO film_certification (film_id, country, certification, rank)
+			VALUES ($1, $2, $3, $4);`, filmID, certification.Country, certification.Certification, certification.Rank)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
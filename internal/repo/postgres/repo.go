@@ -3,9 +3,23 @@ package repo
 import "errors"
 
 var (
-	ErrFilmNotFound      = errors.New("film with provided id was not found")
-	ErrActorNotFound     = errors.New("actor with provided id was not found")
-	ErrFilmActorNotFound = errors.New("film_actor with provided film_id and actor_id was not found")
-	ErrUserNotFound      = errors.New("user with provided username was not found")
-	ErrNonUniqueUsername = errors.New("user with provided username already exists")
+	ErrFilmNotFound           = errors.New("film with provided id was not found")
+	ErrActorNotFound          = errors.New("actor with provided id was not found")
+	ErrFilmActorNotFound      = errors.New("film_actor with provided film_id and actor_id was not found")
+	ErrUserNotFound           = errors.New("user with provided username was not found")
+	ErrNonUniqueUsername      = errors.New("user with provided username already exists")
+	ErrReviewNotFound         = errors.New("review of provided film by this user was not found")
+	ErrNonUniqueReview        = errors.New("review of provided film by this user already exists")
+	ErrWatchlistEntryNotFound = errors.New("film with provided id is not in the watchlist")
+	ErrWatchedEntryNotFound   = errors.New("film with provided id is not in the watched list")
+	ErrCollectionNotFound     = errors.New("collection with provided id or slug was not found")
+	ErrNonUniqueSlug          = errors.New("collection with provided slug already exists")
+	ErrRevisionNotFound       = errors.New("revision with provided id was not found")
+	ErrImageNotFound          = errors.New("image was not found")
+	ErrTranslationNotFound    = errors.New("translation to provided locale was not found")
+	ErrFilmRelationNotFound   = errors.New("films with provided ids are not related")
+	ErrNonUniqueFilmRelation  = errors.New("films with provided ids are already related")
+	ErrFilmRelationCycle      = errors.New("relation would make a cycle of films, e.g. a film would be a sequel of its own sequel")
+	ErrFranchiseNotFound      = errors.New("franchise with provided id was not found")
+	ErrVersionMismatch        = errors.New("resource was modified, its current version does not match If-Match header")
 )
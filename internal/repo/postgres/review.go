@@ -0,0 +1,181 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+	"github.com/lib/pq"
+)
+
+type ReviewRepoPostgres struct {
+	store *postgres.Postgres
+}
+
+// Create new ReviewRepoPostgres.
+func NewReviewRepoPostgres(store *postgres.Postgres) *ReviewRepoPostgres {
+	return &ReviewRepoPostgres{store}
+}
+
+// Insert a new Review and refresh the film's user rating.
+// Must be called in a transaction, so that the film can not be moved to trash until it is committed.
+func (r *ReviewRepoPostgres) Insert(ctx *context.Context, receivedReview *entity.Review) (createdReview *entity.Review, err error) {
+	conn := r.store.Conn(*ctx)
+	err = lockReviewedFilm(ctx, conn, receivedReview.FilmID)
+	if err != nil {
+		return
+	}
+
+	createdReview = &entity.Review{}
+	err = conn.QueryRowContext(*ctx, `
+		INSERT INTO review (film_id, user_id, score, text)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, film_id, user_id, score, text, created_at, updated_at;`,
+		receivedReview.FilmID, receivedReview.UserID, receivedReview.Score, receivedReview.Text).
+		Scan(&createdReview.ID, &createdReview.FilmID, &createdReview.UserID, &createdReview.Score,
+			&createdReview.Text, &createdReview.CreatedAt, &createdReview.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			if pqErr.Code == "23505" {
+				err = ErrNonUniqueReview
+			} else if pqErr.Constraint == "review_film_id_fkey" {
+				err = ErrFilmNotFound
+			}
+		}
+		return
+	}
+	err = refreshFilmUserRating(ctx, conn, createdReview.FilmID)
+	return
+}
+
+// Update provided fields of a user's Review of a film and refresh the film's user rating.
+// Must be called in a transaction, like Insert.
+func (r *ReviewRepoPostgres) Update(ctx *context.Context, filmID, userID int, fields map[string]interface{}) (err error) {
+	conn := r.store.Conn(*ctx)
+	err = lockReviewedFilm(ctx, conn, filmID)
+	if err != nil {
+		return
+	}
+
+	query := `UPDATE review SET updated_at = NOW(), `
+	values := make([]interface{}, 0)
+	idx := 1
+	for field, value := range fields {
+		query += field + "=$" + fmt.Sprint(idx) + ", "
+		values = append(values, value)
+		idx++
+	}
+	query = query[:len(query)-2] + " WHERE film_id=$" + fmt.Sprint(idx) + " AND user_id=$" + fmt.Sprint(idx+1)
+	values = append(values, filmID, userID)
+
+	res, err := conn.ExecContext(*ctx, query, values...)
+	if err != nil {
+		return
+	}
+	if cntRows, _ := res.RowsAffected(); cntRows == 0 {
+		err = ErrReviewNotFound
+		return
+	}
+	err = refreshFilmUserRating(ctx, conn, filmID)
+	return
+}
+
+// Delete a user's Review of a film and refresh the film's user rating.
+// Must be called in a transaction, like Insert.
+func (r *ReviewRepoPostgres) Delete(ctx *context.Context, filmID, userID int) (err error) {
+	conn := r.store.Conn(*ctx)
+	err = lockReviewedFilm(ctx, conn, filmID)
+	if err != nil {
+		return
+	}
+
+	res, err := conn.ExecContext(*ctx, `
+		DELETE FROM review
+		WHERE film_id = $1 AND user_id = $2;`, filmID, userID)
+	if err != nil {
+		return
+	}
+	if cntRows, _ := res.RowsAffected(); cntRows == 0 {
+		err = ErrReviewNotFound
+		return
+	}
+	err = refreshFilmUserRating(ctx, conn, filmID)
+	return
+}
+
+// Select a page of film's Reviews, newest first.
+func (r *ReviewRepoPostgres) SelectByFilmID(ctx *context.Context, filmID int, pagination *entity.PaginationParams) (reviews []*entity.Review, err error) {
+	var exists bool
+	err = r.store.DB.QueryRowContext(*ctx, `SELECT EXISTS (SELECT 1 FROM film WHERE id = $1 AND deleted_at IS NULL);`, filmID).Scan(&exists)
+	if err != nil {
+		return
+	} else if !exists {
+		err = ErrFilmNotFound
+		return
+	}
+
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		SELECT id, film_id, user_id, score, text, created_at, updated_at
+		FROM review
+		WHERE film_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx, filmID, pagination.Limit, pagination.Offset)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		review := &entity.Review{}
+		err = rows.Scan(&review.ID, &review.FilmID, &review.UserID, &review.Score, &review.Text, &review.CreatedAt, &review.UpdatedAt)
+		if err != nil {
+			return
+		}
+		reviews = append(reviews, review)
+	}
+	err = rows.Err()
+	return
+}
+
+// Select a user's Review of a film.
+func (r *ReviewRepoPostgres) SelectByFilmIDAndUserID(ctx *context.Context, filmID, userID int) (review *entity.Review, err error) {
+	review = &entity.Review{}
+	err = r.store.DB.QueryRowContext(*ctx, `
+		SELECT id, film_id, user_id, score, text, created_at, updated_at
+		FROM review
+		WHERE film_id = $1 AND user_id = $2;`, filmID, userID).
+		Scan(&review.ID, &review.FilmID, &review.UserID, &review.Score, &review.Text, &review.CreatedAt, &review.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = ErrReviewNotFound
+	}
+	return
+}
+
+// Lock a film that is not in trash, so that it can not be moved there until the transaction ends.
+func lockReviewedFilm(ctx *context.Context, conn postgres.Conn, filmID int) (err error) {
+	var id int
+	err = conn.QueryRowContext(*ctx, `
+		SELECT id FROM film WHERE id = $1 AND deleted_at IS NULL FOR SHARE;`, filmID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = ErrFilmNotFound
+	}
+	return
+}
+
+// Recalculate average user score and vote count of a film and bump its version, since they are part of the film.
+func refreshFilmUserRating(ctx *context.Context, conn postgres.Conn, filmID int) (err error) {
+	_, err = conn.ExecContext(*ctx, `
+		UPDATE film
+		SET user_rating_avg = COALESCE(agg.avg, 0), user_rating_count = agg.cnt, version = film.version + 1
+		FROM (SELECT AVG(score) AS avg, COUNT(*) AS cnt FROM review WHERE film_id = $1) agg
+		WHERE film.id = $1;`, filmID)
+	return
+}
@@ -0,0 +1,146 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+)
+
+type RevisionRepoPostgres struct {
+	store *postgres.Postgres
+}
+
+// Create new RevisionRepoPostgres.
+func NewRevisionRepoPostgres(store *postgres.Postgres) *RevisionRepoPostgres {
+	return &RevisionRepoPostgres{store}
+}
+
+// Insert a new Revision.
+func (r *RevisionRepoPostgres) Insert(ctx *context.Context, receivedRevision *entity.Revision) (createdRevision *entity.Revision, err error) {
+	diff, err := json.Marshal(receivedRevision.Diff)
+	if err != nil {
+		return
+	}
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
+		INSERT INTO revision (entity_type, entity_id, action, changed_by, diff, snapshot)
+		VALUES ($1, $2, $3, NULLIF($4, 0), $5, $6)
+		RETURNING id, changed_at;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	var changedBy int
+	if receivedRevision.ChangedBy != nil {
+		changedBy = *receivedRevision.ChangedBy
+	}
+	createdRevision = &entity.Revision{
+		EntityType: receivedRevision.EntityType,
+		EntityID:   receivedRevision.EntityID,
+		Action:     receivedRevision.Action,
+		ChangedBy:  receivedRevision.ChangedBy,
+		Diff:       receivedRevision.Diff,
+		Snapshot:   receivedRevision.Snapshot,
+	}
+	err = stmt.QueryRowContext(*ctx, receivedRevision.EntityType, receivedRevision.EntityID, receivedRevision.Action,
+		changedBy, diff, []byte(receivedRevision.Snapshot)).
+		Scan(&createdRevision.ID, &createdRevision.ChangedAt)
+	return
+}
+
+// Select a page of entity's Revisions, newest first.
+func (r *RevisionRepoPostgres) SelectByEntity(ctx *context.Context, entityType string, entityID int, pagination *entity.PaginationParams) (revisions []*entity.Revision, err error) {
+	stmt, err := r.store.Conn(*ctx).PrepareContext(*ctx, `
+		SELECT id, entity_type, entity_id, action, changed_by, changed_at, diff, snapshot
+		FROM revision
+		WHERE entity_type = $1 AND entity_id = $2
+		ORDER BY changed_at DESC, id DESC
+		LIMIT $3 OFFSET $4;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx, entityType, entityID, pagination.Limit, pagination.Offset)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var revision *entity.Revision
+		revision, err = scanRevision(rows)
+		if err != nil {
+			return
+		}
+		revisions = append(revisions, revision)
+	}
+	err = rows.Err()
+	return
+}
+
+// Select entity's Revision by id.
+func (r *RevisionRepoPostgres) SelectByID(ctx *context.Context, entityType string, entityID int, id int64) (revision *entity.Revision, err error) {
+	row := r.store.Conn(*ctx).QueryRowContext(*ctx, `
+		SELECT id, entity_type, entity_id, action, changed_by, changed_at, diff, snapshot
+		FROM revision
+		WHERE entity_type = $1 AND entity_id = $2 AND id = $3;`, entityType, entityID, id)
+	revision, err = scanRevision(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = ErrRevisionNotFound
+	}
+	return
+}
+
+// Select the last entity's Revision made not later than provided time.
+func (r *RevisionRepoPostgres) SelectLatestAt(ctx *context.Context, entityType string, entityID int, at time.Time) (revision *entity.Revision, err error) {
+	row := r.store.Conn(*ctx).QueryRowContext(*ctx, `
+		SELECT id, entity_type, entity_id, action, changed_by, changed_at, diff, snapshot
+		FROM revision
+		WHERE entity_type = $1 AND entity_id = $2 AND changed_at <= $3
+		ORDER BY changed_at DESC, id DESC
+		LIMIT 1;`, entityType, entityID, at)
+	revision, err = scanRevision(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = ErrRevisionNotFound
+	}
+	return
+}
+
+// Select the first recorded entity's Revision.
+func (r *RevisionRepoPostgres) SelectEarliest(ctx *context.Context, entityType string, entityID int) (revision *entity.Revision, err error) {
+	row := r.store.Conn(*ctx).QueryRowContext(*ctx, `
+		SELECT id, entity_type, entity_id, action, changed_by, changed_at, diff, snapshot
+		FROM revision
+		WHERE entity_type = $1 AND entity_id = $2
+		ORDER BY changed_at, id
+		LIMIT 1;`, entityType, entityID)
+	revision, err = scanRevision(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = ErrRevisionNotFound
+	}
+	return
+}
+
+// Scan a Revision from a query result row.
+func scanRevision(row interface{ Scan(dest ...any) error }) (revision *entity.Revision, err error) {
+	revision = &entity.Revision{}
+	var changedBy sql.NullInt64
+	var diff, snapshot []byte
+	err = row.Scan(&revision.ID, &revision.EntityType, &revision.EntityID, &revision.Action,
+		&changedBy, &revision.ChangedAt, &diff, &snapshot)
+	if err != nil {
+		return
+	}
+	if changedBy.Valid {
+		id := int(changedBy.Int64)
+		revision.ChangedBy = &id
+	}
+	revision.Snapshot = snapshot
+	err = json.Unmarshal(diff, &revision.Diff)
+	return
+}
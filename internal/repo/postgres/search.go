@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+)
+
+type SearchRepoPostgres struct {
+	store *postgres.Postgres
+}
+
+// Create new SearchRepoPostgres.
+func NewSearchRepoPostgres(store *postgres.Postgres) *SearchRepoPostgres {
+	return &SearchRepoPostgres{store}
+}
+
+// Select films and actors whose title or name in any locale is similar to the query, most similar first.
+// Every film and actor is suggested once, by its most similar title or name.
+// Word similarity operator uses trigram indexes, its threshold is lowered for the transaction only.
+func (r *SearchRepoPostgres) SelectSuggestions(ctx *context.Context, query string, limit int) (suggestions []*entity.Suggestion, err error) {
+	tx, err := r.store.DB.BeginTx(*ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(*ctx, `SELECT SET_CONFIG('pg_trgm.word_similarity_threshold', $1, true);`,
+		fmt.Sprint(entity.SuggestSimilarityThreshold))
+	if err != nil {
+		return
+	}
+	rows, err := tx.QueryContext(*ctx, `
+		(
+			SELECT DISTINCT ON (id) 'film' AS type, id, text, WORD_SIMILARITY($1, text) AS score
+			FROM (
+				SELECT id, title AS text
+				FROM film
+				WHERE deleted_at IS NULL AND $1 <% title
+				UNION ALL
+				SELECT f.id, ft.title
+				FROM film_translation ft
+				JOIN film f ON ft.film_id = f.id AND f.deleted_at IS NULL
+				WHERE $1 <% ft.title
+			) titles
+			ORDER BY id, score DESC
+		)
+		UNION ALL
+		(
+			SELECT DISTINCT ON (id) 'actor' AS type, id, text, WORD_SIMILARITY($1, text) AS score
+			FROM (
+				SELECT id, name AS text
+				FROM actor
+				WHERE deleted_at IS NULL AND $1 <% name
+				UNION ALL
+				SELECT a.id, t.name
+				FROM actor_translation t
+				JOIN actor a ON t.actor_id = a.id AND a.deleted_at IS NULL
+				WHERE $1 <% t.name
+			) names
+			ORDER BY id, score DESC
+		)
+		ORDER BY score DESC, text
+		LIMIT $2;`, query, limit)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		suggestion := &entity.Suggestion{}
+		if err = rows.Scan(&suggestion.Type, &suggestion.ID, &suggestion.Text, &suggestion.Score); err != nil {
+			return
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	err = rows.Err()
+	return
+}
@@ -0,0 +1,174 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+)
+
+type StatsRepoPostgres struct {
+	store *postgres.Postgres
+}
+
+// Create new StatsRepoPostgres.
+func NewStatsRepoPostgres(store *postgres.Postgres) *StatsRepoPostgres {
+	return &StatsRepoPostgres{store}
+}
+
+// Materialized views with aggregates of stats reports.
+var statsViews = [...]string{"stats_films_by_year", "stats_actor_films_by_year"}
+
+// Get the number of films released in every year of the window.
+func (r *StatsRepoPostgres) SelectFilmsPerYear(ctx *context.Context, params *entity.StatsParams) (report *entity.StatsReport, err error) {
+	report = &entity.StatsReport{Name: entity.StatsFilmsPerYear, Columns: []string{"year", "films_count"}}
+	err = r.selectReport(ctx, report, "stats_films_by_year", `
+		SELECT year, SUM(films_count)::INTEGER
+		FROM stats_films_by_year
+		WHERE ($1 = 0 OR year >= $1) AND ($2 = 0 OR year <= $2)
+		GROUP BY year
+		ORDER BY year;`, []interface{}{params.FromYear, params.ToYear}, func(rows *sql.Rows) (row []interface{}, err error) {
+		var year, cntFilms int
+		err = rows.Scan(&year, &cntFilms)
+		return []interface{}{year, cntFilms}, err
+	})
+	return
+}
+
+// Get the number of films released in the window with every rating.
+func (r *StatsRepoPostgres) SelectRatingDistribution(ctx *context.Context, params *entity.StatsParams) (report *entity.StatsReport, err error) {
+	report = &entity.StatsReport{Name: entity.StatsRatingDistribution, Columns: []string{"rating", "films_count"}}
+	err = r.selectReport(ctx, report, "stats_films_by_year", `
+		SELECT rating, SUM(films_count)::INTEGER
+		FROM stats_films_by_year
+		WHERE ($1 = 0 OR year >= $1) AND ($2 = 0 OR year <= $2)
+		GROUP BY rating
+		ORDER BY rating;`, []interface{}{params.FromYear, params.ToYear}, func(rows *sql.Rows) (row []interface{}, err error) {
+		var rating, cntFilms int
+		err = rows.Scan(&rating, &cntFilms)
+		return []interface{}{rating, cntFilms}, err
+	})
+	return
+}
+
+// Get a page of actors starring in the most films released in the window.
+func (r *StatsRepoPostgres) SelectProlificActors(ctx *context.Context, params *entity.StatsParams) (report *entity.StatsReport, err error) {
+	report = &entity.StatsReport{Name: entity.StatsProlificActors, Columns: []string{"actor_id", "name", "films_count"}}
+	err = r.selectReport(ctx, report, "stats_actor_films_by_year", `
+		SELECT a.id, a.name, SUM(s.films_count)::INTEGER AS films_count
+		FROM stats_actor_films_by_year s
+		JOIN actor a ON s.actor_id = a.id AND a.deleted_at IS NULL
+		WHERE ($1 = 0 OR s.year >= $1) AND ($2 = 0 OR s.year <= $2)
+		GROUP BY a.id
+		ORDER BY films_count DESC, a.name, a.id
+		LIMIT $3 OFFSET $4;`, []interface{}{params.FromYear, params.ToYear, params.Pagination.Limit, params.Pagination.Offset},
+		func(rows *sql.Rows) (row []interface{}, err error) {
+			var id, cntFilms int
+			var name string
+			err = rows.Scan(&id, &name, &cntFilms)
+			return []interface{}{id, name, cntFilms}, err
+		})
+	return
+}
+
+// Get the number of films released in the window and their average cast size.
+func (r *StatsRepoPostgres) SelectCastSize(ctx *context.Context, params *entity.StatsParams) (report *entity.StatsReport, err error) {
+	report = &entity.StatsReport{Name: entity.StatsCastSize, Columns: []string{"films_count", "avg_cast_size"}}
+	err = r.selectReport(ctx, report, "stats_films_by_year", `
+		SELECT COALESCE(SUM(films_count), 0)::INTEGER,
+			COALESCE(ROUND(SUM(cast_size_sum)::NUMERIC / NULLIF(SUM(films_count), 0), 2), 0)::FLOAT8
+		FROM stats_films_by_year
+		WHERE ($1 = 0 OR year >= $1) AND ($2 = 0 OR year <= $2);`, []interface{}{params.FromYear, params.ToYear},
+		func(rows *sql.Rows) (row []interface{}, err error) {
+			var cntFilms int
+			var avgCastSize float64
+			err = rows.Scan(&cntFilms, &avgCastSize)
+			return []interface{}{cntFilms, avgCastSize}, err
+		})
+	return
+}
+
+// Get the number of actors born in every decade, actors are counted live as the query is cheap.
+func (r *StatsRepoPostgres) SelectActorsByBirthDecade(ctx *context.Context, params *entity.StatsParams) (report *entity.StatsReport, err error) {
+	report = &entity.StatsReport{Name: entity.StatsActorsByBirthDecade, Columns: []string{"decade", "actors_count"}}
+	err = r.selectReport(ctx, report, "", `
+		SELECT EXTRACT(YEAR FROM birth_date)::INTEGER / 10 * 10 AS decade, COUNT(*)::INTEGER
+		FROM actor
+		WHERE deleted_at IS NULL
+			AND ($1 = 0 OR EXTRACT(YEAR FROM birth_date) >= $1) AND ($2 = 0 OR EXTRACT(YEAR FROM birth_date) <= $2)
+		GROUP BY decade
+		ORDER BY decade;`, []interface{}{params.FromYear, params.ToYear}, func(rows *sql.Rows) (row []interface{}, err error) {
+		var decade, cntActors int
+		err = rows.Scan(&decade, &cntActors)
+		return []interface{}{decade, cntActors}, err
+	})
+	return
+}
+
+// Get a page of films released in the window that have no cast, oldest first. Films are listed live.
+func (r *StatsRepoPostgres) SelectFilmsWithoutCast(ctx *context.Context, params *entity.StatsParams) (report *entity.StatsReport, err error) {
+	report = &entity.StatsReport{Name: entity.StatsFilmsWithoutCast, Columns: []string{"film_id", "title", "release_date"}}
+	err = r.selectReport(ctx, report, "", `
+		SELECT f.id, f.title, f.release_date, f.release_date_precision
+		FROM film f
+		WHERE f.deleted_at IS NULL
+			AND ($1 = 0 OR EXTRACT(YEAR FROM f.release_date) >= $1) AND ($2 = 0 OR EXTRACT(YEAR FROM f.release_date) <= $2)
+			AND NOT EXISTS (
+				SELECT 1
+				FROM films_actors fa
+				JOIN actor a ON fa.actor_id = a.id AND a.deleted_at IS NULL
+				WHERE fa.film_id = f.id
+			)
+		ORDER BY f.release_date, f.id
+		LIMIT $3 OFFSET $4;`, []interface{}{params.FromYear, params.ToYear, params.Pagination.Limit, params.Pagination.Offset},
+		func(rows *sql.Rows) (row []interface{}, err error) {
+			var id int
+			var title string
+			var releaseDate entity.Date
+			err = rows.Scan(&id, &title, &releaseDate, &releaseDate.Precision)
+			return []interface{}{id, title, releaseDate}, err
+		})
+	return
+}
+
+// Refresh all materialized views of stats reports, they stay readable during the refresh.
+func (r *StatsRepoPostgres) Refresh(ctx *context.Context) (err error) {
+	for _, view := range statsViews {
+		if _, err = r.store.DB.ExecContext(*ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY `+view+`;`); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Fill report rows by query, scanning every row with scan. Refresh time is read from view if it is not empty.
+func (r *StatsRepoPostgres) selectReport(ctx *context.Context, report *entity.StatsReport, view string, query string, args []interface{},
+	scan func(rows *sql.Rows) (row []interface{}, err error)) (err error) {
+	if view != "" {
+		var refreshedAt sql.NullTime
+		err = r.store.DB.QueryRowContext(*ctx, `SELECT MAX(refreshed_at) FROM `+view+`;`).Scan(&refreshedAt)
+		if err != nil {
+			return
+		}
+		if refreshedAt.Valid {
+			report.RefreshedAt = &refreshedAt.Time
+		}
+	}
+
+	rows, err := r.store.DB.QueryContext(*ctx, query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	report.Rows = [][]interface{}{}
+	for rows.Next() {
+		row, err := scan(rows)
+		if err != nil {
+			return err
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	err = rows.Err()
+	return
+}
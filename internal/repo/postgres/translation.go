@@ -0,0 +1,218 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+	"github.com/lib/pq"
+)
+
+type TranslationRepoPostgres struct {
+	store *postgres.Postgres
+}
+
+// Create new TranslationRepoPostgres.
+func NewTranslationRepoPostgres(store *postgres.Postgres) *TranslationRepoPostgres {
+	return &TranslationRepoPostgres{store}
+}
+
+// Get all translations of a Film, ordered by locale.
+func (r *TranslationRepoPostgres) SelectByFilmID(ctx *context.Context, filmID int) (translations []*entity.FilmTranslation, err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		SELECT locale, title, description
+		FROM film_translation
+		WHERE film_id = $1
+		ORDER BY locale;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx, filmID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		translation := &entity.FilmTranslation{}
+		if err = rows.Scan(&translation.Locale, &translation.Title, &translation.Description); err != nil {
+			return
+		}
+		translations = append(translations, translation)
+	}
+	err = rows.Err()
+	return
+}
+
+// Get translations of Films by their ids to the first of provided locales each Film is translated to.
+// Films without translations to any of the locales are absent in the result.
+func (r *TranslationRepoPostgres) SelectPreferredByFilmsIDs(ctx *context.Context, filmsIDs []int, locales []string) (translations map[int]*entity.FilmTranslation, err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		SELECT DISTINCT ON (film_id) film_id, locale, title, description
+		FROM film_translation
+		WHERE film_id = ANY($1) AND locale = ANY($2::TEXT[])
+		ORDER BY film_id, ARRAY_POSITION($2::TEXT[], locale::TEXT);`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx, pq.Array(filmsIDs), pq.Array(locales))
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	translations = make(map[int]*entity.FilmTranslation)
+	for rows.Next() {
+		var filmID int
+		translation := &entity.FilmTranslation{}
+		if err = rows.Scan(&filmID, &translation.Locale, &translation.Title, &translation.Description); err != nil {
+			return
+		}
+		translations[filmID] = translation
+	}
+	err = rows.Err()
+	return
+}
+
+// Insert or replace a translation of a Film. The Film's version is bumped, as its representation changes.
+// Must be called in a transaction, so that the translation and the version change together.
+func (r *TranslationRepoPostgres) UpsertFilmTranslation(ctx *context.Context, filmID int, translation *entity.FilmTranslation) (err error) {
+	conn := r.store.Conn(*ctx)
+	if err = bumpVersion(ctx, conn, "film", filmID, ErrFilmNotFound); err != nil {
+		return
+	}
+	_, err = conn.ExecContext(*ctx, `
+		INSERT INTO film_translation (film_id, locale, title, description)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (film_id, locale) DO UPDATE
+		SET title = EXCLUDED.title, description = EXCLUDED.description;`,
+		filmID, translation.Locale, translation.Title, translation.Description)
+	return
+}
+
+// Delete a translation of a Film. The Film's version is bumped, as its representation changes.
+// Must be called in a transaction, so that the translation and the version change together.
+func (r *TranslationRepoPostgres) DeleteFilmTranslation(ctx *context.Context, filmID int, locale string) (err error) {
+	conn := r.store.Conn(*ctx)
+	if err = bumpVersion(ctx, conn, "film", filmID, ErrFilmNotFound); err != nil {
+		return
+	}
+	res, err := conn.ExecContext(*ctx, `
+		DELETE FROM film_translation
+		WHERE film_id = $1 AND locale = $2;`, filmID, locale)
+	if err != nil {
+		return
+	}
+	if cntRows, _ := res.RowsAffected(); cntRows == 0 {
+		err = ErrTranslationNotFound
+	}
+	return
+}
+
+// Get all translations of an Actor, ordered by locale.
+func (r *TranslationRepoPostgres) SelectByActorID(ctx *context.Context, actorID int) (translations []*entity.ActorTranslation, err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		SELECT locale, name
+		FROM actor_translation
+		WHERE actor_id = $1
+		ORDER BY locale;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx, actorID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		translation := &entity.ActorTranslation{}
+		if err = rows.Scan(&translation.Locale, &translation.Name); err != nil {
+			return
+		}
+		translations = append(translations, translation)
+	}
+	err = rows.Err()
+	return
+}
+
+// Get translations of Actors by their ids to the first of provided locales each Actor is translated to.
+// Actors without translations to any of the locales are absent in the result.
+func (r *TranslationRepoPostgres) SelectPreferredByActorsIDs(ctx *context.Context, actorsIDs []int, locales []string) (translations map[int]*entity.ActorTranslation, err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		SELECT DISTINCT ON (actor_id) actor_id, locale, name
+		FROM actor_translation
+		WHERE actor_id = ANY($1) AND locale = ANY($2::TEXT[])
+		ORDER BY actor_id, ARRAY_POSITION($2::TEXT[], locale::TEXT);`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx, pq.Array(actorsIDs), pq.Array(locales))
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	translations = make(map[int]*entity.ActorTranslation)
+	for rows.Next() {
+		var actorID int
+		translation := &entity.ActorTranslation{}
+		if err = rows.Scan(&actorID, &translation.Locale, &translation.Name); err != nil {
+			return
+		}
+		translations[actorID] = translation
+	}
+	err = rows.Err()
+	return
+}
+
+// Insert or replace a translation of an Actor. The Actor's version is bumped, as its representation changes.
+// Must be called in a transaction, so that the translation and the version change together.
+func (r *TranslationRepoPostgres) UpsertActorTranslation(ctx *context.Context, actorID int, translation *entity.ActorTranslation) (err error) {
+	conn := r.store.Conn(*ctx)
+	if err = bumpVersion(ctx, conn, "actor", actorID, ErrActorNotFound); err != nil {
+		return
+	}
+	_, err = conn.ExecContext(*ctx, `
+		INSERT INTO actor_translation (actor_id, locale, name)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (actor_id, locale) DO UPDATE
+		SET name = EXCLUDED.name;`,
+		actorID, translation.Locale, translation.Name)
+	return
+}
+
+// Delete a translation of an Actor. The Actor's version is bumped, as its representation changes.
+// Must be called in a transaction, so that the translation and the version change together.
+func (r *TranslationRepoPostgres) DeleteActorTranslation(ctx *context.Context, actorID int, locale string) (err error) {
+	conn := r.store.Conn(*ctx)
+	if err = bumpVersion(ctx, conn, "actor", actorID, ErrActorNotFound); err != nil {
+		return
+	}
+	res, err := conn.ExecContext(*ctx, `
+		DELETE FROM actor_translation
+		WHERE actor_id = $1 AND locale = $2;`, actorID, locale)
+	if err != nil {
+		return
+	}
+	if cntRows, _ := res.RowsAffected(); cntRows == 0 {
+		err = ErrTranslationNotFound
+	}
+	return
+}
+
+// Bump version of a not deleted film or actor, errNotFound is returned if there is no such row.
+func bumpVersion(ctx *context.Context, conn postgres.Conn, table string, id int, errNotFound error) (err error) {
+	res, err := conn.ExecContext(*ctx, `UPDATE `+table+` SET version = version + 1 WHERE id = $1 AND deleted_at IS NULL;`, id)
+	if err != nil {
+		return
+	}
+	if cntRows, _ := res.RowsAffected(); cntRows == 0 {
+		err = errNotFound
+	}
+	return
+}
@@ -0,0 +1,219 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+)
+
+type WatchlistRepoPostgres struct {
+	store *postgres.Postgres
+}
+
+// Create new WatchlistRepoPostgres.
+func NewWatchlistRepoPostgres(store *postgres.Postgres) *WatchlistRepoPostgres {
+	return &WatchlistRepoPostgres{store}
+}
+
+// Add a not deleted film to user's watchlist, adding an already present film does nothing.
+func (r *WatchlistRepoPostgres) InsertToWatchlist(ctx *context.Context, userID, filmID int) (entry *entity.WatchlistEntry, err error) {
+	entry = &entity.WatchlistEntry{}
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		INSERT INTO watchlist (user_id, film_id)
+		SELECT $1, id FROM film WHERE id = $2 AND deleted_at IS NULL FOR SHARE
+		ON CONFLICT (user_id, film_id) DO UPDATE SET added_at = watchlist.added_at
+		RETURNING film_id, added_at;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(*ctx, userID, filmID).Scan(&entry.FilmID, &entry.AddedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = ErrFilmNotFound
+	}
+	return
+}
+
+// Remove a film from user's watchlist.
+func (r *WatchlistRepoPostgres) DeleteFromWatchlist(ctx *context.Context, userID, filmID int) (err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		DELETE FROM watchlist
+		WHERE user_id = $1 AND film_id = $2;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(*ctx, userID, filmID)
+	if err != nil {
+		return
+	}
+	if cntRows, _ := res.RowsAffected(); cntRows == 0 {
+		err = ErrWatchlistEntryNotFound
+	}
+	return
+}
+
+// Select user's watchlist, recently added first.
+func (r *WatchlistRepoPostgres) SelectWatchlist(ctx *context.Context, userID int) (entries []*entity.WatchlistEntry, err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		SELECT film_id, added_at
+		FROM watchlist
+		WHERE user_id = $1
+		ORDER BY added_at DESC;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx, userID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		entry := &entity.WatchlistEntry{}
+		if err = rows.Scan(&entry.FilmID, &entry.AddedAt); err != nil {
+			return
+		}
+		entries = append(entries, entry)
+	}
+	err = rows.Err()
+	return
+}
+
+// Add a not deleted film to user's watched list.
+func (r *WatchlistRepoPostgres) InsertToWatched(ctx *context.Context, userID int, receivedEntry *entity.WatchedEntry) (entry *entity.WatchedEntry, err error) {
+	entry = &entity.WatchedEntry{}
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		INSERT INTO watched (user_id, film_id, watched_on)
+		SELECT $1, id, $3 FROM film WHERE id = $2 AND deleted_at IS NULL FOR SHARE
+		ON CONFLICT (user_id, film_id, watched_on) DO UPDATE SET watched_on = watched.watched_on
+		RETURNING film_id, watched_on;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(*ctx, userID, receivedEntry.FilmID, receivedEntry.WatchedOn).Scan(&entry.FilmID, &entry.WatchedOn)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = ErrFilmNotFound
+	}
+	return
+}
+
+// Remove a film from user's watched list. If watchedOn is empty, all views of the film are removed.
+func (r *WatchlistRepoPostgres) DeleteFromWatched(ctx *context.Context, userID, filmID int, watchedOn string) (err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		DELETE FROM watched
+		WHERE user_id = $1 AND film_id = $2 AND ($3 = '' OR watched_on = NULLIF($3, '')::DATE);`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(*ctx, userID, filmID, watchedOn)
+	if err != nil {
+		return
+	}
+	if cntRows, _ := res.RowsAffected(); cntRows == 0 {
+		err = ErrWatchedEntryNotFound
+	}
+	return
+}
+
+// Select user's watched list, recently watched first.
+func (r *WatchlistRepoPostgres) SelectWatched(ctx *context.Context, userID int) (entries []*entity.WatchedEntry, err error) {
+	stmt, err := r.store.DB.PrepareContext(*ctx, `
+		SELECT film_id, watched_on
+		FROM watched
+		WHERE user_id = $1
+		ORDER BY watched_on DESC, film_id;`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(*ctx, userID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		entry := &entity.WatchedEntry{}
+		if err = rows.Scan(&entry.FilmID, &entry.WatchedOn); err != nil {
+			return
+		}
+		entries = append(entries, entry)
+	}
+	err = rows.Err()
+	return
+}
+
+// Get user's watching stats, films in trash are not counted.
+func (r *WatchlistRepoPostgres) SelectStats(ctx *context.Context, userID int) (stats *entity.UserStats, err error) {
+	stats = &entity.UserStats{
+		WatchedPerYear:  []*entity.WatchedPerYear{},
+		FavouriteActors: []*entity.FavouriteActor{},
+	}
+	err = r.store.DB.QueryRowContext(*ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM watchlist w JOIN film f ON f.id = w.film_id
+				WHERE w.user_id = $1 AND f.deleted_at IS NULL),
+			(SELECT COUNT(DISTINCT w.film_id) FROM watched w JOIN film f ON f.id = w.film_id
+				WHERE w.user_id = $1 AND f.deleted_at IS NULL);`, userID).
+		Scan(&stats.WatchlistCount, &stats.WatchedCount)
+	if err != nil {
+		return
+	}
+
+	rows, err := r.store.DB.QueryContext(*ctx, `
+		SELECT EXTRACT(YEAR FROM w.watched_on)::INTEGER AS year, COUNT(DISTINCT w.film_id)
+		FROM watched w
+		JOIN film f ON f.id = w.film_id
+		WHERE w.user_id = $1 AND f.deleted_at IS NULL
+		GROUP BY year
+		ORDER BY year;`, userID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		perYear := &entity.WatchedPerYear{}
+		if err = rows.Scan(&perYear.Year, &perYear.Count); err != nil {
+			return
+		}
+		stats.WatchedPerYear = append(stats.WatchedPerYear, perYear)
+	}
+	if err = rows.Err(); err != nil {
+		return
+	}
+
+	actorRows, err := r.store.DB.QueryContext(*ctx, `
+		SELECT a.id, a.name, COUNT(DISTINCT w.film_id) AS films_count
+		FROM watched w
+		JOIN film f ON f.id = w.film_id
+		JOIN films_actors fa ON fa.film_id = w.film_id
+		JOIN actor a ON a.id = fa.actor_id
+		WHERE w.user_id = $1 AND f.deleted_at IS NULL AND a.deleted_at IS NULL
+		GROUP BY a.id
+		ORDER BY films_count DESC, a.name
+		LIMIT $2;`, userID, entity.FavouriteActorsLimit)
+	if err != nil {
+		return
+	}
+	defer actorRows.Close()
+	for actorRows.Next() {
+		actor := &entity.FavouriteActor{}
+		if err = actorRows.Scan(&actor.ActorID, &actor.Name, &actor.FilmsCount); err != nil {
+			return
+		}
+		stats.FavouriteActors = append(stats.FavouriteActors, actor)
+	}
+	err = actorRows.Err()
+	return
+}
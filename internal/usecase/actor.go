@@ -2,44 +2,84 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
 
 	"github.com/itmosha/vk-internship-2024/internal/entity"
+	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
 )
 
 // ActorRepo interface.
 type ActorRepoInterface interface {
+	NewTransaction(ctx *context.Context) (tx *sql.Tx, err error)
 	Insert(ctx *context.Context, receivedActor *entity.Actor) (createdActor *entity.Actor, err error)
-	Update(ctx *context.Context, id int, fields map[string]interface{}) (err error)
-	Delete(ctx *context.Context, id int) (err error)
+	Update(ctx *context.Context, id int, fields map[string]interface{}, versions []int) (err error)
+	Delete(ctx *context.Context, id int, versions []int) (err error)
+	Lock(ctx *context.Context, id int) (err error)
+	SelectByID(ctx *context.Context, id int) (actor *entity.Actor, err error)
+	SelectByIDs(ctx *context.Context, ids []int) (actors []*entity.ActorWithFilms, err error)
+	SelectListState(ctx *context.Context) (state *entity.ListState, err error)
 	GetAllWithFilms(ctx *context.Context) (actors []*entity.ActorWithFilms, err error)
+	SelectDeleted(ctx *context.Context) (actors []*entity.ActorWithFilms, err error)
+	Restore(ctx *context.Context, id int) (err error)
+	Purge(ctx *context.Context, retentionPeriod time.Duration) (cntPurged int64, err error)
 }
 
 type ActorUsecase struct {
-	actorRepo     ActorRepoInterface
-	filmActorRepo FilmsActorsRepoInterface
+	actorRepo       ActorRepoInterface
+	filmActorRepo   FilmsActorsRepoInterface
+	revisionRepo    RevisionRepoInterface
+	translationRepo TranslationRepoInterface
 }
 
 // Create new ActorUsecase.
-func NewActorUsecase(actorRepo ActorRepoInterface, filmActorRepo FilmsActorsRepoInterface) *ActorUsecase {
+func NewActorUsecase(actorRepo ActorRepoInterface, filmActorRepo FilmsActorsRepoInterface, revisionRepo RevisionRepoInterface, translationRepo TranslationRepoInterface) *ActorUsecase {
 	return &ActorUsecase{
-		actorRepo:     actorRepo,
-		filmActorRepo: filmActorRepo,
+		actorRepo:       actorRepo,
+		filmActorRepo:   filmActorRepo,
+		revisionRepo:    revisionRepo,
+		translationRepo: translationRepo,
 	}
 }
 
 // Create a new actor.
-func (uc *ActorUsecase) Create(ctx *context.Context, body *entity.ActorCreateBody) (actor *entity.Actor, err error) {
+func (uc *ActorUsecase) Create(ctx *context.Context, body *entity.ActorCreateBody, userID int) (actor *entity.Actor, err error) {
+	birthDate, err := entity.ParseFullDate(body.BirthDate)
+	if err != nil {
+		return
+	}
+	deathDate, err := parseOptionalDate(body.DeathDate)
+	if err != nil {
+		return
+	}
 	actorToCreate := &entity.Actor{
-		Name:      body.Name,
-		Gender:    *body.Gender,
-		BirthDate: body.BirthDate,
+		Name:        body.Name,
+		Gender:      *body.Gender,
+		BirthDate:   birthDate,
+		DeathDate:   deathDate,
+		Biography:   body.Biography,
+		Birthplace:  body.Birthplace,
+		Nationality: body.Nationality,
+		Aliases:     body.Aliases,
+		ExternalIDs: body.ExternalIDs,
 	}
+	tx, ctx, err := beginTransaction(ctx, uc.actorRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
 	actor, err = uc.actorRepo.Insert(ctx, actorToCreate)
+	if err != nil {
+		return
+	}
+	err = recordRevision(ctx, uc.revisionRepo, entity.RevisionEntityActor, actor.ID, entity.RevisionActionCreate, userID, nil, actor)
 	return
 }
 
-// Update an actor by id.
-func (uc *ActorUsecase) Update(ctx *context.Context, id int, body *entity.ActorUpdateBody) (err error) {
+// Update an actor by id. If versions are provided, the actor is updated only if its current version is one of them.
+func (uc *ActorUsecase) Update(ctx *context.Context, id int, body *entity.ActorUpdateBody, userID int, versions []int) (err error) {
 	fields := map[string]interface{}{}
 	if len(body.Name) > 0 {
 		fields["name"] = body.Name
@@ -48,34 +88,255 @@ func (uc *ActorUsecase) Update(ctx *context.Context, id int, body *entity.ActorU
 		fields["gender"] = *body.Gender
 	}
 	if len(body.BirthDate) > 0 {
-		fields["birth_date"] = body.BirthDate
+		fields["birth_date"], err = entity.ParseFullDate(body.BirthDate)
+		if err != nil {
+			return
+		}
+	}
+	if len(body.DeathDate) > 0 {
+		fields["death_date"], err = entity.ParseFullDate(body.DeathDate)
+		if err != nil {
+			return
+		}
+	}
+	if len(body.Biography) > 0 {
+		fields["biography"] = body.Biography
+	}
+	if len(body.Birthplace) > 0 {
+		fields["birthplace"] = body.Birthplace
+	}
+	if len(body.Nationality) > 0 {
+		fields["nationality"] = body.Nationality
+	}
+	if body.Aliases != nil {
+		fields["aliases"] = body.Aliases
+	}
+	if body.ExternalIDs != nil {
+		fields["external_ids"] = entity.ExternalIDs(body.ExternalIDs)
 	}
 	if len(fields) == 0 {
 		return
 	}
-	err = uc.actorRepo.Update(ctx, id, fields)
+	err = uc.update(ctx, id, fields, entity.RevisionActionUpdate, userID, versions)
 	return
 }
 
-// Replace an actor by id.
-func (uc *ActorUsecase) Replace(ctx *context.Context, id int, body *entity.ActorReplaceBody) (err error) {
+// Replace an actor by id. If versions are provided, the actor is replaced only if its current version is one of them.
+func (uc *ActorUsecase) Replace(ctx *context.Context, id int, body *entity.ActorReplaceBody, userID int, versions []int) (err error) {
+	birthDate, err := entity.ParseFullDate(body.BirthDate)
+	if err != nil {
+		return
+	}
+	deathDate, err := parseOptionalDate(body.DeathDate)
+	if err != nil {
+		return
+	}
 	fields := map[string]interface{}{
-		"name":       body.Name,
-		"gender":     *body.Gender,
-		"birth_date": body.BirthDate,
+		"name":         body.Name,
+		"gender":       *body.Gender,
+		"birth_date":   birthDate,
+		"death_date":   deathDate,
+		"biography":    body.Biography,
+		"birthplace":   body.Birthplace,
+		"nationality":  body.Nationality,
+		"aliases":      body.Aliases,
+		"external_ids": entity.ExternalIDs(body.ExternalIDs),
 	}
-	err = uc.actorRepo.Update(ctx, id, fields)
+	err = uc.update(ctx, id, fields, entity.RevisionActionUpdate, userID, versions)
 	return
 }
 
-// Delete an actor by id.
-func (uc *ActorUsecase) Delete(ctx *context.Context, id int) (err error) {
-	err = uc.actorRepo.Delete(ctx, id)
+// Move an actor to trash by id. If versions are provided, the actor is deleted only if its current version is one of them.
+func (uc *ActorUsecase) Delete(ctx *context.Context, id int, userID int, versions []int) (err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.actorRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	err = uc.actorRepo.Lock(ctx, id)
+	if err != nil {
+		return
+	}
+	before, err := uc.actorRepo.SelectByID(ctx, id)
+	if err != nil {
+		return
+	}
+	err = uc.actorRepo.Delete(ctx, id, versions)
+	if err != nil {
+		return
+	}
+	err = recordRevision(ctx, uc.revisionRepo, entity.RevisionEntityActor, id, entity.RevisionActionDelete, userID, before, nil)
+	return
+}
+
+// Get an actor by id with name translated to the most preferred of locales available, if any.
+func (uc *ActorUsecase) GetByID(ctx *context.Context, id int, locales []string) (actor *entity.Actor, err error) {
+	actor, err = uc.actorRepo.SelectByID(ctx, id)
+	if err != nil {
+		return
+	}
+	err = translateActors(ctx, uc.translationRepo, []*entity.Actor{actor}, locales)
+	return
+}
+
+// Get actors with their films by ids, names are translated to the most preferred of locales available.
+// Missing actors are skipped, the order of actors is not specified.
+func (uc *ActorUsecase) GetByIDs(ctx *context.Context, ids []int, locales []string) (actors []*entity.ActorWithFilms, err error) {
+	actors, err = uc.actorRepo.SelectByIDs(ctx, ids)
+	if err != nil {
+		return
+	}
+	translated := make([]*entity.Actor, len(actors))
+	for i, actor := range actors {
+		translated[i] = &actor.Actor
+	}
+	err = translateActors(ctx, uc.translationRepo, translated, locales)
 	return
 }
 
-// Get all actors.
-func (uc *ActorUsecase) GetAllWithFilms(ctx *context.Context) (actors []*entity.ActorWithFilms, err error) {
+// Get all actors with names translated to the most preferred of locales available.
+func (uc *ActorUsecase) GetAllWithFilms(ctx *context.Context, locales []string) (actors []*entity.ActorWithFilms, err error) {
 	actors, err = uc.actorRepo.GetAllWithFilms(ctx)
+	if err != nil {
+		return
+	}
+	translated := make([]*entity.Actor, len(actors))
+	for i, actor := range actors {
+		translated[i] = &actor.Actor
+	}
+	err = translateActors(ctx, uc.translationRepo, translated, locales)
+	return
+}
+
+// Get state of lists of actors, it changes whenever any listed actor may change.
+func (uc *ActorUsecase) GetListState(ctx *context.Context) (state *entity.ListState, err error) {
+	state, err = uc.actorRepo.SelectListState(ctx)
+	return
+}
+
+// Get a page of actor's change history, newest first.
+func (uc *ActorUsecase) GetHistory(ctx *context.Context, id int, pagination *entity.PaginationParams) (revisions []*entity.Revision, err error) {
+	revisions, err = uc.revisionRepo.SelectByEntity(ctx, entity.RevisionEntityActor, id, pagination)
+	return
+}
+
+// Revert an actor to the state it had in provided revision. Actors in trash are restored.
+func (uc *ActorUsecase) Revert(ctx *context.Context, id int, revisionID int64, userID int) (actor *entity.Actor, err error) {
+	revision, err := uc.revisionRepo.SelectByID(ctx, entity.RevisionEntityActor, id, revisionID)
+	if err != nil {
+		return
+	}
+	state := &entity.Actor{}
+	err = json.Unmarshal(revision.Snapshot, state)
+	if err != nil {
+		return
+	}
+	fields := map[string]interface{}{
+		"name":         state.Name,
+		"gender":       state.Gender,
+		"birth_date":   state.BirthDate,
+		"death_date":   state.DeathDate,
+		"biography":    state.Biography,
+		"birthplace":   state.Birthplace,
+		"nationality":  state.Nationality,
+		"aliases":      state.Aliases,
+		"external_ids": state.ExternalIDs,
+	}
+	err = uc.update(ctx, id, fields, entity.RevisionActionRevert, userID, nil)
+	if err != nil {
+		return
+	}
+	actor, err = uc.actorRepo.SelectByID(ctx, id)
+	return
+}
+
+// Get all actors in trash.
+func (uc *ActorUsecase) GetTrash(ctx *context.Context) (actors []*entity.ActorWithFilms, err error) {
+	actors, err = uc.actorRepo.SelectDeleted(ctx)
+	return
+}
+
+// Restore an actor from trash by id.
+func (uc *ActorUsecase) Restore(ctx *context.Context, id int, userID int) (err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.actorRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	err = uc.actorRepo.Restore(ctx, id)
+	if err != nil {
+		return
+	}
+	after, err := uc.actorRepo.SelectByID(ctx, id)
+	if err != nil {
+		return
+	}
+	err = recordRevision(ctx, uc.revisionRepo, entity.RevisionEntityActor, id, entity.RevisionActionRestore, userID, nil, after)
+	return
+}
+
+// Permanently delete actors that are in trash for longer than retention period.
+func (uc *ActorUsecase) PurgeTrash(ctx *context.Context, retentionPeriod time.Duration) (cntPurged int64, err error) {
+	cntPurged, err = uc.actorRepo.Purge(ctx, retentionPeriod)
 	return
 }
+
+// Update fields of an actor and record the change. Reverted actors in trash are restored first.
+func (uc *ActorUsecase) update(ctx *context.Context, id int, fields map[string]interface{}, action string, userID int, versions []int) (err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.actorRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	err = uc.actorRepo.Lock(ctx, id)
+	if err != nil {
+		return
+	}
+	before, err := uc.actorRepo.SelectByID(ctx, id)
+	if errors.Is(err, repo.ErrActorNotFound) && action == entity.RevisionActionRevert {
+		before = nil
+		err = uc.actorRepo.Restore(ctx, id)
+	}
+	if err != nil {
+		return
+	}
+	if before != nil {
+		err = validateActorLifespan(before, fields)
+		if err != nil {
+			return
+		}
+	}
+	err = uc.actorRepo.Update(ctx, id, fields, versions)
+	if err != nil {
+		return
+	}
+	after, err := uc.actorRepo.SelectByID(ctx, id)
+	if err != nil {
+		return
+	}
+	err = recordRevision(ctx, uc.revisionRepo, entity.RevisionEntityActor, id, action, userID, before, after)
+	return
+}
+
+// Check that an actor would not die before being born after updating its fields.
+func validateActorLifespan(actor *entity.Actor, fields map[string]interface{}) (err error) {
+	birthDate, deathDate := actor.BirthDate, actor.DeathDate
+	if value, ok := fields["birth_date"].(entity.Date); ok {
+		birthDate = value
+	}
+	if value, ok := fields["death_date"].(entity.Date); ok {
+		deathDate = value
+	}
+	return entity.ValidateActorLifespan(birthDate, deathDate)
+}
+
+// Parse a date that may be omitted, empty value results in a zero date stored as NULL.
+func parseOptionalDate(value string) (date entity.Date, err error) {
+	if value == "" {
+		return
+	}
+	return entity.ParseFullDate(value)
+}
@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+)
+
+// Read cache interface.
+type ReadCacheInterface interface {
+	Stats() (stats *entity.CacheStats)
+}
+
+type CacheUsecase struct {
+	readCache ReadCacheInterface
+}
+
+// Create new CacheUsecase.
+func NewCacheUsecase(readCache ReadCacheInterface) *CacheUsecase {
+	return &CacheUsecase{readCache: readCache}
+}
+
+// Get usage counters of the read cache.
+func (uc *CacheUsecase) GetStats(ctx *context.Context) (stats *entity.CacheStats, err error) {
+	stats = uc.readCache.Stats()
+	return
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
+)
+
+// CollectionRepo interface.
+type CollectionRepoInterface interface {
+	Insert(ctx *context.Context, receivedCollection *entity.Collection) (createdCollection *entity.Collection, err error)
+	Update(ctx *context.Context, id int, fields map[string]interface{}, filmsIDs []int) (err error)
+	Delete(ctx *context.Context, id int) (err error)
+	SelectAll(ctx *context.Context, withDrafts bool) (collections []*entity.Collection, err error)
+	SelectBySlug(ctx *context.Context, slug string) (collection *entity.Collection, err error)
+}
+
+type CollectionUsecase struct {
+	collectionRepo CollectionRepoInterface
+	filmRepo       FilmRepoInterface
+}
+
+// Create new CollectionUsecase.
+func NewCollectionUsecase(collectionRepo CollectionRepoInterface, filmRepo FilmRepoInterface) *CollectionUsecase {
+	return &CollectionUsecase{
+		collectionRepo: collectionRepo,
+		filmRepo:       filmRepo,
+	}
+}
+
+// Create a new collection.
+func (uc *CollectionUsecase) Create(ctx *context.Context, body *entity.CollectionCreateBody) (collection *entity.Collection, err error) {
+	collectionToCreate := &entity.Collection{
+		Slug:        body.Slug,
+		Title:       body.Title,
+		Description: body.Description,
+		Status:      body.Status,
+		FilmsIDs:    body.FilmsIDs,
+	}
+	if collectionToCreate.FilmsIDs == nil {
+		collectionToCreate.FilmsIDs = []int{}
+	}
+	collection, err = uc.collectionRepo.Insert(ctx, collectionToCreate)
+	return
+}
+
+// Update a collection by id.
+func (uc *CollectionUsecase) Update(ctx *context.Context, id int, body *entity.CollectionUpdateBody) (err error) {
+	fields := map[string]interface{}{}
+	if len(body.Slug) > 0 {
+		fields["slug"] = body.Slug
+	}
+	if len(body.Title) > 0 {
+		fields["title"] = body.Title
+	}
+	if len(body.Description) > 0 {
+		fields["description"] = body.Description
+	}
+	if len(body.Status) > 0 {
+		fields["status"] = body.Status
+	}
+	if len(fields) == 0 && body.FilmsIDs == nil {
+		return
+	}
+	err = uc.collectionRepo.Update(ctx, id, fields, body.FilmsIDs)
+	return
+}
+
+// Replace a collection by id.
+func (uc *CollectionUsecase) Replace(ctx *context.Context, id int, body *entity.CollectionReplaceBody) (err error) {
+	fields := map[string]interface{}{
+		"slug":        body.Slug,
+		"title":       body.Title,
+		"description": body.Description,
+		"status":      body.Status,
+	}
+	filmsIDs := body.FilmsIDs
+	if filmsIDs == nil {
+		filmsIDs = []int{}
+	}
+	err = uc.collectionRepo.Update(ctx, id, fields, filmsIDs)
+	return
+}
+
+// Delete a collection by id.
+func (uc *CollectionUsecase) Delete(ctx *context.Context, id int) (err error) {
+	err = uc.collectionRepo.Delete(ctx, id)
+	return
+}
+
+// Get all collections, drafts are returned only to admins.
+func (uc *CollectionUsecase) GetAll(ctx *context.Context, isAdmin bool) (collections []*entity.Collection, err error) {
+	collections, err = uc.collectionRepo.SelectAll(ctx, isAdmin)
+	return
+}
+
+// Get a collection with its films in collection order, drafts are returned only to admins.
+func (uc *CollectionUsecase) GetBySlug(ctx *context.Context, slug string, isAdmin bool) (collection *entity.CollectionWithFilms, err error) {
+	found, err := uc.collectionRepo.SelectBySlug(ctx, slug)
+	if err != nil {
+		return
+	}
+	if found.Status != entity.CollectionStatusPublished && !isAdmin {
+		err = repo.ErrCollectionNotFound
+		return
+	}
+	films, err := uc.filmRepo.GetAllWithActors(ctx, nil, &entity.FilmSearchParams{
+		Collection:           slug,
+		WithDraftCollections: true,
+	})
+	if err != nil {
+		return
+	}
+	filmsByID := make(map[int]*entity.FilmWithActors, len(films))
+	for _, film := range films {
+		filmsByID[film.ID] = film
+	}
+	collection = &entity.CollectionWithFilms{
+		ID:          found.ID,
+		Slug:        found.Slug,
+		Title:       found.Title,
+		Description: found.Description,
+		Status:      found.Status,
+		Films:       make([]*entity.FilmWithActors, 0, len(found.FilmsIDs)),
+		CreatedAt:   found.CreatedAt,
+		UpdatedAt:   found.UpdatedAt,
+	}
+	for _, filmID := range found.FilmsIDs {
+		if film, ok := filmsByID[filmID]; ok {
+			collection.Films = append(collection.Films, film)
+		}
+	}
+	return
+}
@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
+)
+
+// CostarRepo interface.
+type CostarRepoInterface interface {
+	SelectByActorID(ctx *context.Context, actorID int, pagination *entity.PaginationParams) (costars []*entity.Costar, err error)
+	SelectCastGraph(ctx *context.Context) (filmsActors []*entity.FilmActor, err error)
+}
+
+// How long the cast graph is reused before it is loaded again, so paths may miss the latest cast changes.
+const castGraphTTL = time.Minute
+
+// Bipartite graph of actors and films they starred in.
+type castGraph struct {
+	actorFilms map[int][]int
+	filmActors map[int][]int
+	loadedAt   time.Time
+}
+
+type CostarUsecase struct {
+	costarRepo CostarRepoInterface
+	actorRepo  ActorRepoInterface
+	filmRepo   FilmRepoInterface
+	mu         sync.Mutex
+	graph      *castGraph
+}
+
+// Create new CostarUsecase.
+func NewCostarUsecase(costarRepo CostarRepoInterface, actorRepo ActorRepoInterface, filmRepo FilmRepoInterface) *CostarUsecase {
+	return &CostarUsecase{
+		costarRepo: costarRepo,
+		actorRepo:  actorRepo,
+		filmRepo:   filmRepo,
+	}
+}
+
+// Get a page of co-stars of an actor ranked by the number of shared films.
+func (uc *CostarUsecase) GetCostars(ctx *context.Context, actorID int, pagination *entity.PaginationParams) (costars []*entity.Costar, err error) {
+	if _, err = uc.actorRepo.SelectByID(ctx, actorID); err != nil {
+		return
+	}
+	costars, err = uc.costarRepo.SelectByActorID(ctx, actorID, pagination)
+	return
+}
+
+// Get the shortest chain of actors connected by shared films, at most maxDegrees films long.
+// The chain is found by BFS over the cached cast graph, its search cost is bounded by the graph size.
+func (uc *CostarUsecase) GetPath(ctx *context.Context, fromID, toID int, maxDegrees int) (path *entity.ActorPath, err error) {
+	from, err := uc.actorRepo.SelectByID(ctx, fromID)
+	if err != nil {
+		return
+	}
+	if _, err = uc.actorRepo.SelectByID(ctx, toID); err != nil {
+		return
+	}
+	graph, err := uc.castGraph(ctx, nil)
+	if err != nil {
+		return
+	}
+	path, err = uc.searchPath(ctx, graph, from, toID, maxDegrees)
+	if err == repo.ErrFilmNotFound || err == repo.ErrActorNotFound {
+		// A film or an actor of the chain was moved to trash after the graph was loaded, search in a fresh one.
+		if graph, err = uc.castGraph(ctx, graph); err != nil {
+			return
+		}
+		path, err = uc.searchPath(ctx, graph, from, toID, maxDegrees)
+	}
+	return
+}
+
+// Find the shortest chain from an actor in the graph and get its films and actors.
+func (uc *CostarUsecase) searchPath(ctx *context.Context, graph *castGraph, from *entity.Actor, toID int, maxDegrees int) (path *entity.ActorPath, err error) {
+	fromID := from.ID
+	type step struct{ actorID, filmID int }
+	prev := map[int]step{fromID: {}}
+	seenFilms := make(map[int]bool)
+	frontier := []int{fromID}
+	for degrees := 0; degrees < maxDegrees && len(frontier) > 0; degrees++ {
+		if _, ok := prev[toID]; ok {
+			break
+		}
+		next := []int{}
+		for _, actorID := range frontier {
+			for _, filmID := range graph.actorFilms[actorID] {
+				if seenFilms[filmID] {
+					continue
+				}
+				seenFilms[filmID] = true
+				for _, costarID := range graph.filmActors[filmID] {
+					if _, ok := prev[costarID]; !ok {
+						prev[costarID] = step{actorID, filmID}
+						next = append(next, costarID)
+					}
+				}
+			}
+		}
+		frontier = next
+	}
+	if _, ok := prev[toID]; !ok {
+		err = entity.ErrActorPathNotFound
+		return
+	}
+
+	actorsIDs := []int{toID}
+	filmsIDs := []int{}
+	for actorID := toID; actorID != fromID; actorID = prev[actorID].actorID {
+		actorsIDs = append(actorsIDs, prev[actorID].actorID)
+		filmsIDs = append(filmsIDs, prev[actorID].filmID)
+	}
+	path = &entity.ActorPath{
+		Degrees: len(filmsIDs),
+		Actors:  []*entity.ActorPathActor{{ID: from.ID, Name: from.Name}},
+		Films:   []*entity.ActorPathFilm{},
+	}
+	for i := len(filmsIDs) - 1; i >= 0; i-- {
+		film, err := uc.filmRepo.SelectByID(ctx, filmsIDs[i])
+		if err != nil {
+			return nil, err
+		}
+		actor, err := uc.actorRepo.SelectByID(ctx, actorsIDs[i])
+		if err != nil {
+			return nil, err
+		}
+		path.Films = append(path.Films, &entity.ActorPathFilm{ID: film.ID, Title: film.Title})
+		path.Actors = append(path.Actors, &entity.ActorPathActor{ID: actor.ID, Name: actor.Name})
+	}
+	return
+}
+
+// Get the cast graph, loading it again if it is older than castGraphTTL or it is the stale one.
+func (uc *CostarUsecase) castGraph(ctx *context.Context, stale *castGraph) (graph *castGraph, err error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.graph != nil && uc.graph != stale && time.Since(uc.graph.loadedAt) < castGraphTTL {
+		return uc.graph, nil
+	}
+	filmsActors, err := uc.costarRepo.SelectCastGraph(ctx)
+	if err != nil {
+		return
+	}
+	graph = &castGraph{
+		actorFilms: make(map[int][]int),
+		filmActors: make(map[int][]int),
+		loadedAt:   time.Now(),
+	}
+	for _, filmActor := range filmsActors {
+		graph.actorFilms[filmActor.ActorID] = append(graph.actorFilms[filmActor.ActorID], filmActor.FilmID)
+		graph.filmActors[filmActor.FilmID] = append(graph.filmActors[filmActor.FilmID], filmActor.ActorID)
+	}
+	uc.graph = graph
+	return
+}
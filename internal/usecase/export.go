@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+)
+
+// ExportRepo interface.
+type ExportRepoInterface interface {
+	ExportActors(ctx *context.Context, handle func(actor *entity.ActorImportRow) error) (err error)
+	ExportFilms(ctx *context.Context, handle func(film *entity.FilmImportRow) error) (err error)
+	ExportCast(ctx *context.Context, handle func(filmActor *entity.FilmActor) error) (err error)
+	ExportUsers(ctx *context.Context, handle func(user *entity.User) error) (err error)
+}
+
+// CSV columns of exported entities. Actors and films use the same columns as the importer.
+var exportCSVHeaders = map[string][]string{
+	entity.ExportEntityActors: {"id", "name", "gender", "birth_date", "death_date", "biography", "birthplace", "nationality", "aliases", "external_ids"},
+	entity.ExportEntityFilms:  {"id", "title", "description", "release_date", "rating", "actors_ids", "actors_names"},
+	entity.ExportEntityCast:   {"film_id", "actor_id"},
+	entity.ExportEntityUsers:  {"id", "username", "is_admin"},
+}
+
+type ExportUsecase struct {
+	exportRepo ExportRepoInterface
+}
+
+// Create new ExportUsecase.
+func NewExportUsecase(exportRepo ExportRepoInterface) *ExportUsecase {
+	return &ExportUsecase{exportRepo}
+}
+
+// Write all rows of an entity to w in NDJSON or CSV format.
+func (uc *ExportUsecase) Export(ctx *context.Context, entityName, format string, w io.Writer) (err error) {
+	encoder := newExportEncoder(format, w, exportCSVHeaders[entityName])
+	switch entityName {
+	case entity.ExportEntityActors:
+		err = uc.exportRepo.ExportActors(ctx, func(actor *entity.ActorImportRow) error {
+			return encoder.encode(actor, func() []string {
+				externalIDs := make([]string, 0, len(actor.ExternalIDs))
+				for source, id := range actor.ExternalIDs {
+					externalIDs = append(externalIDs, source+"="+id)
+				}
+				sort.Strings(externalIDs)
+				return []string{strconv.Itoa(*actor.ID), actor.Name, string(*actor.Gender), actor.BirthDate, actor.DeathDate,
+					actor.Biography, actor.Birthplace, actor.Nationality, strings.Join(actor.Aliases, ";"), strings.Join(externalIDs, ";")}
+			})
+		})
+	case entity.ExportEntityFilms:
+		err = uc.exportRepo.ExportFilms(ctx, func(film *entity.FilmImportRow) error {
+			return encoder.encode(film, func() []string {
+				actorsIDs := make([]string, len(film.ActorsIDs))
+				for i, id := range film.ActorsIDs {
+					actorsIDs[i] = strconv.Itoa(id)
+				}
+				return []string{strconv.Itoa(*film.ID), film.Title, film.Description, film.ReleaseDate,
+					strconv.Itoa(*film.Rating), strings.Join(actorsIDs, ";"), strings.Join(film.ActorsNames, ";")}
+			})
+		})
+	case entity.ExportEntityCast:
+		err = uc.exportRepo.ExportCast(ctx, func(filmActor *entity.FilmActor) error {
+			return encoder.encode(filmActor, func() []string {
+				return []string{strconv.Itoa(filmActor.FilmID), strconv.Itoa(filmActor.ActorID)}
+			})
+		})
+	case entity.ExportEntityUsers:
+		err = uc.exportRepo.ExportUsers(ctx, func(user *entity.User) error {
+			return encoder.encode(user, func() []string {
+				return []string{strconv.Itoa(user.ID), user.Username, strconv.FormatBool(user.IsAdmin)}
+			})
+		})
+	}
+	if err != nil {
+		return
+	}
+	err = encoder.flush()
+	return
+}
+
+// Write all entities to w as a zip archive with an NDJSON file per entity.
+func (uc *ExportUsecase) ExportBundle(ctx *context.Context, w io.Writer) (err error) {
+	archive := zip.NewWriter(w)
+	for _, entityName := range entity.ExportEntities {
+		var file io.Writer
+		file, err = archive.Create(entityName + "." + entity.ExportFormatNDJSON)
+		if err != nil {
+			return
+		}
+		err = uc.Export(ctx, entityName, entity.ExportFormatNDJSON, file)
+		if err != nil {
+			return
+		}
+	}
+	err = archive.Close()
+	return
+}
+
+// Encoder of exported rows into NDJSON or CSV.
+type exportEncoder struct {
+	jsonEncoder *json.Encoder
+	csvWriter   *csv.Writer
+	header      []string
+}
+
+// Create new exportEncoder. CSV header is written together with the first row.
+func newExportEncoder(format string, w io.Writer, header []string) *exportEncoder {
+	if format == entity.ExportFormatCSV {
+		return &exportEncoder{csvWriter: csv.NewWriter(w), header: header}
+	}
+	return &exportEncoder{jsonEncoder: json.NewEncoder(w)}
+}
+
+// Encode a row as a JSON line or as a CSV record built by record function.
+func (e *exportEncoder) encode(value interface{}, record func() []string) (err error) {
+	if e.jsonEncoder != nil {
+		return e.jsonEncoder.Encode(value)
+	}
+	if e.header != nil {
+		if err = e.csvWriter.Write(e.header); err != nil {
+			return
+		}
+		e.header = nil
+	}
+	return e.csvWriter.Write(record())
+}
+
+// Flush buffered rows, CSV header is written even if there were no rows.
+func (e *exportEncoder) flush() (err error) {
+	if e.csvWriter == nil {
+		return
+	}
+	if e.header != nil {
+		if err = e.csvWriter.Write(e.header); err != nil {
+			return
+		}
+	}
+	e.csvWriter.Flush()
+	return e.csvWriter.Error()
+}
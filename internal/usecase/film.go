@@ -3,7 +3,9 @@ package usecase
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/itmosha/vk-internship-2024/internal/entity"
 	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
@@ -13,9 +15,17 @@ import (
 type FilmRepoInterface interface {
 	NewTransaction(ctx *context.Context) (tx *sql.Tx, err error)
 	Insert(ctx *context.Context, receivedFilm *entity.Film) (createdFilm *entity.Film, err error)
-	Update(ctx *context.Context, id int, fields map[string]interface{}) (err error)
-	Delete(ctx *context.Context, id int) (err error)
+	Update(ctx *context.Context, id int, fields map[string]interface{}, versions []int) (err error)
+	Delete(ctx *context.Context, id int, versions []int) (err error)
+	Lock(ctx *context.Context, id int) (err error)
+	SelectByID(ctx *context.Context, id int) (film *entity.FilmWithActors, err error)
+	SelectByIDs(ctx *context.Context, ids []int) (films []*entity.FilmWithActors, err error)
+	SelectListState(ctx *context.Context) (state *entity.ListState, err error)
 	GetAllWithActors(ctx *context.Context, sortParams *entity.FilmSortParams, searchFields *entity.FilmSearchParams) (films []*entity.FilmWithActors, err error)
+	SelectFacets(ctx *context.Context, searchParams *entity.FilmSearchParams, facets []string) (buckets map[string][]*entity.FacetBucket, err error)
+	SelectDeleted(ctx *context.Context) (films []*entity.FilmWithActors, err error)
+	Restore(ctx *context.Context, id int) (err error)
+	Purge(ctx *context.Context, retentionPeriod time.Duration) (cntPurged int64, err error)
 }
 
 // FilmActorRepo interface.
@@ -30,66 +40,64 @@ type FilmUsecase struct {
 	filmRepo        FilmRepoInterface
 	actorRepo       ActorRepoInterface
 	filmsActorsRepo FilmsActorsRepoInterface
+	revisionRepo    RevisionRepoInterface
+	translationRepo TranslationRepoInterface
+	releaseRepo     ReleaseRepoInterface
+	relationRepo    RelationRepoInterface
+	franchiseRepo   FranchiseRepoInterface
 }
 
 // Create new FilmUsecase.
-func NewFilmUsecase(filmRepo FilmRepoInterface, actorRepo ActorRepoInterface, filmsActorsRepo FilmsActorsRepoInterface) *FilmUsecase {
+func NewFilmUsecase(filmRepo FilmRepoInterface, actorRepo ActorRepoInterface, filmsActorsRepo FilmsActorsRepoInterface, revisionRepo RevisionRepoInterface, translationRepo TranslationRepoInterface, releaseRepo ReleaseRepoInterface, relationRepo RelationRepoInterface, franchiseRepo FranchiseRepoInterface) *FilmUsecase {
 	return &FilmUsecase{
 		filmRepo:        filmRepo,
 		actorRepo:       actorRepo,
 		filmsActorsRepo: filmsActorsRepo,
+		revisionRepo:    revisionRepo,
+		translationRepo: translationRepo,
+		releaseRepo:     releaseRepo,
+		relationRepo:    relationRepo,
+		franchiseRepo:   franchiseRepo,
 	}
 }
 
 // Create a new film.
-func (uc *FilmUsecase) Create(ctx *context.Context, body *entity.FilmCreateBody) (film *entity.Film, err error) {
-	tx, err := uc.filmRepo.NewTransaction(ctx)
+func (uc *FilmUsecase) Create(ctx *context.Context, body *entity.FilmCreateBody, userID int) (film *entity.Film, err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
 	if err != nil {
 		return
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-			return
-		}
-		err = tx.Commit()
-	}()
+	defer endTransaction(tx, &err)
 
+	releaseDate, err := entity.ParseDate(body.ReleaseDate)
+	if err != nil {
+		return
+	}
 	filmToCreate := &entity.Film{
 		Title:       body.Title,
 		Description: body.Description,
-		ReleaseDate: body.ReleaseDate,
+		ReleaseDate: releaseDate,
 		Rating:      *body.Rating,
 	}
 	film, err = uc.filmRepo.Insert(ctx, filmToCreate)
 	if err != nil {
 		return
 	}
-	for _, actorID := range body.ActorsIDs {
-		_, err = uc.filmsActorsRepo.Insert(ctx, &entity.FilmActor{
-			FilmID:  film.ID,
-			ActorID: actorID,
-		})
-		if err != nil {
-			return
-		}
+	err = uc.replaceCast(ctx, film.ID, body.ActorsIDs)
+	if err != nil {
+		return
 	}
+	err = uc.recordRevision(ctx, film.ID, entity.RevisionActionCreate, userID, nil)
 	return
 }
 
-// Update a film by id.
-func (uc *FilmUsecase) Update(ctx *context.Context, id int, body *entity.FilmUpdateBody) (err error) {
-	tx, err := uc.filmRepo.NewTransaction(ctx)
+// Update a film by id. If versions are provided, the film is updated only if its current version is one of them.
+func (uc *FilmUsecase) Update(ctx *context.Context, id int, body *entity.FilmUpdateBody, userID int, versions []int) (err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
 	if err != nil {
 		return
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-			return
-		}
-		err = tx.Commit()
-	}()
+	defer endTransaction(tx, &err)
 
 	fields := map[string]interface{}{}
 	if len(body.Title) > 0 {
@@ -99,75 +107,284 @@ func (uc *FilmUsecase) Update(ctx *context.Context, id int, body *entity.FilmUpd
 		fields["description"] = body.Description
 	}
 	if len(body.ReleaseDate) > 0 {
-		fields["release_date"] = body.ReleaseDate
+		var releaseDate entity.Date
+		releaseDate, err = entity.ParseDate(body.ReleaseDate)
+		if err != nil {
+			return
+		}
+		fields["release_date"] = releaseDate
+		fields["release_date_precision"] = releaseDate.Precision
 	}
 	if body.Rating != nil {
 		fields["rating"] = *body.Rating
 	}
-	if len(fields) == 0 {
+	if len(fields) == 0 && body.ActorsIDs == nil {
 		return
 	}
-	err = uc.filmRepo.Update(ctx, id, fields)
+	before, err := uc.selectByIDWithVersion(ctx, id, versions)
+	if err != nil {
+		return
+	}
+	err = uc.filmRepo.Update(ctx, id, fields, versions)
 	if err != nil {
 		return
 	}
 	if body.ActorsIDs != nil {
-		filmsActors, err_ := uc.filmsActorsRepo.SelectByFilmID(ctx, id)
-		if err_ != nil {
-			err = err_
+		err = uc.replaceCast(ctx, id, body.ActorsIDs)
+		if err != nil {
 			return
 		}
-		for _, filmActor := range filmsActors {
-			err = uc.filmsActorsRepo.Delete(ctx, id, filmActor.ActorID)
-			if err != nil {
-				if errors.Is(err, repo.ErrFilmActorNotFound) {
-					err = nil
-				} else {
-					return
-				}
-			}
-		}
-		for _, actorID := range body.ActorsIDs {
-			_, err = uc.filmsActorsRepo.Insert(ctx, &entity.FilmActor{
-				FilmID:  id,
-				ActorID: actorID,
-			})
-			if err != nil {
-				return
-			}
-		}
 	}
+	err = uc.recordRevision(ctx, id, entity.RevisionActionUpdate, userID, before)
+	return
+}
+
+// Replace a film by id. If versions are provided, the film is replaced only if its current version is one of them.
+func (uc *FilmUsecase) Replace(ctx *context.Context, id int, body *entity.FilmReplaceBody, userID int, versions []int) (err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+	releaseDate, err := entity.ParseDate(body.ReleaseDate)
+	if err != nil {
+		return
+	}
+	fields := map[string]interface{}{
+		"title":                  body.Title,
+		"description":            body.Description,
+		"release_date":           releaseDate,
+		"release_date_precision": releaseDate.Precision,
+		"rating":                 *body.Rating,
+	}
+
+	before, err := uc.selectByIDWithVersion(ctx, id, versions)
+	if err != nil {
+		return
+	}
+	err = uc.filmRepo.Update(ctx, id, fields, versions)
+	if err != nil {
+		return
+	}
+	err = uc.replaceCast(ctx, id, body.ActorsIDs)
+	if err != nil {
+		return
+	}
+	err = uc.recordRevision(ctx, id, entity.RevisionActionUpdate, userID, before)
 	return
 }
 
-// Replace a film by id.
-func (uc *FilmUsecase) Replace(ctx *context.Context, id int, body *entity.FilmReplaceBody) (err error) {
-	tx, err := uc.filmRepo.NewTransaction(ctx)
+// Move a film to trash by id. If versions are provided, the film is deleted only if its current version is one of them.
+func (uc *FilmUsecase) Delete(ctx *context.Context, id int, userID int, versions []int) (err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
 	if err != nil {
 		return
 	}
-	defer func() {
+	defer endTransaction(tx, &err)
+
+	before, err := uc.selectByIDWithVersion(ctx, id, versions)
+	if err != nil {
+		return
+	}
+	err = uc.filmRepo.Delete(ctx, id, versions)
+	if err != nil {
+		return
+	}
+	err = recordRevision(ctx, uc.revisionRepo, entity.RevisionEntityFilm, id, entity.RevisionActionDelete, userID, before, nil)
+	return
+}
+
+// Get a film by id. If asOf is provided, the film is returned as it was at that time.
+// Title and description are translated to the most preferred of locales available, if any.
+// Releases, certifications, related films and franchises are not versioned, so they are present only in the current film.
+func (uc *FilmUsecase) GetByID(ctx *context.Context, id int, asOf *time.Time, locales []string) (film *entity.FilmWithActors, err error) {
+	if asOf == nil {
+		film, err = uc.filmRepo.SelectByID(ctx, id)
 		if err != nil {
-			tx.Rollback()
 			return
 		}
-		err = tx.Commit()
-	}()
-	fields := map[string]interface{}{
-		"title":        body.Title,
-		"description":  body.Description,
-		"release_date": body.ReleaseDate,
-		"rating":       *body.Rating,
+		if err = translateFilms(ctx, uc.translationRepo, []*entity.FilmWithActors{film}, locales); err != nil {
+			return
+		}
+		if err = attachReleaseInfo(ctx, uc.releaseRepo, []*entity.FilmWithActors{film}, ""); err != nil {
+			return
+		}
+		if film.Relations, err = uc.relationRepo.SelectByFilmID(ctx, id); err != nil {
+			return
+		}
+		film.Franchises, err = uc.franchiseRepo.SelectByFilmID(ctx, id)
+		return
+	}
+	snapshot, err := snapshotAt(ctx, uc.revisionRepo, entity.RevisionEntityFilm, id, *asOf)
+	if err != nil {
+		if errors.Is(err, repo.ErrRevisionNotFound) {
+			err = repo.ErrFilmNotFound
+		}
+		return
 	}
+	if snapshot == nil {
+		film, err = uc.filmRepo.SelectByID(ctx, id)
+	} else {
+		film = &entity.FilmWithActors{}
+		err = json.Unmarshal(snapshot, film)
+	}
+	if err != nil {
+		return
+	}
+	err = translateFilms(ctx, uc.translationRepo, []*entity.FilmWithActors{film}, locales)
+	return
+}
 
-	err = uc.filmRepo.Update(ctx, id, fields)
+// Get films by ids with titles and descriptions translated to the most preferred of locales available.
+// Missing films are skipped, the order of films is not specified.
+func (uc *FilmUsecase) GetByIDs(ctx *context.Context, ids []int, locales []string) (films []*entity.FilmWithActors, err error) {
+	films, err = uc.filmRepo.SelectByIDs(ctx, ids)
 	if err != nil {
 		return
 	}
+	err = translateFilms(ctx, uc.translationRepo, films, locales)
+	return
+}
 
-	filmsActors, err_ := uc.filmsActorsRepo.SelectByFilmID(ctx, id)
-	if err_ != nil {
-		err = err_
+// Get all films with titles and descriptions translated to the most preferred of locales available.
+// If films are filtered by country, only their releases and certifications in that country are present.
+func (uc *FilmUsecase) GetAll(ctx *context.Context, sortParams *entity.FilmSortParams, searchFields *entity.FilmSearchParams, locales []string) (films []*entity.FilmWithActors, err error) {
+	films, err = uc.filmRepo.GetAllWithActors(ctx, sortParams, searchFields)
+	if err != nil {
+		return
+	}
+	if err = translateFilms(ctx, uc.translationRepo, films, locales); err != nil {
+		return
+	}
+	country := ""
+	if searchFields != nil {
+		country = searchFields.Country
+	}
+	err = attachReleaseInfo(ctx, uc.releaseRepo, films, country)
+	return
+}
+
+// Get state of lists of films, it changes whenever any listed film may change.
+func (uc *FilmUsecase) GetListState(ctx *context.Context) (state *entity.ListState, err error) {
+	state, err = uc.filmRepo.SelectListState(ctx)
+	return
+}
+
+// Get facet buckets of all films matching search params.
+func (uc *FilmUsecase) GetFacets(ctx *context.Context, searchFields *entity.FilmSearchParams, facets []string) (buckets map[string][]*entity.FacetBucket, err error) {
+	buckets, err = uc.filmRepo.SelectFacets(ctx, searchFields, facets)
+	return
+}
+
+// Get a page of film's change history, newest first.
+func (uc *FilmUsecase) GetHistory(ctx *context.Context, id int, pagination *entity.PaginationParams) (revisions []*entity.Revision, err error) {
+	revisions, err = uc.revisionRepo.SelectByEntity(ctx, entity.RevisionEntityFilm, id, pagination)
+	return
+}
+
+// Revert a film to the state it had in provided revision. Films in trash are restored.
+func (uc *FilmUsecase) Revert(ctx *context.Context, id int, revisionID int64, userID int) (film *entity.FilmWithActors, err error) {
+	revision, err := uc.revisionRepo.SelectByID(ctx, entity.RevisionEntityFilm, id, revisionID)
+	if err != nil {
+		return
+	}
+	state := &entity.FilmWithActors{}
+	err = json.Unmarshal(revision.Snapshot, state)
+	if err != nil {
+		return
+	}
+
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+	err = uc.filmRepo.Lock(ctx, id)
+	if err != nil {
+		return
+	}
+	before, err := uc.filmRepo.SelectByID(ctx, id)
+	if errors.Is(err, repo.ErrFilmNotFound) {
+		before = nil
+		err = uc.filmRepo.Restore(ctx, id)
+	}
+	if err != nil {
+		return
+	}
+	err = uc.filmRepo.Update(ctx, id, map[string]interface{}{
+		"title":                  state.Title,
+		"description":            state.Description,
+		"release_date":           state.ReleaseDate,
+		"release_date_precision": state.ReleaseDate.Precision,
+		"rating":                 state.Rating,
+	}, nil)
+	if err != nil {
+		return
+	}
+	err = uc.replaceCast(ctx, id, state.ActorsIDs)
+	if err != nil {
+		return
+	}
+	err = uc.recordRevision(ctx, id, entity.RevisionActionRevert, userID, before)
+	if err != nil {
+		return
+	}
+	film, err = uc.filmRepo.SelectByID(ctx, id)
+	return
+}
+
+// Get all films in trash.
+func (uc *FilmUsecase) GetTrash(ctx *context.Context) (films []*entity.FilmWithActors, err error) {
+	films, err = uc.filmRepo.SelectDeleted(ctx)
+	return
+}
+
+// Restore a film from trash by id.
+func (uc *FilmUsecase) Restore(ctx *context.Context, id int, userID int) (err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	err = uc.filmRepo.Restore(ctx, id)
+	if err != nil {
+		return
+	}
+	err = uc.recordRevision(ctx, id, entity.RevisionActionRestore, userID, nil)
+	return
+}
+
+// Permanently delete films that are in trash for longer than retention period.
+func (uc *FilmUsecase) PurgeTrash(ctx *context.Context, retentionPeriod time.Duration) (cntPurged int64, err error) {
+	cntPurged, err = uc.filmRepo.Purge(ctx, retentionPeriod)
+	return
+}
+
+// Lock a film by id, get it and check that its current version is one of provided versions, if any.
+// Cast links are written outside of the conditional film update, so the check is done before any writes.
+func (uc *FilmUsecase) selectByIDWithVersion(ctx *context.Context, id int, versions []int) (film *entity.FilmWithActors, err error) {
+	err = uc.filmRepo.Lock(ctx, id)
+	if err != nil {
+		return
+	}
+	film, err = uc.filmRepo.SelectByID(ctx, id)
+	if err != nil || versions == nil {
+		return
+	}
+	for _, version := range versions {
+		if version == film.Version {
+			return
+		}
+	}
+	err = repo.ErrVersionMismatch
+	return
+}
+
+// Replace all actors of a film.
+func (uc *FilmUsecase) replaceCast(ctx *context.Context, id int, actorsIDs []int) (err error) {
+	filmsActors, err := uc.filmsActorsRepo.SelectByFilmID(ctx, id)
+	if err != nil {
 		return
 	}
 	for _, filmActor := range filmsActors {
@@ -180,7 +397,7 @@ func (uc *FilmUsecase) Replace(ctx *context.Context, id int, body *entity.FilmRe
 			}
 		}
 	}
-	for _, actorID := range body.ActorsIDs {
+	for _, actorID := range actorsIDs {
 		_, err = uc.filmsActorsRepo.Insert(ctx, &entity.FilmActor{
 			FilmID:  id,
 			ActorID: actorID,
@@ -192,14 +409,12 @@ func (uc *FilmUsecase) Replace(ctx *context.Context, id int, body *entity.FilmRe
 	return
 }
 
-// Delete a film by id.
-func (uc *FilmUsecase) Delete(ctx *context.Context, id int) (err error) {
-	err = uc.filmRepo.Delete(ctx, id)
-	return
-}
-
-// Get all films.
-func (uc *FilmUsecase) GetAll(ctx *context.Context, sortParams *entity.FilmSortParams, searchFields *entity.FilmSearchParams) (films []*entity.FilmWithActors, err error) {
-	films, err = uc.filmRepo.GetAllWithActors(ctx, sortParams, searchFields)
+// Record a change of a film comparing its current state with the state before the change.
+func (uc *FilmUsecase) recordRevision(ctx *context.Context, id int, action string, userID int, before *entity.FilmWithActors) (err error) {
+	after, err := uc.filmRepo.SelectByID(ctx, id)
+	if err != nil {
+		return
+	}
+	err = recordRevision(ctx, uc.revisionRepo, entity.RevisionEntityFilm, id, action, userID, before, after)
 	return
 }
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+)
+
+// FranchiseRepo interface.
+type FranchiseRepoInterface interface {
+	Insert(ctx *context.Context, receivedFranchise *entity.Franchise) (createdFranchise *entity.Franchise, err error)
+	Replace(ctx *context.Context, id int, receivedFranchise *entity.Franchise) (err error)
+	Delete(ctx *context.Context, id int) (err error)
+	SelectAll(ctx *context.Context) (franchises []*entity.Franchise, err error)
+	SelectByID(ctx *context.Context, id int) (franchise *entity.Franchise, err error)
+	SelectByFilmID(ctx *context.Context, filmID int) (franchises []*entity.FilmFranchise, err error)
+}
+
+type FranchiseUsecase struct {
+	franchiseRepo FranchiseRepoInterface
+	filmRepo      FilmRepoInterface
+}
+
+// Create new FranchiseUsecase.
+func NewFranchiseUsecase(franchiseRepo FranchiseRepoInterface, filmRepo FilmRepoInterface) *FranchiseUsecase {
+	return &FranchiseUsecase{
+		franchiseRepo: franchiseRepo,
+		filmRepo:      filmRepo,
+	}
+}
+
+// Create a new franchise.
+func (uc *FranchiseUsecase) Create(ctx *context.Context, body *entity.FranchiseBody) (franchise *entity.Franchise, err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	franchiseToCreate := &entity.Franchise{Name: body.Name, FilmsIDs: body.FilmsIDs}
+	if franchiseToCreate.FilmsIDs == nil {
+		franchiseToCreate.FilmsIDs = []int{}
+	}
+	franchise, err = uc.franchiseRepo.Insert(ctx, franchiseToCreate)
+	return
+}
+
+// Replace a franchise by id.
+func (uc *FranchiseUsecase) Replace(ctx *context.Context, id int, body *entity.FranchiseBody) (err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	franchiseToReplace := &entity.Franchise{Name: body.Name, FilmsIDs: body.FilmsIDs}
+	if franchiseToReplace.FilmsIDs == nil {
+		franchiseToReplace.FilmsIDs = []int{}
+	}
+	err = uc.franchiseRepo.Replace(ctx, id, franchiseToReplace)
+	return
+}
+
+// Delete a franchise by id.
+func (uc *FranchiseUsecase) Delete(ctx *context.Context, id int) (err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	err = uc.franchiseRepo.Delete(ctx, id)
+	return
+}
+
+// Get all franchises.
+func (uc *FranchiseUsecase) GetAll(ctx *context.Context) (franchises []*entity.Franchise, err error) {
+	franchises, err = uc.franchiseRepo.SelectAll(ctx)
+	return
+}
+
+// Get a franchise with its not deleted films in chronological order and in release order.
+// Films released on the same date keep their chronological order.
+func (uc *FranchiseUsecase) GetByID(ctx *context.Context, id int) (franchise *entity.FranchiseWithFilms, err error) {
+	found, err := uc.franchiseRepo.SelectByID(ctx, id)
+	if err != nil {
+		return
+	}
+	films, err := uc.filmRepo.GetAllWithActors(ctx, nil, &entity.FilmSearchParams{Franchise: id})
+	if err != nil {
+		return
+	}
+	filmsByID := make(map[int]*entity.FilmWithActors, len(films))
+	for _, film := range films {
+		filmsByID[film.ID] = film
+	}
+	franchise = &entity.FranchiseWithFilms{
+		ID:                 found.ID,
+		Name:               found.Name,
+		ChronologicalOrder: make([]*entity.FilmWithActors, 0, len(found.FilmsIDs)),
+	}
+	for _, filmID := range found.FilmsIDs {
+		if film, ok := filmsByID[filmID]; ok {
+			franchise.ChronologicalOrder = append(franchise.ChronologicalOrder, film)
+		}
+	}
+	franchise.ReleaseOrder = slices.Clone(franchise.ChronologicalOrder)
+	slices.SortStableFunc(franchise.ReleaseOrder, func(a, b *entity.FilmWithActors) int {
+		return cmp.Compare(a.ReleaseDate.Time.Unix(), b.ReleaseDate.Time.Unix())
+	})
+	return
+}
@@ -0,0 +1,512 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+)
+
+// Maximum length of a single NDJSON line.
+const maxImportLineSize = 1 << 20
+
+// ImportRepo interface.
+type ImportRepoInterface interface {
+	SelectActorsIDsByNames(ctx *context.Context, names []string) (ids map[string][]int, err error)
+	ImportActors(ctx *context.Context, rows []*entity.ActorImportRow, dryRun bool) (results []*entity.ImportRowResult, err error)
+	ImportFilms(ctx *context.Context, rows []*entity.FilmImportRow, dryRun bool) (results []*entity.ImportRowResult, err error)
+}
+
+type ImportUsecase struct {
+	importRepo   ImportRepoInterface
+	filmRepo     FilmRepoInterface
+	actorRepo    ActorRepoInterface
+	revisionRepo RevisionRepoInterface
+}
+
+// Create new ImportUsecase.
+func NewImportUsecase(importRepo ImportRepoInterface, filmRepo FilmRepoInterface, actorRepo ActorRepoInterface, revisionRepo RevisionRepoInterface) *ImportUsecase {
+	return &ImportUsecase{
+		importRepo:   importRepo,
+		filmRepo:     filmRepo,
+		actorRepo:    actorRepo,
+		revisionRepo: revisionRepo,
+	}
+}
+
+// Import actors from CSV or NDJSON input in batches, each batch is imported as soon as it is read.
+func (uc *ImportUsecase) ImportActors(ctx *context.Context, params *entity.ImportParams, reader io.Reader, userID int) (report *entity.ImportReport, err error) {
+	report = &entity.ImportReport{DryRun: params.DryRun, Rows: []*entity.ImportRowResult{}}
+	var batch []*entity.ActorImportRow
+	err = readImportRows(params.Format, reader, []string{"name", "gender", "birth_date"}, actorImportRowFromCSV,
+		func(rowNum int, row *entity.ActorImportRow, rowErr error) (err error) {
+			if rowErr == nil {
+				row.Row = rowNum
+				if params.IgnoreIDs {
+					row.ID = nil
+				}
+				rowErr = entity.ValidateActorImportRow(row)
+			}
+			if rowErr != nil {
+				report.Rows = append(report.Rows, failedImportRow(rowNum, rowErr))
+				return
+			}
+			batch = append(batch, row)
+			if len(batch) < entity.ImportBatchSize {
+				return
+			}
+			err = uc.importActorsBatch(ctx, batch, params.DryRun, userID, report)
+			batch = nil
+			return
+		})
+	if err == nil {
+		err = uc.importActorsBatch(ctx, batch, params.DryRun, userID, report)
+	}
+	if err != nil {
+		return nil, err
+	}
+	finishImportReport(report)
+	return
+}
+
+// Import a batch of actors with their revisions in one transaction and add results to the report.
+// Dry-run transaction is rolled back.
+func (uc *ImportUsecase) importActorsBatch(ctx *context.Context, batch []*entity.ActorImportRow, dryRun bool, userID int, report *entity.ImportReport) (err error) {
+	if len(batch) == 0 {
+		return
+	}
+	tx, ctx, err := beginTransaction(ctx, uc.actorRepo)
+	if err != nil {
+		return
+	}
+	defer endImportTransaction(tx, dryRun, &err)
+
+	ids := []int{}
+	for _, row := range batch {
+		if row.ID != nil {
+			ids = append(ids, *row.ID)
+		}
+	}
+	before, err := uc.selectActorsByIDs(ctx, ids, dryRun)
+	if err != nil {
+		return
+	}
+	results, err := uc.importRepo.ImportActors(ctx, batch, dryRun)
+	if err != nil {
+		return
+	}
+	after, err := uc.selectActorsByIDs(ctx, importedIDs(results), dryRun)
+	if err != nil {
+		return
+	}
+	for _, result := range results {
+		if dryRun || result.Status == entity.ImportRowStatusFailed {
+			continue
+		}
+		err = recordRevision(ctx, uc.revisionRepo, entity.RevisionEntityActor, result.ID, importRevisionAction(result), userID, before[result.ID], after[result.ID])
+		if err != nil {
+			return
+		}
+	}
+	report.Rows = append(report.Rows, results...)
+	return
+}
+
+// Select snapshots of imported actors by their ids, snapshots are not needed in dry-run mode.
+func (uc *ImportUsecase) selectActorsByIDs(ctx *context.Context, ids []int, dryRun bool) (actors map[int]*entity.Actor, err error) {
+	actors = map[int]*entity.Actor{}
+	if dryRun || len(ids) == 0 {
+		return
+	}
+	selected, err := uc.actorRepo.SelectByIDs(ctx, ids)
+	if err != nil {
+		return
+	}
+	for _, actor := range selected {
+		actors[actor.ID] = &actor.Actor
+	}
+	return
+}
+
+// Import films from CSV or NDJSON input in batches, each batch is imported as soon as it is read.
+// Actors referenced by name must match exactly one actor.
+func (uc *ImportUsecase) ImportFilms(ctx *context.Context, params *entity.ImportParams, reader io.Reader, userID int) (report *entity.ImportReport, err error) {
+	report = &entity.ImportReport{DryRun: params.DryRun, Rows: []*entity.ImportRowResult{}}
+	var batch []*entity.FilmImportRow
+	err = readImportRows(params.Format, reader, []string{"title", "release_date", "rating"}, filmImportRowFromCSV,
+		func(rowNum int, row *entity.FilmImportRow, rowErr error) (err error) {
+			if rowErr != nil {
+				report.Rows = append(report.Rows, failedImportRow(rowNum, rowErr))
+				return
+			}
+			row.Row = rowNum
+			if params.IgnoreIDs {
+				row.ID = nil
+				row.ActorsIDs = nil
+			}
+			batch = append(batch, row)
+			if len(batch) < entity.ImportBatchSize {
+				return
+			}
+			err = uc.importFilmsBatch(ctx, batch, params.DryRun, userID, report)
+			batch = nil
+			return
+		})
+	if err == nil {
+		err = uc.importFilmsBatch(ctx, batch, params.DryRun, userID, report)
+	}
+	if err != nil {
+		return nil, err
+	}
+	finishImportReport(report)
+	return
+}
+
+// Import a batch of films with their revisions in one transaction and add results to the report.
+// Dry-run transaction is rolled back.
+func (uc *ImportUsecase) importFilmsBatch(ctx *context.Context, batch []*entity.FilmImportRow, dryRun bool, userID int, report *entity.ImportReport) (err error) {
+	if len(batch) == 0 {
+		return
+	}
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
+	if err != nil {
+		return
+	}
+	defer endImportTransaction(tx, dryRun, &err)
+
+	batch, err = uc.resolveActorsNames(ctx, batch, report)
+	if err != nil || len(batch) == 0 {
+		return
+	}
+	ids := []int{}
+	for _, row := range batch {
+		if row.ID != nil {
+			ids = append(ids, *row.ID)
+		}
+	}
+	before, err := uc.selectFilmsByIDs(ctx, ids, dryRun)
+	if err != nil {
+		return
+	}
+	results, err := uc.importRepo.ImportFilms(ctx, batch, dryRun)
+	if err != nil {
+		return
+	}
+	after, err := uc.selectFilmsByIDs(ctx, importedIDs(results), dryRun)
+	if err != nil {
+		return
+	}
+	for _, result := range results {
+		if dryRun || result.Status == entity.ImportRowStatusFailed {
+			continue
+		}
+		err = recordRevision(ctx, uc.revisionRepo, entity.RevisionEntityFilm, result.ID, importRevisionAction(result), userID, before[result.ID], after[result.ID])
+		if err != nil {
+			return
+		}
+	}
+	report.Rows = append(report.Rows, results...)
+	return
+}
+
+// Select snapshots of imported films by their ids, snapshots are not needed in dry-run mode.
+func (uc *ImportUsecase) selectFilmsByIDs(ctx *context.Context, ids []int, dryRun bool) (films map[int]*entity.FilmWithActors, err error) {
+	films = map[int]*entity.FilmWithActors{}
+	if dryRun || len(ids) == 0 {
+		return
+	}
+	selected, err := uc.filmRepo.SelectByIDs(ctx, ids)
+	if err != nil {
+		return
+	}
+	for _, film := range selected {
+		films[film.ID] = film
+	}
+	return
+}
+
+// Resolve actors' names of film rows to ids and validate the rows.
+// Rows that could not be resolved or are invalid are added to the report as failed.
+func (uc *ImportUsecase) resolveActorsNames(ctx *context.Context, rows []*entity.FilmImportRow, report *entity.ImportReport) (valid []*entity.FilmImportRow, err error) {
+	names := []string{}
+	for _, row := range rows {
+		names = append(names, row.ActorsNames...)
+	}
+	ids := map[string][]int{}
+	if len(names) > 0 {
+		ids, err = uc.importRepo.SelectActorsIDsByNames(ctx, names)
+		if err != nil {
+			return
+		}
+	}
+
+rowsLoop:
+	for _, row := range rows {
+		seen := map[int]bool{}
+		actorsIDs := []int{}
+		for _, id := range row.ActorsIDs {
+			if !seen[id] {
+				seen[id] = true
+				actorsIDs = append(actorsIDs, id)
+			}
+		}
+		for _, name := range row.ActorsNames {
+			switch len(ids[name]) {
+			case 0:
+				report.Rows = append(report.Rows, failedImportRow(row.Row, fmt.Errorf("%w: %s", entity.ErrUnknownActorName, name)))
+				continue rowsLoop
+			case 1:
+				if id := ids[name][0]; !seen[id] {
+					seen[id] = true
+					actorsIDs = append(actorsIDs, id)
+				}
+			default:
+				report.Rows = append(report.Rows, failedImportRow(row.Row, fmt.Errorf("%w: %s", entity.ErrAmbiguousActorName, name)))
+				continue rowsLoop
+			}
+		}
+		row.ActorsIDs = actorsIDs
+		if rowErr := entity.ValidateFilmImportRow(row); rowErr != nil {
+			report.Rows = append(report.Rows, failedImportRow(row.Row, rowErr))
+			continue
+		}
+		valid = append(valid, row)
+	}
+	return
+}
+
+// Read import input row by row and call handle with the row number and either a decoded row or a reason
+// why the row could not be decoded. Errors that make the whole input unreadable and errors of handle are returned.
+func readImportRows[T any](format string, reader io.Reader, requiredColumns []string, fromCSV func(record map[string]string) (*T, error), handle func(rowNum int, row *T, rowErr error) error) (err error) {
+	switch format {
+	case entity.ImportFormatCSV:
+		csvReader := csv.NewReader(reader)
+		csvReader.FieldsPerRecord = -1
+		csvReader.TrimLeadingSpace = true
+		var header []string
+		header, err = csvReader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%w: %s", entity.ErrInvalidImportRow, err)
+		}
+		header = append([]string{}, header...)
+		for i := range header {
+			header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff")))
+		}
+		for _, column := range requiredColumns {
+			found := false
+			for _, name := range header {
+				found = found || name == column
+			}
+			if !found {
+				return fmt.Errorf("%w: %s", entity.ErrMissingImportColumn, column)
+			}
+		}
+		for rowNum := 1; ; rowNum++ {
+			values, readErr := csvReader.Read()
+			if readErr == io.EOF {
+				return nil
+			}
+			var parseErr *csv.ParseError
+			if errors.As(readErr, &parseErr) {
+				if err = handle(rowNum, nil, fmt.Errorf("%w: %s", entity.ErrInvalidImportRow, parseErr.Err)); err != nil {
+					return
+				}
+				continue
+			} else if readErr != nil {
+				return readErr
+			}
+			if len(values) != len(header) {
+				if err = handle(rowNum, nil, fmt.Errorf("%w: %s", entity.ErrInvalidImportRow, csv.ErrFieldCount)); err != nil {
+					return
+				}
+				continue
+			}
+			record := make(map[string]string, len(header))
+			for i, name := range header {
+				record[name] = strings.TrimSpace(values[i])
+			}
+			row, rowErr := fromCSV(record)
+			if err = handle(rowNum, row, rowErr); err != nil {
+				return
+			}
+		}
+	case entity.ImportFormatNDJSON:
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineSize)
+		for rowNum := 1; scanner.Scan(); rowNum++ {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			row := new(T)
+			if jsonErr := json.Unmarshal(line, row); jsonErr != nil {
+				if err = handle(rowNum, nil, fmt.Errorf("%w: %s", entity.ErrInvalidImportRow, jsonErr)); err != nil {
+					return
+				}
+				continue
+			}
+			if err = handle(rowNum, row, nil); err != nil {
+				return
+			}
+		}
+		return scanner.Err()
+	default:
+		return entity.ErrInvalidImportFormat
+	}
+}
+
+// Convert a CSV record to an actor import row. Aliases and external ids are separated by semicolons,
+// external ids are written as source=id.
+func actorImportRowFromCSV(record map[string]string) (row *entity.ActorImportRow, err error) {
+	row = &entity.ActorImportRow{
+		Name:        record["name"],
+		BirthDate:   record["birth_date"],
+		DeathDate:   record["death_date"],
+		Biography:   record["biography"],
+		Birthplace:  record["birthplace"],
+		Nationality: record["nationality"],
+		Aliases:     splitImportList(record["aliases"]),
+	}
+	row.ID, err = parseImportID(record["id"])
+	if err != nil {
+		return nil, err
+	}
+	if gender := record["gender"]; gender != "" {
+		value, err := entity.ParseGender(gender)
+		if err != nil {
+			return nil, err
+		}
+		row.Gender = &value
+	}
+	for _, part := range splitImportList(record["external_ids"]) {
+		source, id, found := strings.Cut(part, "=")
+		if !found {
+			return nil, entity.ErrInvalidActorExternalIDs
+		}
+		if row.ExternalIDs == nil {
+			row.ExternalIDs = map[string]string{}
+		}
+		row.ExternalIDs[strings.TrimSpace(source)] = strings.TrimSpace(id)
+	}
+	return
+}
+
+// Convert a CSV record to a film import row. Actors' ids and names are separated by semicolons.
+func filmImportRowFromCSV(record map[string]string) (row *entity.FilmImportRow, err error) {
+	row = &entity.FilmImportRow{
+		Title:       record["title"],
+		Description: record["description"],
+		ReleaseDate: record["release_date"],
+	}
+	row.ID, err = parseImportID(record["id"])
+	if err != nil {
+		return nil, err
+	}
+	if rating := record["rating"]; rating != "" {
+		value, err := strconv.Atoi(rating)
+		if err != nil {
+			return nil, entity.ErrInvalidFilmRating
+		}
+		row.Rating = &value
+	}
+	for _, part := range splitImportList(record["actors_ids"]) {
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, entity.ErrInvalidImportActorsIDs
+		}
+		row.ActorsIDs = append(row.ActorsIDs, id)
+	}
+	row.ActorsNames = splitImportList(record["actors_names"])
+	return
+}
+
+// Parse an optional id column, empty value means that a new entity should be created.
+func parseImportID(value string) (id *int, err error) {
+	if value == "" {
+		return
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, entity.ErrInvalidImportID
+	}
+	id = &parsed
+	return
+}
+
+// Split a semicolon separated list, skipping empty items.
+func splitImportList(value string) (items []string) {
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return
+}
+
+// Create a failed row result.
+func failedImportRow(rowNum int, err error) *entity.ImportRowResult {
+	return &entity.ImportRowResult{
+		Row:    rowNum,
+		Status: entity.ImportRowStatusFailed,
+		Error:  err.Error(),
+	}
+}
+
+// Get ids of imported rows.
+func importedIDs(results []*entity.ImportRowResult) (ids []int) {
+	for _, result := range results {
+		if result.Status != entity.ImportRowStatusFailed {
+			ids = append(ids, result.ID)
+		}
+	}
+	return
+}
+
+// Get the revision action of an imported row.
+func importRevisionAction(result *entity.ImportRowResult) string {
+	if result.Status == entity.ImportRowStatusCreated {
+		return entity.RevisionActionCreate
+	}
+	return entity.RevisionActionUpdate
+}
+
+// Commit a transaction of an import batch, or roll it back on error or in dry-run mode.
+func endImportTransaction(tx *sql.Tx, dryRun bool, err *error) {
+	if dryRun {
+		tx.Rollback()
+		return
+	}
+	endTransaction(tx, err)
+}
+
+// Sort report rows and count them by status. Ids of rows that would be created are hidden in dry-run mode.
+func finishImportReport(report *entity.ImportReport) {
+	sort.Slice(report.Rows, func(i, j int) bool {
+		return report.Rows[i].Row < report.Rows[j].Row
+	})
+	for _, row := range report.Rows {
+		switch row.Status {
+		case entity.ImportRowStatusCreated:
+			report.Created++
+			if report.DryRun {
+				row.ID = 0
+			}
+		case entity.ImportRowStatusUpdated:
+			report.Updated++
+		case entity.ImportRowStatusFailed:
+			report.Failed++
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
+	"github.com/itmosha/vk-internship-2024/pkg/blobstorage"
+)
+
+// BlobStorage interface.
+type BlobStorageInterface interface {
+	Put(ctx context.Context, key, contentType string, data []byte) (err error)
+	Get(ctx context.Context, key string) (body io.ReadCloser, contentType string, err error)
+	Delete(ctx context.Context, key string) (err error)
+	URL(key string) string
+}
+
+type MediaUsecase struct {
+	filmRepo      FilmRepoInterface
+	actorRepo     ActorRepoInterface
+	revisionRepo  RevisionRepoInterface
+	storage       BlobStorageInterface
+	maxUploadSize int64
+}
+
+// Create new MediaUsecase. Uploaded images larger than maxUploadSize bytes are rejected.
+func NewMediaUsecase(filmRepo FilmRepoInterface, actorRepo ActorRepoInterface, revisionRepo RevisionRepoInterface, storage BlobStorageInterface, maxUploadSize int64) *MediaUsecase {
+	return &MediaUsecase{
+		filmRepo:      filmRepo,
+		actorRepo:     actorRepo,
+		revisionRepo:  revisionRepo,
+		storage:       storage,
+		maxUploadSize: maxUploadSize,
+	}
+}
+
+// Upload a poster of a film, replacing the previous one.
+func (uc *MediaUsecase) UploadFilmPoster(ctx *context.Context, filmID int, reader io.Reader, userID int) (poster *entity.Image, err error) {
+	if _, err = uc.filmRepo.SelectByID(ctx, filmID); err != nil {
+		return
+	}
+	poster, err = uc.storeImage(ctx, "films/"+strconv.Itoa(filmID)+"/poster-", reader)
+	if err != nil {
+		return
+	}
+	replaced, err := uc.setFilmPoster(ctx, filmID, poster, userID)
+	if err != nil {
+		uc.deleteImage(ctx, poster)
+		return nil, err
+	}
+	uc.deleteImage(ctx, replaced)
+	return
+}
+
+// Delete a poster of a film.
+func (uc *MediaUsecase) DeleteFilmPoster(ctx *context.Context, filmID int, userID int) (err error) {
+	replaced, err := uc.setFilmPoster(ctx, filmID, nil, userID)
+	if err != nil {
+		return
+	}
+	uc.deleteImage(ctx, replaced)
+	return
+}
+
+// Upload a photo of an actor, replacing the previous one.
+func (uc *MediaUsecase) UploadActorPhoto(ctx *context.Context, actorID int, reader io.Reader, userID int) (photo *entity.Image, err error) {
+	if _, err = uc.actorRepo.SelectByID(ctx, actorID); err != nil {
+		return
+	}
+	photo, err = uc.storeImage(ctx, "actors/"+strconv.Itoa(actorID)+"/photo-", reader)
+	if err != nil {
+		return
+	}
+	replaced, err := uc.setActorPhoto(ctx, actorID, photo, userID)
+	if err != nil {
+		uc.deleteImage(ctx, photo)
+		return nil, err
+	}
+	uc.deleteImage(ctx, replaced)
+	return
+}
+
+// Delete a photo of an actor.
+func (uc *MediaUsecase) DeleteActorPhoto(ctx *context.Context, actorID int, userID int) (err error) {
+	replaced, err := uc.setActorPhoto(ctx, actorID, nil, userID)
+	if err != nil {
+		return
+	}
+	uc.deleteImage(ctx, replaced)
+	return
+}
+
+// Set or delete a poster of a film and record the change in one transaction.
+// The replaced poster is returned, its files may be deleted only after the transaction is committed.
+func (uc *MediaUsecase) setFilmPoster(ctx *context.Context, filmID int, poster *entity.Image, userID int) (replaced *entity.Image, err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	if err = uc.filmRepo.Lock(ctx, filmID); err != nil {
+		return
+	}
+	before, err := uc.filmRepo.SelectByID(ctx, filmID)
+	if err != nil {
+		return
+	}
+	if poster == nil && before.Poster == nil {
+		err = repo.ErrImageNotFound
+		return
+	}
+	if err = uc.filmRepo.Update(ctx, filmID, map[string]interface{}{"poster": poster}, nil); err != nil {
+		return
+	}
+	after, err := uc.filmRepo.SelectByID(ctx, filmID)
+	if err != nil {
+		return
+	}
+	err = recordRevision(ctx, uc.revisionRepo, entity.RevisionEntityFilm, filmID, entity.RevisionActionUpdate, userID, before, after)
+	replaced = before.Poster
+	return
+}
+
+// Set or delete a photo of an actor and record the change in one transaction.
+// The replaced photo is returned, its files may be deleted only after the transaction is committed.
+func (uc *MediaUsecase) setActorPhoto(ctx *context.Context, actorID int, photo *entity.Image, userID int) (replaced *entity.Image, err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.actorRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	if err = uc.actorRepo.Lock(ctx, actorID); err != nil {
+		return
+	}
+	before, err := uc.actorRepo.SelectByID(ctx, actorID)
+	if err != nil {
+		return
+	}
+	if photo == nil && before.Photo == nil {
+		err = repo.ErrImageNotFound
+		return
+	}
+	if err = uc.actorRepo.Update(ctx, actorID, map[string]interface{}{"photo": photo}, nil); err != nil {
+		return
+	}
+	after, err := uc.actorRepo.SelectByID(ctx, actorID)
+	if err != nil {
+		return
+	}
+	err = recordRevision(ctx, uc.revisionRepo, entity.RevisionEntityActor, actorID, entity.RevisionActionUpdate, userID, before, after)
+	replaced = before.Photo
+	return
+}
+
+// Open a stored image file by its key.
+func (uc *MediaUsecase) OpenFile(ctx *context.Context, key string) (body io.ReadCloser, contentType string, err error) {
+	body, contentType, err = uc.storage.Get(*ctx, key)
+	if errors.Is(err, blobstorage.ErrNotFound) || errors.Is(err, blobstorage.ErrInvalidKey) {
+		err = repo.ErrImageNotFound
+	}
+	return
+}
+
+// Validate an uploaded image, store its original and thumbnails under keys starting with prefix.
+// Nothing is left in storage if any of the files could not be stored.
+func (uc *MediaUsecase) storeImage(ctx *context.Context, prefix string, reader io.Reader) (img *entity.Image, err error) {
+	data, err := io.ReadAll(io.LimitReader(reader, uc.maxUploadSize+1))
+	if err != nil {
+		return
+	}
+	if int64(len(data)) > uc.maxUploadSize {
+		return nil, entity.ErrImageTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	extension, ok := entity.ImageContentTypes[contentType]
+	if !ok {
+		return nil, entity.ErrUnsupportedImageType
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, entity.ErrInvalidImage
+	}
+	if config.Width*config.Height > entity.ImageMaxPixels {
+		return nil, entity.ErrImageTooManyPixels
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, entity.ErrInvalidImage
+	}
+
+	suffix := make([]byte, 8)
+	if _, err = rand.Read(suffix); err != nil {
+		return
+	}
+	prefix += hex.EncodeToString(suffix) + "/"
+	img = &entity.Image{Width: config.Width, Height: config.Height, Thumbnails: map[string]string{}}
+	defer func() {
+		if err != nil {
+			uc.deleteImage(ctx, img)
+			img = nil
+		}
+	}()
+
+	key := prefix + "original." + extension
+	if err = uc.storage.Put(*ctx, key, contentType, data); err != nil {
+		return
+	}
+	img.Keys = append(img.Keys, key)
+	img.URL = uc.storage.URL(key)
+
+	// Thumbnails are scaled from the next bigger one, so the original is scanned only once.
+	thumbnail := flattenImage(decoded)
+	for i := len(entity.ThumbnailSizes) - 1; i >= 0; i-- {
+		size := entity.ThumbnailSizes[i]
+		thumbnail = scaleToFit(thumbnail, size.MaxSide)
+		var encoded bytes.Buffer
+		if err = jpeg.Encode(&encoded, thumbnail, &jpeg.Options{Quality: 85}); err != nil {
+			return
+		}
+		key = prefix + size.Name + ".jpg"
+		if err = uc.storage.Put(*ctx, key, "image/jpeg", encoded.Bytes()); err != nil {
+			return
+		}
+		img.Keys = append(img.Keys, key)
+		img.Thumbnails[size.Name] = uc.storage.URL(key)
+	}
+	return
+}
+
+// Delete all stored files of an image. Failures are ignored, they only leave unreferenced files behind.
+func (uc *MediaUsecase) deleteImage(ctx *context.Context, img *entity.Image) {
+	if img == nil {
+		return
+	}
+	for _, key := range img.Keys {
+		uc.storage.Delete(*ctx, key)
+	}
+}
+
+// Draw an image on white background, so transparent images become opaque for JPEG thumbnails.
+func flattenImage(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Over)
+	return dst
+}
+
+// Scale an image down to fit into a square with maxSide side, averaging source pixels covered by every
+// destination pixel. Images that already fit are returned as is.
+func scaleToFit(src *image.RGBA, maxSide int) *image.RGBA {
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	if srcWidth <= maxSide && srcHeight <= maxSide {
+		return src
+	}
+	width, height := maxSide, max(1, srcHeight*maxSide/srcWidth)
+	if srcHeight > srcWidth {
+		width, height = max(1, srcWidth*maxSide/srcHeight), maxSide
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := y * srcHeight / height
+		y1 := max((y+1)*srcHeight/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := x * srcWidth / width
+			x1 := max((x+1)*srcWidth/width, x0+1)
+			var sum [4]uint64
+			for sy := y0; sy < y1; sy++ {
+				offset := src.PixOffset(src.Rect.Min.X+x0, src.Rect.Min.Y+sy)
+				for sx := x0; sx < x1; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += uint64(src.Pix[offset+c])
+					}
+					offset += 4
+				}
+			}
+			count := uint64((y1 - y0) * (x1 - x0))
+			offset := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[offset+c] = uint8(sum[c] / count)
+			}
+		}
+	}
+	return dst
+}
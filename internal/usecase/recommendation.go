@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+)
+
+// RecommendationRepo interface.
+type RecommendationRepoInterface interface {
+	SelectSimilar(ctx *context.Context, filmID int, pagination *entity.PaginationParams) (films []*entity.FilmRecommendation, err error)
+	SelectByUserID(ctx *context.Context, userID int, pagination *entity.PaginationParams) (films []*entity.FilmRecommendation, err error)
+	SelectPopular(ctx *context.Context, userID int, pagination *entity.PaginationParams) (films []*entity.FilmRecommendation, err error)
+	RefreshSimilarities(ctx *context.Context) (cntPairs int64, err error)
+}
+
+type RecommendationUsecase struct {
+	recommendationRepo RecommendationRepoInterface
+	filmRepo           FilmRepoInterface
+}
+
+// Create new RecommendationUsecase.
+func NewRecommendationUsecase(recommendationRepo RecommendationRepoInterface, filmRepo FilmRepoInterface) *RecommendationUsecase {
+	return &RecommendationUsecase{
+		recommendationRepo: recommendationRepo,
+		filmRepo:           filmRepo,
+	}
+}
+
+// Get a page of films similar to a film by cast and rating.
+func (uc *RecommendationUsecase) GetSimilar(ctx *context.Context, filmID int, pagination *entity.PaginationParams) (films []*entity.FilmRecommendation, err error) {
+	if _, err = uc.filmRepo.SelectByID(ctx, filmID); err != nil {
+		return
+	}
+	films, err = uc.recommendationRepo.SelectSimilar(ctx, filmID, pagination)
+	return
+}
+
+// Get a page of films recommended to a user by their ratings.
+// Users whose ratings give no recommendations get the best rated films instead.
+func (uc *RecommendationUsecase) GetRecommendations(ctx *context.Context, userID int, pagination *entity.PaginationParams) (films []*entity.FilmRecommendation, err error) {
+	films, err = uc.recommendationRepo.SelectByUserID(ctx, userID, pagination)
+	if err != nil || len(films) > 0 {
+		return
+	}
+	if pagination.Offset > 0 {
+		// An empty page past the end of recommendations must not fall back to the best rated films.
+		first, err := uc.recommendationRepo.SelectByUserID(ctx, userID, &entity.PaginationParams{Limit: 1})
+		if err != nil || len(first) > 0 {
+			return nil, err
+		}
+	}
+	films, err = uc.recommendationRepo.SelectPopular(ctx, userID, pagination)
+	return
+}
+
+// Recompute rating-based similarity of all films.
+func (uc *RecommendationUsecase) RefreshSimilarities(ctx *context.Context) (cntPairs int64, err error) {
+	cntPairs, err = uc.recommendationRepo.RefreshSimilarities(ctx)
+	return
+}
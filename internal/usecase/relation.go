@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+)
+
+// RelationRepo interface.
+type RelationRepoInterface interface {
+	SelectByFilmID(ctx *context.Context, filmID int) (relations []*entity.FilmRelation, err error)
+	Insert(ctx *context.Context, filmID, relatedFilmID int, relationType entity.FilmRelationType) (err error)
+	Delete(ctx *context.Context, filmID, relatedFilmID int) (err error)
+}
+
+type RelationUsecase struct {
+	relationRepo RelationRepoInterface
+	filmRepo     FilmRepoInterface
+}
+
+// Create new RelationUsecase.
+func NewRelationUsecase(relationRepo RelationRepoInterface, filmRepo FilmRepoInterface) *RelationUsecase {
+	return &RelationUsecase{
+		relationRepo: relationRepo,
+		filmRepo:     filmRepo,
+	}
+}
+
+// Relate a film to another film. Prequels are stored as inverse sequels.
+func (uc *RelationUsecase) Create(ctx *context.Context, filmID int, body *entity.FilmRelationBody) (relation *entity.FilmRelation, err error) {
+	if body.FilmID == filmID {
+		err = entity.ErrSelfFilmRelation
+		return
+	}
+
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	if body.Type == entity.FilmRelationPrequelOf {
+		err = uc.relationRepo.Insert(ctx, body.FilmID, filmID, body.Type.Inverse())
+	} else {
+		err = uc.relationRepo.Insert(ctx, filmID, body.FilmID, body.Type)
+	}
+	if err != nil {
+		return
+	}
+	related, err := uc.filmRepo.SelectByID(ctx, body.FilmID)
+	if err != nil {
+		return
+	}
+	relation = &entity.FilmRelation{
+		Type:        body.Type,
+		FilmID:      related.ID,
+		Title:       related.Title,
+		ReleaseDate: related.ReleaseDate,
+	}
+	return
+}
+
+// Delete the relation between two films.
+func (uc *RelationUsecase) Delete(ctx *context.Context, filmID, relatedFilmID int) (err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	err = uc.relationRepo.Delete(ctx, filmID, relatedFilmID)
+	return
+}
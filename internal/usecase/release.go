@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+)
+
+// ReleaseRepo interface.
+type ReleaseRepoInterface interface {
+	SelectByFilmsIDs(ctx *context.Context, filmsIDs []int, country string) (infos map[int]*entity.FilmReleaseInfo, err error)
+	Replace(ctx *context.Context, filmID int, info *entity.FilmReleaseInfo) (err error)
+}
+
+type ReleaseUsecase struct {
+	releaseRepo ReleaseRepoInterface
+	filmRepo    FilmRepoInterface
+}
+
+// Create new ReleaseUsecase.
+func NewReleaseUsecase(releaseRepo ReleaseRepoInterface, filmRepo FilmRepoInterface) *ReleaseUsecase {
+	return &ReleaseUsecase{
+		releaseRepo: releaseRepo,
+		filmRepo:    filmRepo,
+	}
+}
+
+// Get release dates and certifications of a film in all countries.
+func (uc *ReleaseUsecase) GetFilmReleaseInfo(ctx *context.Context, filmID int) (info *entity.FilmReleaseInfo, err error) {
+	if _, err = uc.filmRepo.SelectByID(ctx, filmID); err != nil {
+		return
+	}
+	infos, err := uc.releaseRepo.SelectByFilmsIDs(ctx, []int{filmID}, "")
+	if err != nil {
+		return
+	}
+	info, ok := infos[filmID]
+	if !ok {
+		info = &entity.FilmReleaseInfo{Releases: []*entity.FilmRelease{}, Certifications: []*entity.FilmCertification{}}
+	}
+	return
+}
+
+// Replace release dates and certifications of a film in all countries.
+func (uc *ReleaseUsecase) ReplaceFilmReleaseInfo(ctx *context.Context, filmID int, info *entity.FilmReleaseInfo) (err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	err = uc.releaseRepo.Replace(ctx, filmID, info)
+	return
+}
+
+// Add releases and certifications to films, only in the country if it is not empty.
+func attachReleaseInfo(ctx *context.Context, releaseRepo ReleaseRepoInterface, films []*entity.FilmWithActors, country string) (err error) {
+	if len(films) == 0 {
+		return
+	}
+	filmsIDs := make([]int, len(films))
+	for i, film := range films {
+		filmsIDs[i] = film.ID
+	}
+	infos, err := releaseRepo.SelectByFilmsIDs(ctx, filmsIDs, country)
+	if err != nil {
+		return
+	}
+	for _, film := range films {
+		if info, ok := infos[film.ID]; ok {
+			film.Releases = info.Releases
+			film.Certifications = info.Certifications
+		}
+	}
+	return
+}
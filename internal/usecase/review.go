@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+)
+
+// ReviewRepo interface.
+type ReviewRepoInterface interface {
+	Insert(ctx *context.Context, receivedReview *entity.Review) (createdReview *entity.Review, err error)
+	Update(ctx *context.Context, filmID, userID int, fields map[string]interface{}) (err error)
+	Delete(ctx *context.Context, filmID, userID int) (err error)
+	SelectByFilmID(ctx *context.Context, filmID int, pagination *entity.PaginationParams) (reviews []*entity.Review, err error)
+	SelectByFilmIDAndUserID(ctx *context.Context, filmID, userID int) (review *entity.Review, err error)
+}
+
+type ReviewUsecase struct {
+	reviewRepo ReviewRepoInterface
+	filmRepo   FilmRepoInterface
+}
+
+// Create new ReviewUsecase.
+func NewReviewUsecase(reviewRepo ReviewRepoInterface, filmRepo FilmRepoInterface) *ReviewUsecase {
+	return &ReviewUsecase{reviewRepo, filmRepo}
+}
+
+// Create a user's review of a film.
+func (uc *ReviewUsecase) Create(ctx *context.Context, filmID, userID int, body *entity.ReviewCreateBody) (review *entity.Review, err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	reviewToCreate := &entity.Review{
+		FilmID: filmID,
+		UserID: userID,
+		Score:  *body.Score,
+		Text:   body.Text,
+	}
+	review, err = uc.reviewRepo.Insert(ctx, reviewToCreate)
+	return
+}
+
+// Update a user's review of a film.
+func (uc *ReviewUsecase) Update(ctx *context.Context, filmID, userID int, body *entity.ReviewUpdateBody) (review *entity.Review, err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	fields := map[string]interface{}{}
+	if body.Score != nil {
+		fields["score"] = *body.Score
+	}
+	if body.Text != nil {
+		fields["text"] = *body.Text
+	}
+	if len(fields) > 0 {
+		err = uc.reviewRepo.Update(ctx, filmID, userID, fields)
+		if err != nil {
+			return
+		}
+	}
+	review, err = uc.reviewRepo.SelectByFilmIDAndUserID(ctx, filmID, userID)
+	return
+}
+
+// Delete a user's review of a film.
+func (uc *ReviewUsecase) Delete(ctx *context.Context, filmID, userID int) (err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	err = uc.reviewRepo.Delete(ctx, filmID, userID)
+	return
+}
+
+// Get a page of film's reviews.
+func (uc *ReviewUsecase) GetByFilmID(ctx *context.Context, filmID int, pagination *entity.PaginationParams) (reviews []*entity.Review, err error) {
+	reviews, err = uc.reviewRepo.SelectByFilmID(ctx, filmID, pagination)
+	return
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+	repo "github.com/itmosha/vk-internship-2024/internal/repo/postgres"
+	"github.com/itmosha/vk-internship-2024/pkg/postgres"
+)
+
+// RevisionRepo interface.
+type RevisionRepoInterface interface {
+	Insert(ctx *context.Context, receivedRevision *entity.Revision) (createdRevision *entity.Revision, err error)
+	SelectByEntity(ctx *context.Context, entityType string, entityID int, pagination *entity.PaginationParams) (revisions []*entity.Revision, err error)
+	SelectByID(ctx *context.Context, entityType string, entityID int, id int64) (revision *entity.Revision, err error)
+	SelectLatestAt(ctx *context.Context, entityType string, entityID int, at time.Time) (revision *entity.Revision, err error)
+	SelectEarliest(ctx *context.Context, entityType string, entityID int) (revision *entity.Revision, err error)
+}
+
+// Repo of entities which changes are recorded, it starts transactions shared by the change and its revision.
+type TransactionRepoInterface interface {
+	NewTransaction(ctx *context.Context) (tx *sql.Tx, err error)
+}
+
+// Fields derived from other data or changed on every write, they are not recorded in revisions.
+var unrecordedFields = []string{"version", "user_rating_avg", "user_rating_count"}
+
+// Start a transaction, repo calls made with txCtx are part of it.
+func beginTransaction(ctx *context.Context, txRepo TransactionRepoInterface) (tx *sql.Tx, txCtx *context.Context, err error) {
+	tx, err = txRepo.NewTransaction(ctx)
+	if err != nil {
+		return
+	}
+	withTx := postgres.WithTx(*ctx, tx)
+	txCtx = &withTx
+	return
+}
+
+// Commit a transaction if there is no error and roll it back otherwise, meant to be deferred.
+func endTransaction(tx *sql.Tx, err *error) {
+	if *err != nil {
+		tx.Rollback()
+		return
+	}
+	*err = tx.Commit()
+}
+
+// Record a change of an entity. Before is nil for created entities and after is nil for deleted ones.
+// Updates that changed nothing are not recorded.
+func recordRevision(ctx *context.Context, revisionRepo RevisionRepoInterface, entityType string, entityID int, action string, userID int, before, after interface{}) (err error) {
+	beforeFields, err := toFieldsMap(before)
+	if err != nil {
+		return
+	}
+	afterFields, err := toFieldsMap(after)
+	if err != nil {
+		return
+	}
+	diff := map[string]entity.FieldChange{}
+	for field, oldValue := range beforeFields {
+		if newValue := afterFields[field]; !reflect.DeepEqual(oldValue, newValue) {
+			diff[field] = entity.FieldChange{Old: oldValue, New: newValue}
+		}
+	}
+	for field, newValue := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			diff[field] = entity.FieldChange{Old: nil, New: newValue}
+		}
+	}
+	if len(diff) == 0 && action == entity.RevisionActionUpdate {
+		return
+	}
+
+	snapshotFields := afterFields
+	if after == nil || reflect.ValueOf(after).IsNil() {
+		snapshotFields = beforeFields
+	}
+	snapshot, err := json.Marshal(snapshotFields)
+	if err != nil {
+		return
+	}
+	revision := &entity.Revision{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Diff:       diff,
+		Snapshot:   snapshot,
+	}
+	if userID != 0 {
+		revision.ChangedBy = &userID
+	}
+	_, err = revisionRepo.Insert(ctx, revision)
+	return
+}
+
+// Get the snapshot of an entity as it was at provided time.
+// Entities created before history was recorded have no create revision, so their state before the first recorded change
+// is restored from its diff, and snapshot is nil for ones that were not changed since, meaning their current state.
+// ErrRevisionNotFound is returned if the entity did not exist or was in trash at that time.
+func snapshotAt(ctx *context.Context, revisionRepo RevisionRepoInterface, entityType string, entityID int, at time.Time) (snapshot json.RawMessage, err error) {
+	revision, err := revisionRepo.SelectLatestAt(ctx, entityType, entityID, at)
+	if err == nil {
+		if revision.Action == entity.RevisionActionDelete {
+			err = repo.ErrRevisionNotFound
+			return
+		}
+		snapshot = revision.Snapshot
+		return
+	}
+	if !errors.Is(err, repo.ErrRevisionNotFound) {
+		return
+	}
+	revision, err = revisionRepo.SelectEarliest(ctx, entityType, entityID)
+	if errors.Is(err, repo.ErrRevisionNotFound) {
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+	// Id is changed only by revisions of entities that did not exist or were in trash before.
+	if _, ok := revision.Diff["id"]; ok && revision.Action != entity.RevisionActionDelete {
+		err = repo.ErrRevisionNotFound
+		return
+	}
+	fields := map[string]interface{}{}
+	if err = json.Unmarshal(revision.Snapshot, &fields); err != nil {
+		return
+	}
+	for field, change := range revision.Diff {
+		fields[field] = change.Old
+	}
+	snapshot, err = json.Marshal(fields)
+	return
+}
+
+// Convert an entity to a map of its recorded JSON fields, nil entities give an empty map.
+func toFieldsMap(value interface{}) (fields map[string]interface{}, err error) {
+	fields = map[string]interface{}{}
+	if value == nil || reflect.ValueOf(value).IsNil() {
+		return
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(raw, &fields)
+	for _, field := range unrecordedFields {
+		delete(fields, field)
+	}
+	return
+}
@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+)
+
+// SearchRepo interface.
+type SearchRepoInterface interface {
+	SelectSuggestions(ctx *context.Context, query string, limit int) (suggestions []*entity.Suggestion, err error)
+}
+
+type SearchUsecase struct {
+	searchRepo SearchRepoInterface
+}
+
+// Create new SearchUsecase.
+func NewSearchUsecase(searchRepo SearchRepoInterface) *SearchUsecase {
+	return &SearchUsecase{searchRepo}
+}
+
+// Get films and actors matching the query despite typos.
+func (uc *SearchUsecase) Suggest(ctx *context.Context, query string, limit int) (suggestions []*entity.Suggestion, err error) {
+	suggestions, err = uc.searchRepo.SelectSuggestions(ctx, strings.TrimSpace(query), limit)
+	return
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+)
+
+// StatsRepo interface.
+type StatsRepoInterface interface {
+	SelectFilmsPerYear(ctx *context.Context, params *entity.StatsParams) (report *entity.StatsReport, err error)
+	SelectRatingDistribution(ctx *context.Context, params *entity.StatsParams) (report *entity.StatsReport, err error)
+	SelectProlificActors(ctx *context.Context, params *entity.StatsParams) (report *entity.StatsReport, err error)
+	SelectCastSize(ctx *context.Context, params *entity.StatsParams) (report *entity.StatsReport, err error)
+	SelectActorsByBirthDecade(ctx *context.Context, params *entity.StatsParams) (report *entity.StatsReport, err error)
+	SelectFilmsWithoutCast(ctx *context.Context, params *entity.StatsParams) (report *entity.StatsReport, err error)
+	Refresh(ctx *context.Context) (err error)
+}
+
+type StatsUsecase struct {
+	statsRepo StatsRepoInterface
+}
+
+// Create new StatsUsecase.
+func NewStatsUsecase(statsRepo StatsRepoInterface) *StatsUsecase {
+	return &StatsUsecase{statsRepo: statsRepo}
+}
+
+// Get a stats report by name.
+func (uc *StatsUsecase) GetReport(ctx *context.Context, name string, params *entity.StatsParams) (report *entity.StatsReport, err error) {
+	switch name {
+	case entity.StatsFilmsPerYear:
+		report, err = uc.statsRepo.SelectFilmsPerYear(ctx, params)
+	case entity.StatsRatingDistribution:
+		report, err = uc.statsRepo.SelectRatingDistribution(ctx, params)
+	case entity.StatsProlificActors:
+		report, err = uc.statsRepo.SelectProlificActors(ctx, params)
+	case entity.StatsCastSize:
+		report, err = uc.statsRepo.SelectCastSize(ctx, params)
+	case entity.StatsActorsByBirthDecade:
+		report, err = uc.statsRepo.SelectActorsByBirthDecade(ctx, params)
+	case entity.StatsFilmsWithoutCast:
+		report, err = uc.statsRepo.SelectFilmsWithoutCast(ctx, params)
+	default:
+		err = entity.ErrUnknownStatsReport
+	}
+	return
+}
+
+// Refresh aggregates of stats reports.
+func (uc *StatsUsecase) Refresh(ctx *context.Context) (err error) {
+	err = uc.statsRepo.Refresh(ctx)
+	return
+}
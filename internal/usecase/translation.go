@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+)
+
+// TranslationRepo interface.
+type TranslationRepoInterface interface {
+	SelectByFilmID(ctx *context.Context, filmID int) (translations []*entity.FilmTranslation, err error)
+	SelectPreferredByFilmsIDs(ctx *context.Context, filmsIDs []int, locales []string) (translations map[int]*entity.FilmTranslation, err error)
+	UpsertFilmTranslation(ctx *context.Context, filmID int, translation *entity.FilmTranslation) (err error)
+	DeleteFilmTranslation(ctx *context.Context, filmID int, locale string) (err error)
+	SelectByActorID(ctx *context.Context, actorID int) (translations []*entity.ActorTranslation, err error)
+	SelectPreferredByActorsIDs(ctx *context.Context, actorsIDs []int, locales []string) (translations map[int]*entity.ActorTranslation, err error)
+	UpsertActorTranslation(ctx *context.Context, actorID int, translation *entity.ActorTranslation) (err error)
+	DeleteActorTranslation(ctx *context.Context, actorID int, locale string) (err error)
+}
+
+type TranslationUsecase struct {
+	translationRepo TranslationRepoInterface
+	filmRepo        FilmRepoInterface
+	actorRepo       ActorRepoInterface
+}
+
+// Create new TranslationUsecase.
+func NewTranslationUsecase(translationRepo TranslationRepoInterface, filmRepo FilmRepoInterface, actorRepo ActorRepoInterface) *TranslationUsecase {
+	return &TranslationUsecase{
+		translationRepo: translationRepo,
+		filmRepo:        filmRepo,
+		actorRepo:       actorRepo,
+	}
+}
+
+// Get all translations of a film.
+func (uc *TranslationUsecase) GetFilmTranslations(ctx *context.Context, filmID int) (translations []*entity.FilmTranslation, err error) {
+	if _, err = uc.filmRepo.SelectByID(ctx, filmID); err != nil {
+		return
+	}
+	translations, err = uc.translationRepo.SelectByFilmID(ctx, filmID)
+	return
+}
+
+// Create or replace a translation of a film to a locale.
+func (uc *TranslationUsecase) PutFilmTranslation(ctx *context.Context, filmID int, locale string, body *entity.FilmTranslationBody) (translation *entity.FilmTranslation, err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	translation = &entity.FilmTranslation{Locale: locale, Title: body.Title, Description: body.Description}
+	err = uc.translationRepo.UpsertFilmTranslation(ctx, filmID, translation)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Delete a translation of a film to a locale.
+func (uc *TranslationUsecase) DeleteFilmTranslation(ctx *context.Context, filmID int, locale string) (err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.filmRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	err = uc.translationRepo.DeleteFilmTranslation(ctx, filmID, locale)
+	return
+}
+
+// Get all translations of an actor.
+func (uc *TranslationUsecase) GetActorTranslations(ctx *context.Context, actorID int) (translations []*entity.ActorTranslation, err error) {
+	if _, err = uc.actorRepo.SelectByID(ctx, actorID); err != nil {
+		return
+	}
+	translations, err = uc.translationRepo.SelectByActorID(ctx, actorID)
+	return
+}
+
+// Create or replace a translation of an actor to a locale.
+func (uc *TranslationUsecase) PutActorTranslation(ctx *context.Context, actorID int, locale string, body *entity.ActorTranslationBody) (translation *entity.ActorTranslation, err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.actorRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	translation = &entity.ActorTranslation{Locale: locale, Name: body.Name}
+	err = uc.translationRepo.UpsertActorTranslation(ctx, actorID, translation)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Delete a translation of an actor to a locale.
+func (uc *TranslationUsecase) DeleteActorTranslation(ctx *context.Context, actorID int, locale string) (err error) {
+	tx, ctx, err := beginTransaction(ctx, uc.actorRepo)
+	if err != nil {
+		return
+	}
+	defer endTransaction(tx, &err)
+
+	err = uc.translationRepo.DeleteActorTranslation(ctx, actorID, locale)
+	return
+}
+
+// Replace titles and descriptions of films with their translations to the most preferred available locale.
+// Films without such translations and empty translated descriptions are left original.
+func translateFilms(ctx *context.Context, translationRepo TranslationRepoInterface, films []*entity.FilmWithActors, locales []string) (err error) {
+	if len(films) == 0 || len(locales) == 0 {
+		return
+	}
+	filmsIDs := make([]int, len(films))
+	for i, film := range films {
+		filmsIDs[i] = film.ID
+	}
+	translations, err := translationRepo.SelectPreferredByFilmsIDs(ctx, filmsIDs, locales)
+	if err != nil {
+		return
+	}
+	for _, film := range films {
+		if translation, ok := translations[film.ID]; ok {
+			film.Title = translation.Title
+			if translation.Description != "" {
+				film.Description = translation.Description
+			}
+			film.Locale = translation.Locale
+		}
+	}
+	return
+}
+
+// Replace names of actors with their translations to the most preferred available locale.
+func translateActors(ctx *context.Context, translationRepo TranslationRepoInterface, actors []*entity.Actor, locales []string) (err error) {
+	if len(actors) == 0 || len(locales) == 0 {
+		return
+	}
+	actorsIDs := make([]int, len(actors))
+	for i, actor := range actors {
+		actorsIDs[i] = actor.ID
+	}
+	translations, err := translationRepo.SelectPreferredByActorsIDs(ctx, actorsIDs, locales)
+	if err != nil {
+		return
+	}
+	for _, actor := range actors {
+		if translation, ok := translations[actor.ID]; ok {
+			actor.Name = translation.Name
+			actor.Locale = translation.Locale
+		}
+	}
+	return
+}
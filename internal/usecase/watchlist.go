@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/itmosha/vk-internship-2024/internal/entity"
+)
+
+// WatchlistRepo interface.
+type WatchlistRepoInterface interface {
+	InsertToWatchlist(ctx *context.Context, userID, filmID int) (entry *entity.WatchlistEntry, err error)
+	DeleteFromWatchlist(ctx *context.Context, userID, filmID int) (err error)
+	SelectWatchlist(ctx *context.Context, userID int) (entries []*entity.WatchlistEntry, err error)
+	InsertToWatched(ctx *context.Context, userID int, receivedEntry *entity.WatchedEntry) (entry *entity.WatchedEntry, err error)
+	DeleteFromWatched(ctx *context.Context, userID, filmID int, watchedOn string) (err error)
+	SelectWatched(ctx *context.Context, userID int) (entries []*entity.WatchedEntry, err error)
+	SelectStats(ctx *context.Context, userID int) (stats *entity.UserStats, err error)
+}
+
+type WatchlistUsecase struct {
+	watchlistRepo WatchlistRepoInterface
+}
+
+// Create new WatchlistUsecase.
+func NewWatchlistUsecase(watchlistRepo WatchlistRepoInterface) *WatchlistUsecase {
+	return &WatchlistUsecase{watchlistRepo}
+}
+
+// Add a film to user's watchlist.
+func (uc *WatchlistUsecase) AddToWatchlist(ctx *context.Context, userID int, body *entity.WatchlistAddBody) (entry *entity.WatchlistEntry, err error) {
+	entry, err = uc.watchlistRepo.InsertToWatchlist(ctx, userID, body.FilmID)
+	return
+}
+
+// Remove a film from user's watchlist.
+func (uc *WatchlistUsecase) RemoveFromWatchlist(ctx *context.Context, userID, filmID int) (err error) {
+	err = uc.watchlistRepo.DeleteFromWatchlist(ctx, userID, filmID)
+	return
+}
+
+// Get user's watchlist.
+func (uc *WatchlistUsecase) GetWatchlist(ctx *context.Context, userID int) (entries []*entity.WatchlistEntry, err error) {
+	entries, err = uc.watchlistRepo.SelectWatchlist(ctx, userID)
+	return
+}
+
+// Mark a film as watched on a date.
+func (uc *WatchlistUsecase) AddToWatched(ctx *context.Context, userID int, body *entity.WatchedAddBody) (entry *entity.WatchedEntry, err error) {
+	watchedOn, err := entity.ParseFullDate(body.WatchedOn)
+	if err != nil {
+		return
+	}
+	entryToCreate := &entity.WatchedEntry{
+		FilmID:    body.FilmID,
+		WatchedOn: watchedOn,
+	}
+	entry, err = uc.watchlistRepo.InsertToWatched(ctx, userID, entryToCreate)
+	return
+}
+
+// Remove a film from user's watched list.
+func (uc *WatchlistUsecase) RemoveFromWatched(ctx *context.Context, userID, filmID int, watchedOn string) (err error) {
+	err = uc.watchlistRepo.DeleteFromWatched(ctx, userID, filmID, watchedOn)
+	return
+}
+
+// Get user's watched list.
+func (uc *WatchlistUsecase) GetWatched(ctx *context.Context, userID int) (entries []*entity.WatchedEntry, err error) {
+	entries, err = uc.watchlistRepo.SelectWatched(ctx, userID)
+	return
+}
+
+// Get user's watching stats.
+func (uc *WatchlistUsecase) GetStats(ctx *context.Context, userID int) (stats *entity.UserStats, err error) {
+	stats, err = uc.watchlistRepo.SelectStats(ctx, userID)
+	return
+}
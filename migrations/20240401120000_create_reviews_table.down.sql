@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS review;
+
+ALTER TABLE film
+    DROP COLUMN IF EXISTS user_rating_avg,
+    DROP COLUMN IF EXISTS user_rating_count;
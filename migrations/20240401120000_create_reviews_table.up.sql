@@ -0,0 +1,16 @@
+ALTER TABLE film
+    ADD COLUMN IF NOT EXISTS user_rating_avg NUMERIC(4, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS user_rating_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS review (
+    id SERIAL PRIMARY KEY,
+    film_id INTEGER NOT NULL REFERENCES film(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    score INTEGER NOT NULL CHECK (score BETWEEN 0 AND 10),
+    text VARCHAR(5000) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (film_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS review_film_id_created_at_idx ON review (film_id, created_at DESC);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS watchlist (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    film_id INTEGER NOT NULL REFERENCES film(id) ON DELETE CASCADE,
+    added_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, film_id)
+);
+
+CREATE TABLE IF NOT EXISTS watched (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    film_id INTEGER NOT NULL REFERENCES film(id) ON DELETE CASCADE,
+    watched_on DATE NOT NULL,
+    PRIMARY KEY (user_id, film_id, watched_on)
+);
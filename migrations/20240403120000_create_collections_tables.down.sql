@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS collections_films;
+DROP TABLE IF EXISTS collection;
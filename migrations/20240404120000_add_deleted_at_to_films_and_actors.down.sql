@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS actor_deleted_at_idx;
+DROP INDEX IF EXISTS film_deleted_at_idx;
+
+DELETE FROM actor WHERE deleted_at IS NOT NULL;
+DELETE FROM film WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE actor DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE film DROP COLUMN IF EXISTS deleted_at;
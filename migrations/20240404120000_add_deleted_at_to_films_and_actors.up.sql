@@ -0,0 +1,5 @@
+ALTER TABLE film ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE actor ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS film_deleted_at_idx ON film (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS actor_deleted_at_idx ON actor (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS revision (
+    id BIGSERIAL PRIMARY KEY,
+    entity_type VARCHAR(20) NOT NULL CHECK (entity_type IN ('film', 'actor')),
+    entity_id INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('create', 'update', 'delete', 'restore', 'revert')),
+    changed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    diff JSONB NOT NULL DEFAULT '{}',
+    snapshot JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS revision_entity_idx ON revision (entity_type, entity_id, changed_at DESC);
@@ -0,0 +1,2 @@
+ALTER TABLE film ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE actor ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
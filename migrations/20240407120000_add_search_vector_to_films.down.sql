@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS film_search_vector_idx;
+
+DROP TRIGGER IF EXISTS actor_search_vector_update ON actor;
+DROP TRIGGER IF EXISTS films_actors_search_vector_update ON films_actors;
+DROP TRIGGER IF EXISTS film_search_vector_update ON film;
+
+DROP FUNCTION IF EXISTS actor_search_vector_update();
+DROP FUNCTION IF EXISTS films_actors_search_vector_update();
+DROP FUNCTION IF EXISTS film_search_vector_update();
+DROP FUNCTION IF EXISTS film_cast_names(INTEGER);
+DROP FUNCTION IF EXISTS film_search_vector(TEXT, TEXT, TEXT);
+
+ALTER TABLE film DROP COLUMN IF EXISTS search_vector;
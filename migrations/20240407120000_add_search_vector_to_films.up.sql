@@ -0,0 +1,65 @@
+ALTER TABLE film ADD COLUMN IF NOT EXISTS search_vector TSVECTOR NOT NULL DEFAULT ''::TSVECTOR;
+
+-- Title, description and cast names are indexed with both English and Russian configurations.
+CREATE OR REPLACE FUNCTION film_search_vector(p_title TEXT, p_description TEXT, p_cast TEXT) RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector('english', p_title), 'A') || setweight(to_tsvector('russian', p_title), 'A') ||
+        setweight(to_tsvector('english', p_description), 'B') || setweight(to_tsvector('russian', p_description), 'B') ||
+        setweight(to_tsvector('english', p_cast), 'C') || setweight(to_tsvector('russian', p_cast), 'C');
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION film_cast_names(p_film_id INTEGER) RETURNS TEXT AS $$
+    SELECT COALESCE(STRING_AGG(a.name, ' '), '')
+    FROM films_actors fa
+    JOIN actor a ON a.id = fa.actor_id AND a.deleted_at IS NULL
+    WHERE fa.film_id = p_film_id;
+$$ LANGUAGE SQL STABLE;
+
+CREATE OR REPLACE FUNCTION film_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := film_search_vector(NEW.title, NEW.description, film_cast_names(NEW.id));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION films_actors_search_vector_update() RETURNS TRIGGER AS $$
+DECLARE
+    changed_film_id INTEGER;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_film_id := OLD.film_id;
+    ELSE
+        changed_film_id := NEW.film_id;
+    END IF;
+    UPDATE film
+    SET search_vector = film_search_vector(title, description, film_cast_names(id))
+    WHERE id = changed_film_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION actor_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE film
+    SET search_vector = film_search_vector(title, description, film_cast_names(id))
+    WHERE id IN (SELECT film_id FROM films_actors WHERE actor_id = NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER film_search_vector_update
+    BEFORE INSERT OR UPDATE OF title, description ON film
+    FOR EACH ROW EXECUTE FUNCTION film_search_vector_update();
+
+CREATE TRIGGER films_actors_search_vector_update
+    AFTER INSERT OR DELETE ON films_actors
+    FOR EACH ROW EXECUTE FUNCTION films_actors_search_vector_update();
+
+CREATE TRIGGER actor_search_vector_update
+    AFTER UPDATE OF name, deleted_at ON actor
+    FOR EACH ROW
+    WHEN (OLD.name IS DISTINCT FROM NEW.name OR OLD.deleted_at IS DISTINCT FROM NEW.deleted_at)
+    EXECUTE FUNCTION actor_search_vector_update();
+
+UPDATE film SET search_vector = film_search_vector(title, description, film_cast_names(id));
+
+CREATE INDEX IF NOT EXISTS film_search_vector_idx ON film USING GIN (search_vector);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS actor_name_trgm_idx;
+DROP INDEX IF EXISTS film_title_trgm_idx;
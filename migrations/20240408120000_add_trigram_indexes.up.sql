@@ -0,0 +1,4 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS film_title_trgm_idx ON film USING GIN (title gin_trgm_ops) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS actor_name_trgm_idx ON actor USING GIN (name gin_trgm_ops) WHERE deleted_at IS NULL;
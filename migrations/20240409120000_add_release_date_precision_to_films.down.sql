@@ -0,0 +1 @@
+ALTER TABLE film DROP COLUMN IF EXISTS release_date_precision;
@@ -0,0 +1,3 @@
+ALTER TABLE film
+    ADD COLUMN IF NOT EXISTS release_date_precision VARCHAR(5) NOT NULL DEFAULT 'day'
+        CHECK (release_date_precision IN ('day', 'month', 'year'));
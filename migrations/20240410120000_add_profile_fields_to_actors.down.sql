@@ -0,0 +1,10 @@
+ALTER TABLE actor
+    DROP CONSTRAINT IF EXISTS actor_death_date_check,
+    DROP COLUMN IF EXISTS external_ids,
+    DROP COLUMN IF EXISTS aliases,
+    DROP COLUMN IF EXISTS nationality,
+    DROP COLUMN IF EXISTS birthplace,
+    DROP COLUMN IF EXISTS biography,
+    DROP COLUMN IF EXISTS death_date,
+    DROP CONSTRAINT IF EXISTS actor_gender_check,
+    ALTER COLUMN gender TYPE BOOLEAN USING gender = 'male';
@@ -0,0 +1,10 @@
+ALTER TABLE actor
+    ALTER COLUMN gender TYPE VARCHAR(20) USING CASE WHEN gender THEN 'male' ELSE 'female' END,
+    ADD CONSTRAINT actor_gender_check CHECK (gender IN ('male', 'female', 'non_binary', 'unknown')),
+    ADD COLUMN IF NOT EXISTS death_date DATE,
+    ADD COLUMN IF NOT EXISTS biography TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS birthplace VARCHAR(150) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS nationality VARCHAR(2) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS aliases VARCHAR(100)[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS external_ids JSONB NOT NULL DEFAULT '{}',
+    ADD CONSTRAINT actor_death_date_check CHECK (death_date IS NULL OR death_date >= birth_date);
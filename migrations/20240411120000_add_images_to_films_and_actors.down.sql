@@ -0,0 +1,2 @@
+ALTER TABLE actor DROP COLUMN IF EXISTS photo;
+ALTER TABLE film DROP COLUMN IF EXISTS poster;
@@ -0,0 +1,2 @@
+ALTER TABLE film ADD COLUMN IF NOT EXISTS poster JSONB;
+ALTER TABLE actor ADD COLUMN IF NOT EXISTS photo JSONB;
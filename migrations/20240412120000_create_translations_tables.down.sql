@@ -0,0 +1,51 @@
+DROP TRIGGER IF EXISTS actor_translation_search_vector_update ON actor_translation;
+DROP TRIGGER IF EXISTS film_translation_search_vector_update ON film_translation;
+
+DROP FUNCTION IF EXISTS actor_translation_search_vector_update();
+DROP FUNCTION IF EXISTS film_translation_search_vector_update();
+
+CREATE OR REPLACE FUNCTION film_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := film_search_vector(NEW.title, NEW.description, film_cast_names(NEW.id));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION films_actors_search_vector_update() RETURNS TRIGGER AS $$
+DECLARE
+    changed_film_id INTEGER;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_film_id := OLD.film_id;
+    ELSE
+        changed_film_id := NEW.film_id;
+    END IF;
+    UPDATE film
+    SET search_vector = film_search_vector(title, description, film_cast_names(id))
+    WHERE id = changed_film_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION actor_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE film
+    SET search_vector = film_search_vector(title, description, film_cast_names(id))
+    WHERE id IN (SELECT film_id FROM films_actors WHERE actor_id = NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS film_translated_search_vector(INTEGER, TEXT, TEXT);
+
+DROP TABLE IF EXISTS actor_translation;
+DROP TABLE IF EXISTS film_translation;
+
+CREATE OR REPLACE FUNCTION film_cast_names(p_film_id INTEGER) RETURNS TEXT AS $$
+    SELECT COALESCE(STRING_AGG(a.name, ' '), '')
+    FROM films_actors fa
+    JOIN actor a ON a.id = fa.actor_id AND a.deleted_at IS NULL
+    WHERE fa.film_id = p_film_id;
+$$ LANGUAGE SQL STABLE;
+
+UPDATE film SET search_vector = film_search_vector(title, description, film_cast_names(id));
@@ -0,0 +1,111 @@
+CREATE TABLE IF NOT EXISTS film_translation (
+    film_id INTEGER NOT NULL REFERENCES film(id) ON DELETE CASCADE,
+    locale VARCHAR(35) NOT NULL,
+    title VARCHAR(150) NOT NULL,
+    description VARCHAR(1000) NOT NULL DEFAULT '',
+    PRIMARY KEY (film_id, locale)
+);
+
+CREATE TABLE IF NOT EXISTS actor_translation (
+    actor_id INTEGER NOT NULL REFERENCES actor(id) ON DELETE CASCADE,
+    locale VARCHAR(35) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    PRIMARY KEY (actor_id, locale)
+);
+
+CREATE INDEX IF NOT EXISTS film_translation_title_trgm_idx ON film_translation USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS actor_translation_name_trgm_idx ON actor_translation USING GIN (name gin_trgm_ops);
+
+-- Translated titles, descriptions and cast names are indexed together with the original ones.
+CREATE OR REPLACE FUNCTION film_cast_names(p_film_id INTEGER) RETURNS TEXT AS $$
+    SELECT COALESCE(STRING_AGG(names.name, ' '), '')
+    FROM films_actors fa
+    JOIN actor a ON a.id = fa.actor_id AND a.deleted_at IS NULL
+    CROSS JOIN LATERAL (
+        SELECT a.name
+        UNION ALL
+        SELECT t.name FROM actor_translation t WHERE t.actor_id = a.id
+    ) names
+    WHERE fa.film_id = p_film_id;
+$$ LANGUAGE SQL STABLE;
+
+CREATE OR REPLACE FUNCTION film_translated_search_vector(p_film_id INTEGER, p_title TEXT, p_description TEXT) RETURNS TSVECTOR AS $$
+    SELECT film_search_vector(
+        p_title || ' ' || COALESCE(STRING_AGG(ft.title, ' '), ''),
+        p_description || ' ' || COALESCE(STRING_AGG(ft.description, ' '), ''),
+        film_cast_names(p_film_id))
+    FROM film_translation ft
+    WHERE ft.film_id = p_film_id;
+$$ LANGUAGE SQL STABLE;
+
+CREATE OR REPLACE FUNCTION film_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := film_translated_search_vector(NEW.id, NEW.title, NEW.description);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION films_actors_search_vector_update() RETURNS TRIGGER AS $$
+DECLARE
+    changed_film_id INTEGER;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_film_id := OLD.film_id;
+    ELSE
+        changed_film_id := NEW.film_id;
+    END IF;
+    UPDATE film
+    SET search_vector = film_translated_search_vector(id, title, description)
+    WHERE id = changed_film_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION actor_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE film
+    SET search_vector = film_translated_search_vector(id, title, description)
+    WHERE id IN (SELECT film_id FROM films_actors WHERE actor_id = NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION film_translation_search_vector_update() RETURNS TRIGGER AS $$
+DECLARE
+    changed_film_id INTEGER;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_film_id := OLD.film_id;
+    ELSE
+        changed_film_id := NEW.film_id;
+    END IF;
+    UPDATE film
+    SET search_vector = film_translated_search_vector(id, title, description)
+    WHERE id = changed_film_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION actor_translation_search_vector_update() RETURNS TRIGGER AS $$
+DECLARE
+    changed_actor_id INTEGER;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_actor_id := OLD.actor_id;
+    ELSE
+        changed_actor_id := NEW.actor_id;
+    END IF;
+    UPDATE film
+    SET search_vector = film_translated_search_vector(id, title, description)
+    WHERE id IN (SELECT film_id FROM films_actors WHERE actor_id = changed_actor_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER film_translation_search_vector_update
+    AFTER INSERT OR UPDATE OR DELETE ON film_translation
+    FOR EACH ROW EXECUTE FUNCTION film_translation_search_vector_update();
+
+CREATE TRIGGER actor_translation_search_vector_update
+    AFTER INSERT OR UPDATE OR DELETE ON actor_translation
+    FOR EACH ROW EXECUTE FUNCTION actor_translation_search_vector_update();
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS film_certification;
+DROP TABLE IF EXISTS film_release;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS film_release (
+    film_id INTEGER NOT NULL REFERENCES film(id) ON DELETE CASCADE,
+    country CHAR(2) NOT NULL,
+    release_type VARCHAR(20) NOT NULL CHECK (release_type IN ('theatrical', 'digital', 'festival')),
+    release_date DATE NOT NULL,
+    PRIMARY KEY (film_id, country, release_type)
+);
+
+CREATE INDEX IF NOT EXISTS film_release_country_date_idx ON film_release (country, release_date);
+
+CREATE TABLE IF NOT EXISTS film_certification (
+    film_id INTEGER NOT NULL REFERENCES film(id) ON DELETE CASCADE,
+    country CHAR(2) NOT NULL,
+    certification VARCHAR(10) NOT NULL,
+    rank INTEGER NOT NULL,
+    PRIMARY KEY (film_id, country)
+);
+
+CREATE INDEX IF NOT EXISTS film_certification_country_rank_idx ON film_certification (country, rank);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS franchises_films;
+DROP TABLE IF EXISTS franchise;
+DROP TABLE IF EXISTS film_relation;
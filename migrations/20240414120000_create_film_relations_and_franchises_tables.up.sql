@@ -0,0 +1,24 @@
+-- Prequels are stored as inverse sequels, so film_id is always the later film of the story.
+CREATE TABLE IF NOT EXISTS film_relation (
+    film_id INTEGER NOT NULL REFERENCES film(id) ON DELETE CASCADE,
+    related_film_id INTEGER NOT NULL REFERENCES film(id) ON DELETE CASCADE,
+    relation_type VARCHAR(20) NOT NULL CHECK (relation_type IN ('sequel_of', 'remake_of', 'spin_off')),
+    PRIMARY KEY (film_id, related_film_id),
+    CHECK (film_id <> related_film_id)
+);
+
+CREATE INDEX IF NOT EXISTS film_relation_related_film_id_idx ON film_relation (related_film_id);
+
+CREATE TABLE IF NOT EXISTS franchise (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(150) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS franchises_films (
+    franchise_id INTEGER NOT NULL REFERENCES franchise(id) ON DELETE CASCADE,
+    film_id INTEGER NOT NULL REFERENCES film(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (franchise_id, film_id)
+);
+
+CREATE INDEX IF NOT EXISTS franchises_films_film_id_idx ON franchises_films (film_id);
@@ -0,0 +1,10 @@
+-- Item-based similarity of films computed from user ratings, refreshed periodically by the service.
+CREATE TABLE IF NOT EXISTS film_similarity (
+    film_id INTEGER NOT NULL REFERENCES film(id) ON DELETE CASCADE,
+    similar_film_id INTEGER NOT NULL REFERENCES film(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    common_raters INTEGER NOT NULL,
+    PRIMARY KEY (film_id, similar_film_id)
+);
+
+CREATE INDEX IF NOT EXISTS film_similarity_similar_film_id_idx ON film_similarity (similar_film_id);
@@ -0,0 +1,2 @@
+DROP MATERIALIZED VIEW IF EXISTS stats_actor_films_by_year;
+DROP MATERIALIZED VIEW IF EXISTS stats_films_by_year;
@@ -0,0 +1,24 @@
+-- Aggregates of catalog statistics, refreshed periodically by the service.
+CREATE MATERIALIZED VIEW IF NOT EXISTS stats_films_by_year AS
+    SELECT EXTRACT(YEAR FROM f.release_date)::INTEGER AS year, f.rating,
+        COUNT(*) AS films_count, SUM(c.cast_size) AS cast_size_sum, NOW() AS refreshed_at
+    FROM film f
+    CROSS JOIN LATERAL (
+        SELECT COUNT(*) AS cast_size
+        FROM films_actors fa
+        JOIN actor a ON fa.actor_id = a.id AND a.deleted_at IS NULL
+        WHERE fa.film_id = f.id
+    ) c
+    WHERE f.deleted_at IS NULL
+    GROUP BY 1, 2;
+
+CREATE UNIQUE INDEX IF NOT EXISTS stats_films_by_year_year_rating_idx ON stats_films_by_year (year, rating);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS stats_actor_films_by_year AS
+    SELECT fa.actor_id, EXTRACT(YEAR FROM f.release_date)::INTEGER AS year, COUNT(*) AS films_count, NOW() AS refreshed_at
+    FROM films_actors fa
+    JOIN film f ON fa.film_id = f.id AND f.deleted_at IS NULL
+    JOIN actor a ON fa.actor_id = a.id AND a.deleted_at IS NULL
+    GROUP BY 1, 2;
+
+CREATE UNIQUE INDEX IF NOT EXISTS stats_actor_films_by_year_actor_id_year_idx ON stats_actor_films_by_year (actor_id, year);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS actor_updated_at_idx;
+DROP INDEX IF EXISTS film_updated_at_idx;
+
+DROP TRIGGER IF EXISTS films_actors_touch_updated_at ON films_actors;
+DROP TRIGGER IF EXISTS actor_set_updated_at ON actor;
+DROP TRIGGER IF EXISTS film_set_updated_at ON film;
+
+DROP FUNCTION IF EXISTS films_actors_touch_updated_at();
+DROP FUNCTION IF EXISTS set_updated_at();
+
+ALTER TABLE actor DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE film DROP COLUMN IF EXISTS updated_at;
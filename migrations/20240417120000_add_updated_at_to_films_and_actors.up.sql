@@ -0,0 +1,39 @@
+ALTER TABLE film ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE actor ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- Wall clock time is used instead of transaction start time, so that rows changed by long transactions are not older than rows already read.
+CREATE OR REPLACE FUNCTION set_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at := clock_timestamp();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Cast changes touch both the film and the actor, as lists of films include cast and lists of actors include films.
+CREATE OR REPLACE FUNCTION films_actors_touch_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE film SET updated_at = clock_timestamp() WHERE id = OLD.film_id;
+        UPDATE actor SET updated_at = clock_timestamp() WHERE id = OLD.actor_id;
+    ELSE
+        UPDATE film SET updated_at = clock_timestamp() WHERE id = NEW.film_id;
+        UPDATE actor SET updated_at = clock_timestamp() WHERE id = NEW.actor_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER film_set_updated_at
+    BEFORE UPDATE ON film
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TRIGGER actor_set_updated_at
+    BEFORE UPDATE ON actor
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TRIGGER films_actors_touch_updated_at
+    AFTER INSERT OR DELETE ON films_actors
+    FOR EACH ROW EXECUTE FUNCTION films_actors_touch_updated_at();
+
+CREATE INDEX IF NOT EXISTS film_updated_at_idx ON film (updated_at);
+CREATE INDEX IF NOT EXISTS actor_updated_at_idx ON actor (updated_at);
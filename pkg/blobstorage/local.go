@@ -0,0 +1,93 @@
+package blobstorage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Storage of blobs in a local directory. Content type is derived from the key extension.
+type LocalStorage struct {
+	root    string
+	baseURL string
+}
+
+// Create new LocalStorage in root directory, blobs are served by the app under baseURL.
+func NewLocalStorage(root, baseURL string) (storage *LocalStorage, err error) {
+	err = os.MkdirAll(root, 0o755)
+	if err != nil {
+		return
+	}
+	storage = &LocalStorage{root, strings.TrimSuffix(baseURL, "/")}
+	return
+}
+
+// Write a blob, replacing an existing one. Data is written to a temporary file first, so readers never see partial blobs.
+func (s *LocalStorage) Put(ctx context.Context, key, contentType string, data []byte) (err error) {
+	if err = validateKey(key); err != nil {
+		return
+	}
+	filePath := s.path(key)
+	if err = os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return
+	}
+	file, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(file.Name())
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		return
+	}
+	if err = file.Close(); err != nil {
+		return
+	}
+	if err = os.Chmod(file.Name(), 0o644); err != nil {
+		return
+	}
+	err = os.Rename(file.Name(), filePath)
+	return
+}
+
+// Open a blob for reading.
+func (s *LocalStorage) Get(ctx context.Context, key string) (body io.ReadCloser, contentType string, err error) {
+	if err = validateKey(key); err != nil {
+		return
+	}
+	file, err := os.Open(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		err = ErrNotFound
+	}
+	if err != nil {
+		return
+	}
+	return file, mime.TypeByExtension(path.Ext(key)), nil
+}
+
+// Delete a blob, missing blobs are ignored.
+func (s *LocalStorage) Delete(ctx context.Context, key string) (err error) {
+	if err = validateKey(key); err != nil {
+		return
+	}
+	err = os.Remove(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		err = nil
+	}
+	return
+}
+
+// Get public URL of a blob.
+func (s *LocalStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+func (s *LocalStorage) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
@@ -0,0 +1,243 @@
+package blobstorage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Storage of blobs in a bucket of an S3-compatible service, e.g. AWS S3 or MinIO.
+// Requests use path-style addressing and are signed with AWS Signature Version 4.
+type S3Storage struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	publicURL string
+	client    *http.Client
+}
+
+// Create new S3Storage. If publicURL is empty, blob URLs point to the bucket at endpoint.
+func NewS3Storage(endpoint, region, bucket, accessKey, secretKey, publicURL string) (storage *S3Storage, err error) {
+	endpointURL, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil {
+		return
+	}
+	if endpointURL.Scheme == "" || endpointURL.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+	}
+	if publicURL == "" {
+		publicURL = endpointURL.String() + "/" + bucket
+	}
+	storage = &S3Storage{
+		endpoint:  endpointURL,
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+		client:    &http.Client{Timeout: time.Minute},
+	}
+	return
+}
+
+// Upload a blob, replacing an existing one.
+func (s *S3Storage) Put(ctx context.Context, key, contentType string, data []byte) (err error) {
+	if err = validateKey(key); err != nil {
+		return
+	}
+	req, err := s.newRequest(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", contentType)
+	res, err := s.do(req, data)
+	if err != nil {
+		return
+	}
+	res.Body.Close()
+	return
+}
+
+// Download a blob.
+func (s *S3Storage) Get(ctx context.Context, key string) (body io.ReadCloser, contentType string, err error) {
+	if err = validateKey(key); err != nil {
+		return
+	}
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return
+	}
+	res, err := s.do(req, nil)
+	if err != nil {
+		return
+	}
+	return res.Body, res.Header.Get("Content-Type"), nil
+}
+
+// Delete a blob, missing blobs are ignored.
+func (s *S3Storage) Delete(ctx context.Context, key string) (err error) {
+	if err = validateKey(key); err != nil {
+		return
+	}
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return
+	}
+	res, err := s.do(req, nil)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	res.Body.Close()
+	return
+}
+
+// Get public URL of a blob.
+func (s *S3Storage) URL(key string) string {
+	return s.publicURL + "/" + uriEncode(key)
+}
+
+func (s *S3Storage) newRequest(ctx context.Context, method, key string, data []byte) (req *http.Request, err error) {
+	objectURL := *s.endpoint
+	objectURL.Path = s.endpoint.Path + "/" + s.bucket + "/" + key
+	objectURL.RawPath = uriEncode(objectURL.Path)
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+	return http.NewRequestWithContext(ctx, method, objectURL.String(), body)
+}
+
+// Sign and send a request. Responses with error status are closed and returned as errors.
+func (s *S3Storage) do(req *http.Request, payload []byte) (res *http.Response, err error) {
+	s.sign(req, payload, time.Now().UTC())
+	res, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	if res.StatusCode < 300 {
+		return
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return nil, fmt.Errorf("S3 %s %s: %s: %s", req.Method, req.URL.Path, res.Status, bytes.TrimSpace(message))
+}
+
+// Add AWS Signature Version 4 headers to a request.
+func (s *S3Storage) sign(req *http.Request, payload []byte, now time.Time) {
+	payloadSum := sha256.Sum256(payload)
+	payloadHash := hex.EncodeToString(payloadSum[:])
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	request, signedHeaders := canonicalRequest(req, payloadHash)
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	signature := hex.EncodeToString(hmacSHA256(s.signingKey(date), stringToSign(amzDate, scope, request)))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// Build the canonical form of a request, signing all of its headers.
+func canonicalRequest(req *http.Request, payloadHash string) (request, signedHeaders string) {
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders = strings.Join(names, ";")
+
+	request = strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	return
+}
+
+// Build the canonical query string: parameters sorted by name and value, every one with "=" even if its value is empty.
+func canonicalQuery(query url.Values) string {
+	params := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			params = append(params, queryEncode(name)+"="+queryEncode(value))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// Build the string to sign of a canonical request within a credential scope.
+func stringToSign(amzDate, scope, canonicalRequest string) string {
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	return "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalRequestHash[:])
+}
+
+// Derive the key signing requests of a day.
+func (s *S3Storage) signingKey(date string) (key []byte) {
+	key = hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// Percent-encode a path as required by Signature Version 4: everything except unreserved characters and slashes.
+func uriEncode(value string) string {
+	return percentEncode(value, false)
+}
+
+// Percent-encode a query parameter name or value, slashes included.
+func queryEncode(value string) string {
+	return percentEncode(value, true)
+}
+
+func percentEncode(value string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for _, b := range []byte(value) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9', b == '-', b == '_', b == '.', b == '~':
+			encoded.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			encoded.WriteByte(b)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return encoded.String()
+}
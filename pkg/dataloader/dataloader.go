@@ -0,0 +1,109 @@
+package dataloader
+
+import "sync"
+
+// Load values by a list of keys, missing keys are left out of the map.
+type BatchFunc[K comparable, V any] func(keys []K) (map[K]V, error)
+
+// Per-request loader that collects keys and loads them with one batch call once a value is needed.
+// Loaded values are cached for the lifetime of the loader. Safe for concurrent use, keys queued by several goroutines
+// are loaded with one batch call, and each key is loaded once.
+type Loader[K comparable, V any] struct {
+	batch   BatchFunc[K, V]
+	mu      sync.Mutex
+	results map[K]*result[K, V]
+	pending []*result[K, V]
+}
+
+// Value of a key, set before done is closed.
+type result[K comparable, V any] struct {
+	key    K
+	queued bool
+	done   chan struct{}
+	value  V
+	err    error
+}
+
+// Create new Loader calling batch for the collected keys.
+func New[K comparable, V any](batch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		batch:   batch,
+		results: make(map[K]*result[K, V]),
+	}
+}
+
+// Queue a key, the returned function loads all queued keys on the first call.
+// A key missing from the batch result gives the zero value.
+func (l *Loader[K, V]) Load(key K) func() (V, error) {
+	l.mu.Lock()
+	r, ok := l.results[key]
+	if !ok {
+		r = &result[K, V]{key: key, queued: true, done: make(chan struct{})}
+		l.results[key] = r
+		l.pending = append(l.pending, r)
+	}
+	l.mu.Unlock()
+	return func() (V, error) {
+		l.mu.Lock()
+		if r.queued {
+			l.dispatch()
+		} else {
+			l.mu.Unlock()
+		}
+		<-r.done
+		return r.value, r.err
+	}
+}
+
+// Queue several keys, the values are returned in the order of keys.
+func (l *Loader[K, V]) LoadMany(keys []K) func() ([]V, error) {
+	thunks := make([]func() (V, error), len(keys))
+	for i, key := range keys {
+		thunks[i] = l.Load(key)
+	}
+	return func() (values []V, err error) {
+		values = make([]V, len(keys))
+		for i, thunk := range thunks {
+			if values[i], err = thunk(); err != nil {
+				return nil, err
+			}
+		}
+		return
+	}
+}
+
+// Cache a value loaded elsewhere, existing values are kept.
+func (l *Loader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.results[key]; !ok {
+		r := &result[K, V]{key: key, done: make(chan struct{}), value: value}
+		close(r.done)
+		l.results[key] = r
+	}
+}
+
+// Remove a cached value, so that the next load fetches it again. Loads already queued still get the value.
+func (l *Loader[K, V]) Clear(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.results, key)
+}
+
+// Load all queued keys with one batch call. Called with l.mu locked, it is unlocked before the batch call.
+func (l *Loader[K, V]) dispatch() {
+	batched := l.pending
+	l.pending = nil
+	keys := make([]K, len(batched))
+	for i, r := range batched {
+		r.queued = false
+		keys[i] = r.key
+	}
+	l.mu.Unlock()
+
+	values, err := l.batch(keys)
+	for _, r := range batched {
+		r.value, r.err = values[r.key], err
+		close(r.done)
+	}
+}
@@ -0,0 +1,243 @@
+package dataloader
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// Batch function that records its calls and returns the square of every key except negative ones.
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]int
+	err     error
+}
+
+func (r *recorder) batch(keys []int) (map[int]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sorted := append([]int(nil), keys...)
+	sort.Ints(sorted)
+	r.batches = append(r.batches, sorted)
+	values := make(map[int]int)
+	for _, key := range keys {
+		if key >= 0 {
+			values[key] = key * key
+		}
+	}
+	return values, r.err
+}
+
+func TestLoader(t *testing.T) {
+	errBatch := errors.New("batch failed")
+	tests := []struct {
+		name        string
+		err         error
+		run         func(l *Loader[int, int]) ([]int, error)
+		want        []int
+		wantErr     error
+		wantBatches [][]int
+	}{
+		{
+			name: "queued keys are loaded with one batch",
+			run: func(l *Loader[int, int]) ([]int, error) {
+				a, b, c := l.Load(1), l.Load(2), l.Load(3)
+				values := make([]int, 3)
+				for i, thunk := range []func() (int, error){c, a, b} {
+					value, err := thunk()
+					if err != nil {
+						return nil, err
+					}
+					values[i] = value
+				}
+				return values, nil
+			},
+			want:        []int{9, 1, 4},
+			wantBatches: [][]int{{1, 2, 3}},
+		},
+		{
+			name: "duplicate keys are loaded once",
+			run: func(l *Loader[int, int]) ([]int, error) {
+				return l.LoadMany([]int{2, 1, 2, 1})()
+			},
+			want:        []int{4, 1, 4, 1},
+			wantBatches: [][]int{{1, 2}},
+		},
+		{
+			name: "loaded keys are cached",
+			run: func(l *Loader[int, int]) ([]int, error) {
+				if _, err := l.LoadMany([]int{1, 2})(); err != nil {
+					return nil, err
+				}
+				return l.LoadMany([]int{2, 3})()
+			},
+			want:        []int{4, 9},
+			wantBatches: [][]int{{1, 2}, {3}},
+		},
+		{
+			name: "keys queued after a batch go to the next one",
+			run: func(l *Loader[int, int]) ([]int, error) {
+				first := l.Load(1)
+				if _, err := first(); err != nil {
+					return nil, err
+				}
+				return l.LoadMany([]int{2, 3})()
+			},
+			want:        []int{4, 9},
+			wantBatches: [][]int{{1}, {2, 3}},
+		},
+		{
+			name: "missing keys give zero values",
+			run: func(l *Loader[int, int]) ([]int, error) {
+				return l.LoadMany([]int{-1, 2})()
+			},
+			want:        []int{0, 4},
+			wantBatches: [][]int{{-1, 2}},
+		},
+		{
+			name: "batch error is returned for every key",
+			err:  errBatch,
+			run: func(l *Loader[int, int]) ([]int, error) {
+				return l.LoadMany([]int{1, 2})()
+			},
+			wantErr:     errBatch,
+			wantBatches: [][]int{{1, 2}},
+		},
+		{
+			name: "primed keys are not loaded",
+			run: func(l *Loader[int, int]) ([]int, error) {
+				l.Prime(1, 100)
+				l.Prime(1, 200)
+				return l.LoadMany([]int{1, 2})()
+			},
+			want:        []int{100, 4},
+			wantBatches: [][]int{{2}},
+		},
+		{
+			name: "cleared keys are loaded again",
+			run: func(l *Loader[int, int]) ([]int, error) {
+				l.Prime(1, 100)
+				l.Clear(1)
+				return l.LoadMany([]int{1})()
+			},
+			want:        []int{1},
+			wantBatches: [][]int{{1}},
+		},
+		{
+			name: "keys cleared while queued are still loaded",
+			run: func(l *Loader[int, int]) ([]int, error) {
+				thunk := l.Load(1)
+				l.Clear(1)
+				value, err := thunk()
+				return []int{value}, err
+			},
+			want:        []int{1},
+			wantBatches: [][]int{{1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{err: tt.err}
+			got, err := tt.run(New(r.batch))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("values = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(r.batches, tt.wantBatches) {
+				t.Errorf("batches = %v, want %v", r.batches, tt.wantBatches)
+			}
+		})
+	}
+}
+
+func TestLoaderConcurrentLoad(t *testing.T) {
+	tests := []struct {
+		name       string
+		goroutines int
+		keys       int
+	}{
+		{"one key", 50, 1},
+		{"shared keys", 100, 10},
+		{"distinct keys", 20, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			l := New(r.batch)
+
+			// All keys are queued before any value is needed, so they make a single batch.
+			thunks := make([]func() (int, error), tt.goroutines)
+			var queued sync.WaitGroup
+			for i := range thunks {
+				queued.Add(1)
+				go func(i int) {
+					defer queued.Done()
+					thunks[i] = l.Load(i % tt.keys)
+				}(i)
+			}
+			queued.Wait()
+
+			values := make([]int, tt.goroutines)
+			errs := make([]error, tt.goroutines)
+			var loaded sync.WaitGroup
+			for i := range thunks {
+				loaded.Add(1)
+				go func(i int) {
+					defer loaded.Done()
+					values[i], errs[i] = thunks[i]()
+				}(i)
+			}
+			loaded.Wait()
+
+			for i := range values {
+				key := i % tt.keys
+				if errs[i] != nil || values[i] != key*key {
+					t.Errorf("Load(%d) = %d, %v, want %d", key, values[i], errs[i], key*key)
+				}
+			}
+			wantKeys := make([]int, tt.keys)
+			for i := range wantKeys {
+				wantKeys[i] = i
+			}
+			if want := fmt.Sprint([][]int{wantKeys}); fmt.Sprint(r.batches) != want {
+				t.Errorf("batches = %v, want %v", r.batches, want)
+			}
+		})
+	}
+}
+
+func TestLoaderConcurrentLoadAndWait(t *testing.T) {
+	r := &recorder{}
+	l := New(r.batch)
+
+	// Goroutines queue keys and wait for them at once, so batches may split, but every key is loaded once.
+	const goroutines, keys = 100, 10
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(key int) {
+			defer wg.Done()
+			if value, err := l.Load(key)(); err != nil || value != key*key {
+				t.Errorf("Load(%d) = %d, %v, want %d", key, value, err, key*key)
+			}
+		}(i % keys)
+	}
+	wg.Wait()
+
+	loaded := make(map[int]int)
+	for _, batch := range r.batches {
+		for _, key := range batch {
+			loaded[key]++
+		}
+	}
+	for key := 0; key < keys; key++ {
+		if loaded[key] != 1 {
+			t.Errorf("key %d was loaded %d times, want 1", key, loaded[key])
+		}
+	}
+}
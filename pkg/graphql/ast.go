@@ -0,0 +1,110 @@
+package graphql
+
+// Parsed GraphQL document.
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// Operation types.
+const (
+	OperationQuery    = "query"
+	OperationMutation = "mutation"
+)
+
+// Query or mutation.
+type Operation struct {
+	Type         string
+	Name         string
+	Variables    []*VariableDefinition
+	SelectionSet []Selection
+}
+
+// Variable of an operation. Types are not checked, so the type is kept only as written.
+type VariableDefinition struct {
+	Name         string
+	Type         string
+	DefaultValue Value
+}
+
+// Named fragment.
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+}
+
+// Field, fragment spread or inline fragment.
+type Selection interface {
+	selection()
+}
+
+type FieldSelection struct {
+	Alias        string
+	Name         string
+	Arguments    []*Argument
+	Directives   []*Directive
+	SelectionSet []Selection
+	Line, Column int
+}
+
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+}
+
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+}
+
+func (*FieldSelection) selection() {}
+func (*FragmentSpread) selection() {}
+func (*InlineFragment) selection() {}
+
+// Key of the field in the response.
+func (f *FieldSelection) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+type Argument struct {
+	Name  string
+	Value Value
+}
+
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+}
+
+// Literal value or variable reference.
+type Value interface {
+	value()
+}
+
+type (
+	Variable     struct{ Name string }
+	IntValue     struct{ Value int }
+	FloatValue   struct{ Value float64 }
+	StringValue  struct{ Value string }
+	BooleanValue struct{ Value bool }
+	NullValue    struct{}
+	EnumValue    struct{ Value string }
+	ListValue    struct{ Values []Value }
+	ObjectValue  struct{ Fields []*Argument }
+)
+
+func (*Variable) value()     {}
+func (*IntValue) value()     {}
+func (*FloatValue) value()   {}
+func (*StringValue) value()  {}
+func (*BooleanValue) value() {}
+func (*NullValue) value()    {}
+func (*EnumValue) value()    {}
+func (*ListValue) value()    {}
+func (*ObjectValue) value()  {}
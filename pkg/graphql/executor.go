@@ -0,0 +1,548 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Request to execute.
+type Params struct {
+	Schema        *Schema
+	Query         string
+	OperationName string
+	Variables     map[string]interface{}
+	Context       context.Context
+	// Reject mutations, used for requests that must not have side effects.
+	QueryOnly bool
+}
+
+// Response of an executed request.
+type Result struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Request or field error, fields with errors are resolved to null.
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+var (
+	ErrOperationNotFound    = errors.New("operation not found")
+	ErrOperationName        = errors.New("operation name is required when document has several operations")
+	ErrMutationNotAllowed   = errors.New("mutations are not allowed in this request")
+	ErrMutationNotSupported = errors.New("schema does not support mutations")
+	ErrIntrospection        = errors.New("introspection is not supported")
+)
+
+// Parse and execute a request.
+//
+// Fields are resolved level by level: all resolvers of a level are called before
+// any Thunk they returned is evaluated, so loaders can batch the keys of siblings.
+// Mutation root fields are executed one after another.
+func Do(p Params) *Result {
+	doc, err := Parse(p.Query)
+	if err != nil {
+		var syntaxErr *SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return &Result{Errors: []*Error{{
+				Message:   "syntax error: " + syntaxErr.Message,
+				Locations: []Location{{syntaxErr.Line, syntaxErr.Column}},
+			}}}
+		}
+		return requestError(err)
+	}
+	if err = checkFragmentCycles(doc); err != nil {
+		return requestError(err)
+	}
+	operation, err := selectOperation(doc, p.OperationName)
+	if err != nil {
+		return requestError(err)
+	}
+	root := p.Schema.Query
+	if operation.Type == OperationMutation {
+		if p.QueryOnly {
+			return requestError(ErrMutationNotAllowed)
+		}
+		if p.Schema.Mutation == nil {
+			return requestError(ErrMutationNotSupported)
+		}
+		root = p.Schema.Mutation
+	}
+	ctx := p.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	e := &executor{ctx: ctx, fragments: doc.Fragments}
+	if e.variables, err = e.variableValues(operation, p.Variables); err != nil {
+		return requestError(err)
+	}
+
+	data := &orderedMap{}
+	fields := e.collectFields(root, operation.SelectionSet, nil, make(map[string]bool))
+	if operation.Type == OperationMutation {
+		for _, key := range fields.keys {
+			single := &fieldGroups{keys: []string{key}, fields: map[string][]*FieldSelection{key: fields.fields[key]}}
+			e.run([]*objectTask{{object: root, fields: single, target: data}})
+		}
+	} else {
+		e.run([]*objectTask{{object: root, fields: fields, target: data}})
+	}
+	return &Result{Data: data, Errors: e.errors}
+}
+
+func requestError(err error) *Result {
+	return &Result{Errors: []*Error{{Message: err.Error()}}}
+}
+
+func selectOperation(doc *Document, name string) (*Operation, error) {
+	if name == "" {
+		if len(doc.Operations) > 1 {
+			return nil, ErrOperationName
+		}
+		return doc.Operations[0], nil
+	}
+	for _, operation := range doc.Operations {
+		if operation.Name == name {
+			return operation, nil
+		}
+	}
+	return nil, ErrOperationNotFound
+}
+
+// Reject fragments that spread themselves, they would never stop expanding on cyclic data.
+func checkFragmentCycles(doc *Document) error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var visit func(selections []Selection) error
+	visit = func(selections []Selection) error {
+		for _, selection := range selections {
+			var err error
+			switch s := selection.(type) {
+			case *FieldSelection:
+				err = visit(s.SelectionSet)
+			case *InlineFragment:
+				err = visit(s.SelectionSet)
+			case *FragmentSpread:
+				fragment, ok := doc.Fragments[s.Name]
+				if !ok || state[s.Name] == done {
+					continue
+				}
+				if state[s.Name] == visiting {
+					return fmt.Errorf("fragment %s spreads itself", s.Name)
+				}
+				state[s.Name] = visiting
+				err = visit(fragment.SelectionSet)
+				state[s.Name] = done
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, fragment := range doc.Fragments {
+		if err := visit([]Selection{&FragmentSpread{Name: fragment.Name}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type executor struct {
+	ctx       context.Context
+	fragments map[string]*Fragment
+	variables map[string]interface{}
+	errors    []*Error
+}
+
+// Selected fields of an object grouped by response key, in selection order.
+type fieldGroups struct {
+	keys   []string
+	fields map[string][]*FieldSelection
+}
+
+// Object value whose fields are to be resolved.
+type objectTask struct {
+	object *Object
+	source interface{}
+	fields *fieldGroups
+	target *orderedMap
+	path   []interface{}
+}
+
+// Field whose resolver was called, the value may still be a Thunk.
+type pendingField struct {
+	definition *Field
+	fields     []*FieldSelection
+	value      interface{}
+	err        error
+	target     *orderedMap
+	key        string
+	path       []interface{}
+}
+
+func (e *executor) run(tasks []*objectTask) {
+	for len(tasks) > 0 {
+		var pending []*pendingField
+		for _, task := range tasks {
+			pending = append(pending, e.resolveFields(task)...)
+		}
+		tasks = nil
+		for _, field := range pending {
+			value, err := field.value, field.err
+			for err == nil {
+				thunk, ok := value.(Thunk)
+				if !ok {
+					break
+				}
+				value, err = thunk()
+			}
+			if err != nil {
+				e.fieldError(field.fields[0], field.path, err)
+				continue
+			}
+			completed, next := e.complete(field.definition.Type, field.fields, value, field.path)
+			field.target.set(field.key, completed)
+			tasks = append(tasks, next...)
+		}
+	}
+}
+
+// Call the resolvers of the task fields, leaf values are set right away.
+func (e *executor) resolveFields(task *objectTask) (pending []*pendingField) {
+	for _, key := range task.fields.keys {
+		fields := task.fields.fields[key]
+		field := fields[0]
+		path := appendPath(task.path, key)
+		task.target.set(key, nil)
+
+		switch field.Name {
+		case "__typename":
+			task.target.set(key, task.object.Name)
+			continue
+		case "__schema", "__type":
+			e.fieldError(field, path, ErrIntrospection)
+			continue
+		}
+		definition, ok := task.object.Fields[field.Name]
+		if !ok {
+			e.fieldError(field, path, fmt.Errorf("cannot query field %s on type %s", field.Name, task.object.Name))
+			continue
+		}
+		_, isObject := namedType(definition.Type).(*Object)
+		if isObject && field.SelectionSet == nil {
+			e.fieldError(field, path, fmt.Errorf("field %s of type %s must have a selection of subfields", field.Name, definition.Type))
+			continue
+		}
+		if !isObject && field.SelectionSet != nil {
+			e.fieldError(field, path, fmt.Errorf("field %s of type %s must not have a selection of subfields", field.Name, definition.Type))
+			continue
+		}
+		args, err := e.argumentValues(definition, field)
+		if err != nil {
+			e.fieldError(field, path, err)
+			continue
+		}
+
+		var value interface{}
+		if definition.Resolve != nil {
+			value, err = definition.Resolve(ResolveParams{Context: e.ctx, Source: task.source, Args: args})
+		} else {
+			value = defaultResolve(task.source, field.Name)
+		}
+		pending = append(pending, &pendingField{definition, fields, value, err, task.target, key, path})
+	}
+	return
+}
+
+// Complete a resolved value by its type, objects are returned empty along with the tasks to fill them.
+func (e *executor) complete(t Type, fields []*FieldSelection, value interface{}, path []interface{}) (completed interface{}, tasks []*objectTask) {
+	if isNil(value) {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *Scalar:
+		return value, nil
+	case *Object:
+		var selections []Selection
+		for _, field := range fields {
+			selections = append(selections, field.SelectionSet...)
+		}
+		target := &orderedMap{}
+		groups := e.collectFields(t, selections, path, make(map[string]bool))
+		return target, []*objectTask{{object: t, source: value, fields: groups, target: target, path: path}}
+	case *List:
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			e.fieldError(fields[0], path, fmt.Errorf("expected list value for field %s", fields[0].Name))
+			return nil, nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			var next []*objectTask
+			items[i], next = e.complete(t.OfType, fields, v.Index(i).Interface(), appendPath(path, i))
+			tasks = append(tasks, next...)
+		}
+		return items, tasks
+	}
+	return nil, nil
+}
+
+// Collect the fields of an object selection, applying directives and fragments.
+func (e *executor) collectFields(object *Object, selections []Selection, path []interface{}, visited map[string]bool) *fieldGroups {
+	groups := &fieldGroups{fields: make(map[string][]*FieldSelection)}
+	var collect func(selections []Selection)
+	collect = func(selections []Selection) {
+		for _, selection := range selections {
+			switch s := selection.(type) {
+			case *FieldSelection:
+				if !e.included(s.Directives) {
+					continue
+				}
+				key := s.ResponseKey()
+				if _, ok := groups.fields[key]; !ok {
+					groups.keys = append(groups.keys, key)
+				}
+				groups.fields[key] = append(groups.fields[key], s)
+			case *InlineFragment:
+				if e.included(s.Directives) && (s.TypeCondition == "" || s.TypeCondition == object.Name) {
+					collect(s.SelectionSet)
+				}
+			case *FragmentSpread:
+				if !e.included(s.Directives) || visited[s.Name] {
+					continue
+				}
+				visited[s.Name] = true
+				fragment, ok := e.fragments[s.Name]
+				if !ok {
+					e.errors = append(e.errors, &Error{Message: "unknown fragment " + s.Name, Path: path})
+					continue
+				}
+				if fragment.TypeCondition == object.Name {
+					collect(fragment.SelectionSet)
+				}
+			}
+		}
+	}
+	collect(selections)
+	return groups
+}
+
+// Evaluate @skip and @include directives.
+func (e *executor) included(directives []*Directive) bool {
+	for _, directive := range directives {
+		if directive.Name != "skip" && directive.Name != "include" {
+			continue
+		}
+		for _, argument := range directive.Arguments {
+			if argument.Name != "if" {
+				continue
+			}
+			value, _ := e.valueOf(argument.Value)
+			condition, _ := value.(bool)
+			if condition == (directive.Name == "skip") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (e *executor) argumentValues(definition *Field, field *FieldSelection) (args map[string]interface{}, err error) {
+	args = make(map[string]interface{})
+	for _, argument := range field.Arguments {
+		known := false
+		for _, name := range definition.Args {
+			known = known || name == argument.Name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown argument %s on field %s", argument.Name, field.Name)
+		}
+		// Arguments set to variables that were not provided are treated as absent.
+		if value, ok := e.valueOf(argument.Value); ok {
+			args[argument.Name] = value
+		}
+	}
+	return
+}
+
+// Get the Go value of a literal: int, float64, string, bool, nil, []interface{} or map[string]interface{}.
+func (e *executor) valueOf(value Value) (result interface{}, ok bool) {
+	switch v := value.(type) {
+	case *Variable:
+		result, ok = e.variables[v.Name]
+		return
+	case *IntValue:
+		return v.Value, true
+	case *FloatValue:
+		return v.Value, true
+	case *StringValue:
+		return v.Value, true
+	case *BooleanValue:
+		return v.Value, true
+	case *EnumValue:
+		return v.Value, true
+	case *ListValue:
+		items := make([]interface{}, 0, len(v.Values))
+		for _, item := range v.Values {
+			itemValue, _ := e.valueOf(item)
+			items = append(items, itemValue)
+		}
+		return items, true
+	case *ObjectValue:
+		object := make(map[string]interface{}, len(v.Fields))
+		for _, field := range v.Fields {
+			if fieldValue, ok := e.valueOf(field.Value); ok {
+				object[field.Name] = fieldValue
+			}
+		}
+		return object, true
+	}
+	return nil, true
+}
+
+func (e *executor) variableValues(operation *Operation, provided map[string]interface{}) (variables map[string]interface{}, err error) {
+	variables = make(map[string]interface{})
+	for _, definition := range operation.Variables {
+		if value, ok := provided[definition.Name]; ok {
+			if variables[definition.Name], err = normalizeVariable(value); err != nil {
+				return nil, fmt.Errorf("variable %s: %w", definition.Name, err)
+			}
+		} else if definition.DefaultValue != nil {
+			variables[definition.Name], _ = e.valueOf(definition.DefaultValue)
+		}
+	}
+	return
+}
+
+// Convert numbers of variables decoded with json.Decoder.UseNumber to int or float64.
+func normalizeVariable(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil && int64(int(i)) == i {
+			return int(i), nil
+		}
+		return v.Float64()
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			var err error
+			if items[i], err = normalizeVariable(item); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			var err error
+			if object[key], err = normalizeVariable(item); err != nil {
+				return nil, err
+			}
+		}
+		return object, nil
+	}
+	return value, nil
+}
+
+func (e *executor) fieldError(field *FieldSelection, path []interface{}, err error) {
+	e.errors = append(e.errors, &Error{
+		Message:   err.Error(),
+		Locations: []Location{{field.Line, field.Column}},
+		Path:      path,
+	})
+}
+
+func appendPath(path []interface{}, segment interface{}) []interface{} {
+	next := make([]interface{}, len(path), len(path)+1)
+	copy(next, path)
+	return append(next, segment)
+}
+
+func namedType(t Type) Type {
+	for {
+		list, ok := t.(*List)
+		if !ok {
+			return t
+		}
+		t = list.OfType
+	}
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// JSON object keeping the order of selected fields.
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (m *orderedMap) set(key string, value interface{}) {
+	if m.values == nil {
+		m.values = make(map[string]interface{})
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Get the object as a map for MessagePack, where keys are sorted instead.
+func (m *orderedMap) MsgpackValue() interface{} {
+	if m.values == nil {
+		return map[string]interface{}{}
+	}
+	return m.values
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
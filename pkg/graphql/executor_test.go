@@ -0,0 +1,229 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/itmosha/vk-internship-2024/pkg/dataloader"
+)
+
+type testFilm struct {
+	ID         int    `json:"id"`
+	Title      string `json:"title"`
+	DirectorID int    `json:"-"`
+}
+
+type testPerson struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+var testFilms = []*testFilm{
+	{ID: 1, Title: "Up", DirectorID: 10},
+	{ID: 2, Title: "Coco", DirectorID: 11},
+	{ID: 3, Title: "Inside Out", DirectorID: 10},
+}
+
+// Loader of people that records the keys of every batch.
+type testPeopleLoader struct {
+	mu      sync.Mutex
+	batches [][]int
+}
+
+func (l *testPeopleLoader) load(ids []int) (map[int]*testPerson, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	sorted := append([]int(nil), ids...)
+	sort.Ints(sorted)
+	l.batches = append(l.batches, sorted)
+	people := make(map[int]*testPerson)
+	for _, id := range ids {
+		people[id] = &testPerson{ID: id, Name: fmt.Sprintf("Person %d", id)}
+	}
+	return people, nil
+}
+
+func testSchema(people *dataloader.Loader[int, *testPerson]) *Schema {
+	person := &Object{Name: "Person", Fields: map[string]*Field{
+		"id":   {Type: ID},
+		"name": {Type: String},
+	}}
+	film := &Object{Name: "Film", Fields: map[string]*Field{
+		"id":    {Type: ID},
+		"title": {Type: String},
+		"director": {Type: person, Resolve: func(p ResolveParams) (interface{}, error) {
+			thunk := people.Load(p.Source.(*testFilm).DirectorID)
+			return Thunk(func() (interface{}, error) { return thunk() }), nil
+		}},
+		"rating": {Type: Float, Resolve: func(p ResolveParams) (interface{}, error) {
+			if p.Source.(*testFilm).ID == 2 {
+				return nil, errors.New("rating is not available")
+			}
+			return 7.5, nil
+		}},
+	}}
+	query := &Object{Name: "Query", Fields: map[string]*Field{
+		"films": {Type: &List{film}, Resolve: func(p ResolveParams) (interface{}, error) {
+			return testFilms, nil
+		}},
+		"film": {Type: film, Args: []string{"id"}, Resolve: func(p ResolveParams) (interface{}, error) {
+			id, ok := p.Args["id"].(int)
+			if !ok {
+				return nil, errors.New("id must be an integer")
+			}
+			for _, film := range testFilms {
+				if film.ID == id {
+					return film, nil
+				}
+			}
+			return nil, nil
+		}},
+		"fail": {Type: String, Resolve: func(p ResolveParams) (interface{}, error) {
+			return Thunk(func() (interface{}, error) { return nil, errors.New("failed") }), nil
+		}},
+	}}
+	mutation := &Object{Name: "Mutation", Fields: map[string]*Field{
+		"delete_film": {Type: Boolean, Args: []string{"id"}, Resolve: func(p ResolveParams) (interface{}, error) {
+			return true, nil
+		}},
+	}}
+	return &Schema{Query: query, Mutation: mutation}
+}
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		operation string
+		variables map[string]interface{}
+		queryOnly bool
+		want      string
+	}{
+		{
+			name:  "aliases and arguments",
+			query: `{ up: film(id: 1) { title } coco: film(id: 2) { name: title } missing: film(id: 4) { title } }`,
+			want:  `{"data":{"up":{"title":"Up"},"coco":{"name":"Coco"},"missing":null}}`,
+		},
+		{
+			name:      "variables and defaults",
+			query:     `query Film($id: Int = 3, $other: Int) { a: film(id: $id) { id } b: film(id: $other) { id } }`,
+			variables: map[string]interface{}{"other": json.Number("1")},
+			want:      `{"data":{"a":{"id":3},"b":{"id":1}}}`,
+		},
+		{
+			name: "fragments and directives",
+			query: `query ($skip: Boolean) { film(id: 1) { ...Titles ... on Film @skip(if: $skip) { id } __typename } }
+				fragment Titles on Film { title title @include(if: false) }`,
+			variables: map[string]interface{}{"skip": true},
+			want:      `{"data":{"film":{"title":"Up","__typename":"Film"}}}`,
+		},
+		{
+			name:      "operation by name",
+			query:     `query A { film(id: 1) { id } } query B { film(id: 2) { id } }`,
+			operation: "B",
+			want:      `{"data":{"film":{"id":2}}}`,
+		},
+		{
+			name:  "resolver errors",
+			query: `{ fail films { id rating } }`,
+			want: `{"data":{"fail":null,"films":[{"id":1,"rating":7.5},{"id":2,"rating":null},{"id":3,"rating":7.5}]},` +
+				`"errors":[{"message":"failed","locations":[{"line":1,"column":3}],"path":["fail"]},` +
+				`{"message":"rating is not available","locations":[{"line":1,"column":19}],"path":["films",1,"rating"]}]}`,
+		},
+		{
+			name:  "invalid argument",
+			query: `{ film(id: "1") { id } }`,
+			want:  `{"data":{"film":null},"errors":[{"message":"id must be an integer","locations":[{"line":1,"column":3}],"path":["film"]}]}`,
+		},
+		{
+			name:  "unknown field",
+			query: `{ film(id: 1) { id budget } }`,
+			want:  `{"data":{"film":{"id":1,"budget":null}},"errors":[{"message":"cannot query field budget on type Film","locations":[{"line":1,"column":20}],"path":["film","budget"]}]}`,
+		},
+		{
+			name:  "unknown argument",
+			query: `{ film(id: 1, year: 2009) { id } }`,
+			want:  `{"data":{"film":null},"errors":[{"message":"unknown argument year on field film","locations":[{"line":1,"column":3}],"path":["film"]}]}`,
+		},
+		{
+			name:  "missing subfields",
+			query: `{ film(id: 1) }`,
+			want:  `{"data":{"film":null},"errors":[{"message":"field film of type Film must have a selection of subfields","locations":[{"line":1,"column":3}],"path":["film"]}]}`,
+		},
+		{
+			name:  "subfields of a scalar",
+			query: `{ film(id: 1) { title { id } } }`,
+			want:  `{"data":{"film":{"title":null}},"errors":[{"message":"field title of type String must not have a selection of subfields","locations":[{"line":1,"column":17}],"path":["film","title"]}]}`,
+		},
+		{
+			name:  "syntax error",
+			query: `{ film(id: 1) { id }`,
+			want:  `{"errors":[{"message":"syntax error: unexpected end of document","locations":[{"line":1,"column":21}]}]}`,
+		},
+		{
+			name:  "fragment cycle",
+			query: `{ ...A } fragment A on Query { ...B } fragment B on Query { ...A }`,
+			want:  `{"errors":[{"message":"fragment A spreads itself"}]}`,
+		},
+		{
+			name:  "ambiguous operation",
+			query: `query A { films { id } } query B { films { id } }`,
+			want:  `{"errors":[{"message":"operation name is required when document has several operations"}]}`,
+		},
+		{
+			name:      "mutation in query only request",
+			query:     `mutation { delete_film(id: 1) }`,
+			queryOnly: true,
+			want:      `{"errors":[{"message":"mutations are not allowed in this request"}]}`,
+		},
+		{
+			name:  "mutation",
+			query: `mutation { first: delete_film(id: 1) second: delete_film(id: 2) }`,
+			want:  `{"data":{"first":true,"second":true}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			people := &testPeopleLoader{}
+			result := Do(Params{
+				Schema:        testSchema(dataloader.New(people.load)),
+				Query:         tt.query,
+				OperationName: tt.operation,
+				Variables:     tt.variables,
+				QueryOnly:     tt.queryOnly,
+			})
+			got, err := json.Marshal(result)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Do() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoBatchesSiblingLoads(t *testing.T) {
+	people := &testPeopleLoader{}
+	result := Do(Params{
+		Schema: testSchema(dataloader.New(people.load)),
+		Query:  `{ films { title director { name } } again: film(id: 1) { director { id } } }`,
+	})
+	got, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"data":{"films":[{"title":"Up","director":{"name":"Person 10"}},{"title":"Coco","director":{"name":"Person 11"}},` +
+		`{"title":"Inside Out","director":{"name":"Person 10"}}],"again":{"director":{"id":10}}}}`
+	if string(got) != want {
+		t.Errorf("Do() = %s, want %s", got, want)
+	}
+	wantBatches := [][]int{{10, 11}}
+	if fmt.Sprint(people.batches) != fmt.Sprint(wantBatches) {
+		t.Errorf("batches = %v, want %v", people.batches, wantBatches)
+	}
+}
@@ -0,0 +1,578 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Token kinds.
+const (
+	tokenEOF = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind         int
+	value        string
+	line, column int
+}
+
+// Error in a query document with its position.
+type SyntaxError struct {
+	Message      string
+	Line, Column int
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at %d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+type lexer struct {
+	source       string
+	pos          int
+	line, column int
+}
+
+// Read the next token, skipping whitespace, commas and comments.
+func (l *lexer) next() (tok token, err error) {
+	for l.pos < len(l.source) {
+		c := l.source[l.pos]
+		switch {
+		case c == '\n':
+			l.pos++
+			l.line++
+			l.column = 1
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			l.advance(1)
+			continue
+		case strings.HasPrefix(l.source[l.pos:], "\ufeff"):
+			l.pos += len("\ufeff")
+			continue
+		case c == '#':
+			for l.pos < len(l.source) && l.source[l.pos] != '\n' {
+				l.pos++
+			}
+			continue
+		}
+		break
+	}
+	tok = token{line: l.line, column: l.column}
+	if l.pos >= len(l.source) {
+		tok.kind = tokenEOF
+		return
+	}
+	c := l.source[l.pos]
+	switch {
+	case strings.HasPrefix(l.source[l.pos:], "..."):
+		tok.kind, tok.value = tokenPunctuator, "..."
+		l.advance(3)
+	case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+		tok.kind, tok.value = tokenPunctuator, string(c)
+		l.advance(1)
+	case c == '_' || isLetter(c):
+		start := l.pos
+		for l.pos < len(l.source) && (l.source[l.pos] == '_' || isLetter(l.source[l.pos]) || isDigit(l.source[l.pos])) {
+			l.advance(1)
+		}
+		tok.kind, tok.value = tokenName, l.source[start:l.pos]
+	case c == '-' || isDigit(c):
+		return l.number(tok)
+	case c == '"':
+		return l.string(tok)
+	default:
+		err = &SyntaxError{fmt.Sprintf("unexpected character %q", c), l.line, l.column}
+	}
+	return
+}
+
+func (l *lexer) advance(n int) {
+	l.pos += n
+	l.column += n
+}
+
+func (l *lexer) number(tok token) (token, error) {
+	start := l.pos
+	tok.kind = tokenInt
+	if l.source[l.pos] == '-' {
+		l.advance(1)
+	}
+	digits := func() {
+		for l.pos < len(l.source) && isDigit(l.source[l.pos]) {
+			l.advance(1)
+		}
+	}
+	digits()
+	if l.pos < len(l.source) && l.source[l.pos] == '.' {
+		tok.kind = tokenFloat
+		l.advance(1)
+		digits()
+	}
+	if l.pos < len(l.source) && (l.source[l.pos] == 'e' || l.source[l.pos] == 'E') {
+		tok.kind = tokenFloat
+		l.advance(1)
+		if l.pos < len(l.source) && (l.source[l.pos] == '+' || l.source[l.pos] == '-') {
+			l.advance(1)
+		}
+		digits()
+	}
+	tok.value = l.source[start:l.pos]
+	return tok, nil
+}
+
+// Read a string or a block string. Block strings are taken verbatim, without removing common indentation.
+func (l *lexer) string(tok token) (token, error) {
+	tok.kind = tokenString
+	if strings.HasPrefix(l.source[l.pos:], `"""`) {
+		end := strings.Index(l.source[l.pos+3:], `"""`)
+		if end < 0 {
+			return tok, &SyntaxError{"unterminated block string", tok.line, tok.column}
+		}
+		tok.value = strings.ReplaceAll(l.source[l.pos+3:l.pos+3+end], `\"""`, `"""`)
+		for _, c := range l.source[l.pos : l.pos+end+6] {
+			if c == '\n' {
+				l.line++
+				l.column = 0
+			}
+			l.column++
+		}
+		l.pos += end + 6
+		return tok, nil
+	}
+	start := l.pos
+	l.advance(1)
+	for l.pos < len(l.source) && l.source[l.pos] != '"' {
+		if l.source[l.pos] == '\n' {
+			return tok, &SyntaxError{"unterminated string", tok.line, tok.column}
+		}
+		if l.source[l.pos] == '\\' {
+			l.advance(1)
+		}
+		l.advance(1)
+	}
+	if l.pos >= len(l.source) {
+		return tok, &SyntaxError{"unterminated string", tok.line, tok.column}
+	}
+	l.advance(1)
+	// GraphQL escapes are a subset of JSON ones, which strconv.Unquote handles except \/ and \u.
+	value, err := strconv.Unquote(strings.ReplaceAll(l.source[start:l.pos], `\/`, `/`))
+	if err != nil {
+		return tok, &SyntaxError{"invalid string", tok.line, tok.column}
+	}
+	tok.value = value
+	return tok, nil
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+type parser struct {
+	lexer *lexer
+	tok   token
+}
+
+// Parse a GraphQL query document. Type system definitions are not supported.
+func Parse(source string) (doc *Document, err error) {
+	p := &parser{lexer: &lexer{source: source, line: 1, column: 1}}
+	if err = p.advance(); err != nil {
+		return
+	}
+	doc = &Document{Fragments: make(map[string]*Fragment)}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek(tokenPunctuator, "{"):
+			operation := &Operation{Type: OperationQuery}
+			if operation.SelectionSet, err = p.selectionSet(); err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, operation)
+		case p.peek(tokenName, OperationQuery), p.peek(tokenName, OperationMutation):
+			operation, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, operation)
+		case p.peek(tokenName, "fragment"):
+			fragment, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.Fragments[fragment.Name]; ok {
+				return nil, p.errorf("duplicate fragment %s", fragment.Name)
+			}
+			doc.Fragments[fragment.Name] = fragment
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.Operations) == 0 {
+		return nil, p.errorf("document has no operations")
+	}
+	return
+}
+
+func (p *parser) advance() (err error) {
+	p.tok, err = p.lexer.next()
+	return
+}
+
+func (p *parser) peek(kind int, value string) bool {
+	return p.tok.kind == kind && p.tok.value == value
+}
+
+// Skip the token if it matches.
+func (p *parser) skip(kind int, value string) (skipped bool, err error) {
+	if p.peek(kind, value) {
+		return true, p.advance()
+	}
+	return false, nil
+}
+
+func (p *parser) expect(kind int, value string) (err error) {
+	if !p.peek(kind, value) {
+		return p.unexpected()
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (name string, err error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected()
+	}
+	name = p.tok.value
+	err = p.advance()
+	return
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{fmt.Sprintf(format, args...), p.tok.line, p.tok.column}
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokenEOF {
+		return p.errorf("unexpected end of document")
+	}
+	return p.errorf("unexpected %q", p.tok.value)
+}
+
+func (p *parser) operation() (operation *Operation, err error) {
+	operation = &Operation{Type: p.tok.value}
+	if err = p.advance(); err != nil {
+		return
+	}
+	if p.tok.kind == tokenName {
+		if operation.Name, err = p.name(); err != nil {
+			return
+		}
+	}
+	if p.peek(tokenPunctuator, "(") {
+		if operation.Variables, err = p.variableDefinitions(); err != nil {
+			return
+		}
+	}
+	if _, err = p.directives(); err != nil {
+		return
+	}
+	operation.SelectionSet, err = p.selectionSet()
+	return
+}
+
+func (p *parser) variableDefinitions() (definitions []*VariableDefinition, err error) {
+	if err = p.expect(tokenPunctuator, "("); err != nil {
+		return
+	}
+	for !p.peek(tokenPunctuator, ")") {
+		definition := &VariableDefinition{}
+		if err = p.expect(tokenPunctuator, "$"); err != nil {
+			return
+		}
+		if definition.Name, err = p.name(); err != nil {
+			return
+		}
+		if err = p.expect(tokenPunctuator, ":"); err != nil {
+			return
+		}
+		if definition.Type, err = p.typeRef(); err != nil {
+			return
+		}
+		var hasDefault bool
+		if hasDefault, err = p.skip(tokenPunctuator, "="); err != nil {
+			return
+		}
+		if hasDefault {
+			if definition.DefaultValue, err = p.value(true); err != nil {
+				return
+			}
+		}
+		definitions = append(definitions, definition)
+	}
+	err = p.advance()
+	return
+}
+
+// Read a type reference like [Int!]! as written.
+func (p *parser) typeRef() (typeRef string, err error) {
+	var isList bool
+	if isList, err = p.skip(tokenPunctuator, "["); err != nil {
+		return
+	}
+	if isList {
+		var elem string
+		if elem, err = p.typeRef(); err != nil {
+			return
+		}
+		if err = p.expect(tokenPunctuator, "]"); err != nil {
+			return
+		}
+		typeRef = "[" + elem + "]"
+	} else if typeRef, err = p.name(); err != nil {
+		return
+	}
+	var isNonNull bool
+	if isNonNull, err = p.skip(tokenPunctuator, "!"); err == nil && isNonNull {
+		typeRef += "!"
+	}
+	return
+}
+
+func (p *parser) fragment() (fragment *Fragment, err error) {
+	fragment = &Fragment{}
+	if err = p.advance(); err != nil {
+		return
+	}
+	if fragment.Name, err = p.name(); err != nil {
+		return
+	}
+	if fragment.Name == "on" {
+		return nil, p.errorf("fragment can not be named on")
+	}
+	if err = p.expect(tokenName, "on"); err != nil {
+		return
+	}
+	if fragment.TypeCondition, err = p.name(); err != nil {
+		return
+	}
+	if fragment.Directives, err = p.directives(); err != nil {
+		return
+	}
+	fragment.SelectionSet, err = p.selectionSet()
+	return
+}
+
+func (p *parser) selectionSet() (selections []Selection, err error) {
+	if err = p.expect(tokenPunctuator, "{"); err != nil {
+		return
+	}
+	for !p.peek(tokenPunctuator, "}") {
+		var selection Selection
+		if p.peek(tokenPunctuator, "...") {
+			selection, err = p.fragmentSelection()
+		} else {
+			selection, err = p.field()
+		}
+		if err != nil {
+			return
+		}
+		selections = append(selections, selection)
+	}
+	if len(selections) == 0 {
+		return nil, p.errorf("empty selection set")
+	}
+	err = p.advance()
+	return
+}
+
+func (p *parser) field() (field *FieldSelection, err error) {
+	field = &FieldSelection{Line: p.tok.line, Column: p.tok.column}
+	if field.Name, err = p.name(); err != nil {
+		return
+	}
+	var isAliased bool
+	if isAliased, err = p.skip(tokenPunctuator, ":"); err != nil {
+		return
+	}
+	if isAliased {
+		field.Alias = field.Name
+		if field.Name, err = p.name(); err != nil {
+			return
+		}
+	}
+	if p.peek(tokenPunctuator, "(") {
+		if field.Arguments, err = p.arguments(false); err != nil {
+			return
+		}
+	}
+	if field.Directives, err = p.directives(); err != nil {
+		return
+	}
+	if p.peek(tokenPunctuator, "{") {
+		field.SelectionSet, err = p.selectionSet()
+	}
+	return
+}
+
+func (p *parser) fragmentSelection() (selection Selection, err error) {
+	if err = p.advance(); err != nil {
+		return
+	}
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		spread := &FragmentSpread{}
+		if spread.Name, err = p.name(); err != nil {
+			return
+		}
+		spread.Directives, err = p.directives()
+		return spread, err
+	}
+	fragment := &InlineFragment{}
+	var hasCondition bool
+	if hasCondition, err = p.skip(tokenName, "on"); err != nil {
+		return
+	}
+	if hasCondition {
+		if fragment.TypeCondition, err = p.name(); err != nil {
+			return
+		}
+	}
+	if fragment.Directives, err = p.directives(); err != nil {
+		return
+	}
+	fragment.SelectionSet, err = p.selectionSet()
+	return fragment, err
+}
+
+func (p *parser) arguments(isConst bool) (arguments []*Argument, err error) {
+	if err = p.expect(tokenPunctuator, "("); err != nil {
+		return
+	}
+	for !p.peek(tokenPunctuator, ")") {
+		argument := &Argument{}
+		if argument.Name, err = p.name(); err != nil {
+			return
+		}
+		if err = p.expect(tokenPunctuator, ":"); err != nil {
+			return
+		}
+		if argument.Value, err = p.value(isConst); err != nil {
+			return
+		}
+		arguments = append(arguments, argument)
+	}
+	err = p.advance()
+	return
+}
+
+func (p *parser) directives() (directives []*Directive, err error) {
+	for p.peek(tokenPunctuator, "@") {
+		if err = p.advance(); err != nil {
+			return
+		}
+		directive := &Directive{}
+		if directive.Name, err = p.name(); err != nil {
+			return
+		}
+		if p.peek(tokenPunctuator, "(") {
+			if directive.Arguments, err = p.arguments(false); err != nil {
+				return
+			}
+		}
+		directives = append(directives, directive)
+	}
+	return
+}
+
+// Read a value, variables are not allowed in constant values like variable defaults.
+func (p *parser) value(isConst bool) (value Value, err error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenPunctuator:
+		switch tok.value {
+		case "$":
+			if isConst {
+				return nil, p.unexpected()
+			}
+			if err = p.advance(); err != nil {
+				return
+			}
+			name, err := p.name()
+			return &Variable{name}, err
+		case "[":
+			return p.listValue(isConst)
+		case "{":
+			return p.objectValue(isConst)
+		}
+		return nil, p.unexpected()
+	case tokenInt:
+		i, err := strconv.Atoi(tok.value)
+		if err != nil {
+			return nil, p.errorf("invalid integer %s", tok.value)
+		}
+		value = &IntValue{i}
+	case tokenFloat:
+		f, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, p.errorf("invalid float %s", tok.value)
+		}
+		value = &FloatValue{f}
+	case tokenString:
+		value = &StringValue{tok.value}
+	case tokenName:
+		switch tok.value {
+		case "true", "false":
+			value = &BooleanValue{tok.value == "true"}
+		case "null":
+			value = &NullValue{}
+		default:
+			value = &EnumValue{tok.value}
+		}
+	default:
+		return nil, p.unexpected()
+	}
+	err = p.advance()
+	return
+}
+
+func (p *parser) listValue(isConst bool) (value Value, err error) {
+	list := &ListValue{}
+	if err = p.advance(); err != nil {
+		return
+	}
+	for !p.peek(tokenPunctuator, "]") {
+		var item Value
+		if item, err = p.value(isConst); err != nil {
+			return
+		}
+		list.Values = append(list.Values, item)
+	}
+	return list, p.advance()
+}
+
+func (p *parser) objectValue(isConst bool) (value Value, err error) {
+	object := &ObjectValue{}
+	if err = p.advance(); err != nil {
+		return
+	}
+	for !p.peek(tokenPunctuator, "}") {
+		field := &Argument{}
+		if field.Name, err = p.name(); err != nil {
+			return
+		}
+		if err = p.expect(tokenPunctuator, ":"); err != nil {
+			return
+		}
+		if field.Value, err = p.value(isConst); err != nil {
+			return
+		}
+		object.Fields = append(object.Fields, field)
+	}
+	return object, p.advance()
+}
@@ -0,0 +1,171 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   *Document
+	}{
+		{
+			name:   "shorthand query with alias and arguments",
+			source: `{ first: film(id: 1, title: "Up", rating: 7.5, deleted: false, sort: TITLE) { title } }`,
+			want: &Document{
+				Operations: []*Operation{{
+					Type: OperationQuery,
+					SelectionSet: []Selection{&FieldSelection{
+						Alias: "first",
+						Name:  "film",
+						Arguments: []*Argument{
+							{Name: "id", Value: &IntValue{1}},
+							{Name: "title", Value: &StringValue{"Up"}},
+							{Name: "rating", Value: &FloatValue{7.5}},
+							{Name: "deleted", Value: &BooleanValue{false}},
+							{Name: "sort", Value: &EnumValue{"TITLE"}},
+						},
+						SelectionSet: []Selection{&FieldSelection{Name: "title", Line: 1, Column: 79}},
+						Line:         1,
+						Column:       3,
+					}},
+				}},
+				Fragments: map[string]*Fragment{},
+			},
+		},
+		{
+			name: "named query with variables",
+			source: `query Film($id: Int! = 3, $ids: [ID!], $filter: Filter = {title: "A", ids: [1, null]}) {
+				film(id: $id) @include(if: true) { id }
+			}`,
+			want: &Document{
+				Operations: []*Operation{{
+					Type: OperationQuery,
+					Name: "Film",
+					Variables: []*VariableDefinition{
+						{Name: "id", Type: "Int!", DefaultValue: &IntValue{3}},
+						{Name: "ids", Type: "[ID!]"},
+						{Name: "filter", Type: "Filter", DefaultValue: &ObjectValue{Fields: []*Argument{
+							{Name: "title", Value: &StringValue{"A"}},
+							{Name: "ids", Value: &ListValue{Values: []Value{&IntValue{1}, &NullValue{}}}},
+						}}},
+					},
+					SelectionSet: []Selection{&FieldSelection{
+						Name:         "film",
+						Arguments:    []*Argument{{Name: "id", Value: &Variable{"id"}}},
+						Directives:   []*Directive{{Name: "include", Arguments: []*Argument{{Name: "if", Value: &BooleanValue{true}}}}},
+						SelectionSet: []Selection{&FieldSelection{Name: "id", Line: 2, Column: 40}},
+						Line:         2,
+						Column:       5,
+					}},
+				}},
+				Fragments: map[string]*Fragment{},
+			},
+		},
+		{
+			name: "fragments",
+			source: `query { ...Films ... on Query { actors { name } } }
+			fragment Films on Query @skip(if: false) { films { title } }`,
+			want: &Document{
+				Operations: []*Operation{{
+					Type: OperationQuery,
+					SelectionSet: []Selection{
+						&FragmentSpread{Name: "Films"},
+						&InlineFragment{TypeCondition: "Query", SelectionSet: []Selection{&FieldSelection{
+							Name:         "actors",
+							SelectionSet: []Selection{&FieldSelection{Name: "name", Line: 1, Column: 42}},
+							Line:         1,
+							Column:       33,
+						}}},
+					},
+				}},
+				Fragments: map[string]*Fragment{"Films": {
+					Name:          "Films",
+					TypeCondition: "Query",
+					Directives:    []*Directive{{Name: "skip", Arguments: []*Argument{{Name: "if", Value: &BooleanValue{false}}}}},
+					SelectionSet: []Selection{&FieldSelection{
+						Name:         "films",
+						SelectionSet: []Selection{&FieldSelection{Name: "title", Line: 2, Column: 55}},
+						Line:         2,
+						Column:       47,
+					}},
+				}},
+			},
+		},
+		{
+			name:   "mutation with comments and block string",
+			source: "# create\nmutation Create { create_film(input: {title: \"\"\"Up\n\"quoted\" film\"\"\"}) { id } }",
+			want: &Document{
+				Operations: []*Operation{{
+					Type: OperationMutation,
+					Name: "Create",
+					SelectionSet: []Selection{&FieldSelection{
+						Name: "create_film",
+						Arguments: []*Argument{{Name: "input", Value: &ObjectValue{Fields: []*Argument{
+							{Name: "title", Value: &StringValue{"Up\n\"quoted\" film"}},
+						}}}},
+						SelectionSet: []Selection{&FieldSelection{Name: "id", Line: 3, Column: 22}},
+						Line:         2,
+						Column:       19,
+					}},
+				}},
+				Fragments: map[string]*Fragment{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(tt.source)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(doc, tt.want) {
+				t.Errorf("Parse() = %s, want %s", dump(doc), dump(tt.want))
+			}
+		})
+	}
+}
+
+func TestParseSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   SyntaxError
+	}{
+		{"empty document", "", SyntaxError{"document has no operations", 1, 1}},
+		{"fragments only", "fragment F on Film { id }", SyntaxError{"document has no operations", 1, 26}},
+		{"unclosed selection set", "{ film { id }", SyntaxError{"unexpected end of document", 1, 14}},
+		{"missing argument value", "{ film(id: ) { id } }", SyntaxError{`unexpected ")"`, 1, 12}},
+		{"variable in default value", "query ($a: Int = $b) { film }", SyntaxError{`unexpected "$"`, 1, 18}},
+		{"duplicate fragment", "{ a } fragment F on Q { b }\nfragment F on Q { c }", SyntaxError{"duplicate fragment F", 2, 22}},
+		{"unterminated string", `{ film(title: "Up) }`, SyntaxError{"unterminated string", 1, 15}},
+		{"empty selection set", "{ }", SyntaxError{"empty selection set", 1, 3}},
+		{"unexpected character", "{ film ? }", SyntaxError{"unexpected character '?'", 1, 8}},
+		{"subscription", "subscription { films }", SyntaxError{`unexpected "subscription"`, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.source)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse() error = %v, want SyntaxError", err)
+			}
+			if *syntaxErr != tt.want {
+				t.Errorf("Parse() error = %+v, want %+v", *syntaxErr, tt.want)
+			}
+		})
+	}
+}
+
+// Format a value for failure messages.
+func dump(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err.Error()
+	}
+	return string(encoded)
+}
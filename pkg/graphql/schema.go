@@ -0,0 +1,120 @@
+package graphql
+
+import (
+	"context"
+	"reflect"
+	"strings"
+)
+
+// Output type of a field: *Scalar, *Object or *List.
+type Type interface {
+	String() string
+}
+
+// Leaf type, values are serialized as they are encoded by encoding/json.
+type Scalar struct {
+	Name string
+}
+
+// Object type with named fields.
+type Object struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+// List of values of another type.
+type List struct {
+	OfType Type
+}
+
+func (s *Scalar) String() string { return s.Name }
+func (o *Object) String() string { return o.Name }
+func (l *List) String() string   { return "[" + l.OfType.String() + "]" }
+
+// Built-in scalars.
+var (
+	String  = &Scalar{"String"}
+	Int     = &Scalar{"Int"}
+	Float   = &Scalar{"Float"}
+	Boolean = &Scalar{"Boolean"}
+	ID      = &Scalar{"ID"}
+)
+
+// Field of an object type. Resolve may be nil to read the field of the source struct
+// with the same json name or the key of the source map.
+type Field struct {
+	Type        Type
+	Args        []string
+	Resolve     ResolveFunc
+	Description string
+}
+
+// Arguments of a resolved field, with variables substituted.
+type ResolveParams struct {
+	Context context.Context
+	Source  interface{}
+	Args    map[string]interface{}
+}
+
+// Resolve the value of a field, the value may be a Thunk to defer the work until
+// all fields on the same level are resolved.
+type ResolveFunc func(p ResolveParams) (interface{}, error)
+
+// Deferred value, used to batch loads of sibling fields.
+type Thunk func() (interface{}, error)
+
+// Root types of the API.
+type Schema struct {
+	Query    *Object
+	Mutation *Object
+}
+
+// Read the field of a struct by its json name, or the key of a map.
+func defaultResolve(source interface{}, name string) interface{} {
+	if m, ok := source.(map[string]interface{}); ok {
+		return m[name]
+	}
+	v := reflect.ValueOf(source)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	if field, ok := structField(v, name); ok {
+		return field.Interface()
+	}
+	return nil
+}
+
+func structField(v reflect.Value, name string) (field reflect.Value, ok bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == name {
+			return v.Field(i), true
+		}
+		if f.Anonymous && tag == "" {
+			embedded := v.Field(i)
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if field, ok = structField(embedded, name); ok {
+					return
+				}
+			}
+		}
+	}
+	return
+}
@@ -99,7 +99,7 @@ func ExtractAccessTokenClaims(accessToken string) (cls *AccessTokenClaims, isExp
 	if !ok {
 		return
 	}
-	cls.IsAdmin, ok = claimsMap["is_blocked"].(bool)
+	cls.IsAdmin, ok = claimsMap["is_admin"].(bool)
 	if !ok {
 		return
 	}
@@ -0,0 +1,111 @@
+package lrucache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Thread-safe LRU cache with a fixed capacity, entries expire after ttl.
+type Cache[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	order    *list.List
+	items    map[K]*list.Element
+	stats    Stats
+	// Clock of expiration, replaced in tests.
+	now func() time.Time
+}
+
+// Counters of cache usage since creation.
+type Stats struct {
+	Hits          int64 `json:"hits"`
+	Misses        int64 `json:"misses"`
+	Evictions     int64 `json:"evictions"`
+	Invalidations int64 `json:"invalidations"`
+	Size          int   `json:"size"`
+	Capacity      int   `json:"capacity"`
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// Create new Cache holding at most capacity entries for ttl each.
+func New[K comparable, V any](capacity int, ttl time.Duration) *Cache[K, V] {
+	return &Cache[K, V]{
+		capacity: capacity,
+		ttl:      ttl,
+		order:    list.New(),
+		items:    make(map[K]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// Get a value by key, expired entries are treated as missing.
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, found := c.items[key]
+	if found && c.now().Before(elem.Value.(*entry[K, V]).expiresAt) {
+		c.order.MoveToFront(elem)
+		c.stats.Hits++
+		return elem.Value.(*entry[K, V]).value, true
+	}
+	if found {
+		c.remove(elem)
+	}
+	c.stats.Misses++
+	return
+}
+
+// Set a value by key, evicting the least recently used entry if the cache is full.
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if elem, found := c.items[key]; found {
+		elem.Value = &entry[K, V]{key, value, expiresAt}
+		c.order.MoveToFront(elem)
+		return
+	}
+	if c.capacity <= 0 {
+		return
+	}
+	for c.order.Len() >= c.capacity {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+	c.items[key] = c.order.PushFront(&entry[K, V]{key, value, expiresAt})
+}
+
+// Remove all entries.
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[K]*list.Element)
+	c.stats.Invalidations++
+}
+
+// Get usage counters.
+func (c *Cache[K, V]) Stats() (stats Stats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats = c.stats
+	stats.Size = c.order.Len()
+	stats.Capacity = c.capacity
+	return
+}
+
+func (c *Cache[K, V]) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*entry[K, V]).key)
+}
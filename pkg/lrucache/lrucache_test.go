@@ -0,0 +1,179 @@
+package lrucache
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Operation on a cache: get, set, purge or advance the clock by value seconds.
+type op struct {
+	name  string
+	key   string
+	value int
+}
+
+func get(key string) op            { return op{"get", key, 0} }
+func set(key string, value int) op { return op{"set", key, value} }
+func purge() op                    { return op{"purge", "", 0} }
+func wait(seconds int) op          { return op{"wait", "", seconds} }
+
+func TestCache(t *testing.T) {
+	tests := []struct {
+		name      string
+		capacity  int
+		ops       []op
+		wantGets  []string
+		wantKeys  []string
+		wantStats Stats
+	}{
+		{
+			name:      "get after set",
+			capacity:  2,
+			ops:       []op{set("a", 1), get("a"), get("b")},
+			wantGets:  []string{"a=1", "b miss"},
+			wantKeys:  []string{"a"},
+			wantStats: Stats{Hits: 1, Misses: 1, Size: 1, Capacity: 2},
+		},
+		{
+			name:      "least recently set is evicted",
+			capacity:  2,
+			ops:       []op{set("a", 1), set("b", 2), set("c", 3), get("a"), get("b"), get("c")},
+			wantGets:  []string{"a miss", "b=2", "c=3"},
+			wantKeys:  []string{"c", "b"},
+			wantStats: Stats{Hits: 2, Misses: 1, Evictions: 1, Size: 2, Capacity: 2},
+		},
+		{
+			name:      "get makes an entry recently used",
+			capacity:  2,
+			ops:       []op{set("a", 1), set("b", 2), get("a"), set("c", 3), get("a"), get("b")},
+			wantGets:  []string{"a=1", "a=1", "b miss"},
+			wantKeys:  []string{"a", "c"},
+			wantStats: Stats{Hits: 2, Misses: 1, Evictions: 1, Size: 2, Capacity: 2},
+		},
+		{
+			name:      "set of an existing key replaces it and makes it recently used",
+			capacity:  2,
+			ops:       []op{set("a", 1), set("b", 2), set("a", 10), set("c", 3), get("a"), get("b")},
+			wantGets:  []string{"a=10", "b miss"},
+			wantKeys:  []string{"a", "c"},
+			wantStats: Stats{Hits: 1, Misses: 1, Evictions: 1, Size: 2, Capacity: 2},
+		},
+		{
+			name:      "entries expire after ttl",
+			capacity:  3,
+			ops:       []op{set("a", 1), wait(5), set("b", 2), wait(5), get("a"), get("b"), wait(5), get("b")},
+			wantGets:  []string{"a miss", "b=2", "b miss"},
+			wantKeys:  []string{},
+			wantStats: Stats{Hits: 1, Misses: 2, Size: 0, Capacity: 3},
+		},
+		{
+			name:      "set renews ttl",
+			capacity:  1,
+			ops:       []op{set("a", 1), wait(9), set("a", 2), wait(9), get("a")},
+			wantGets:  []string{"a=2"},
+			wantKeys:  []string{"a"},
+			wantStats: Stats{Hits: 1, Size: 1, Capacity: 1},
+		},
+		{
+			name:      "get does not renew ttl",
+			capacity:  1,
+			ops:       []op{set("a", 1), wait(9), get("a"), wait(1), get("a")},
+			wantGets:  []string{"a=1", "a miss"},
+			wantKeys:  []string{},
+			wantStats: Stats{Hits: 1, Misses: 1, Size: 0, Capacity: 1},
+		},
+		{
+			name:      "purge removes all entries",
+			capacity:  2,
+			ops:       []op{set("a", 1), set("b", 2), purge(), get("a"), set("c", 3), get("c")},
+			wantGets:  []string{"a miss", "c=3"},
+			wantKeys:  []string{"c"},
+			wantStats: Stats{Hits: 1, Misses: 1, Invalidations: 1, Size: 1, Capacity: 2},
+		},
+		{
+			name:      "zero capacity stores nothing",
+			capacity:  0,
+			ops:       []op{set("a", 1), get("a")},
+			wantGets:  []string{"a miss"},
+			wantKeys:  []string{},
+			wantStats: Stats{Misses: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+			c := New[string, int](tt.capacity, 10*time.Second)
+			c.now = func() time.Time { return now }
+
+			gets := []string{}
+			for _, op := range tt.ops {
+				switch op.name {
+				case "get":
+					if value, ok := c.Get(op.key); ok {
+						gets = append(gets, fmt.Sprintf("%s=%d", op.key, value))
+					} else {
+						gets = append(gets, op.key+" miss")
+					}
+				case "set":
+					c.Set(op.key, op.value)
+				case "purge":
+					c.Purge()
+				case "wait":
+					now = now.Add(time.Duration(op.value) * time.Second)
+				}
+			}
+			if !reflect.DeepEqual(gets, tt.wantGets) {
+				t.Errorf("gets = %v, want %v", gets, tt.wantGets)
+			}
+			if keys := keysByRecency(c); !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("keys = %v, want %v", keys, tt.wantKeys)
+			}
+			if stats := c.Stats(); stats != tt.wantStats {
+				t.Errorf("Stats() = %+v, want %+v", stats, tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestCacheConcurrentUse(t *testing.T) {
+	const goroutines, keys, capacity = 20, 50, 10
+	c := New[int, int](capacity, time.Minute)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := (g + i) % keys
+				if value, ok := c.Get(key); ok && value != key*key {
+					t.Errorf("Get(%d) = %d, want %d", key, value, key*key)
+				}
+				c.Set(key, key*key)
+				if i%100 == 0 {
+					c.Purge()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	stats := c.Stats()
+	if stats.Size > capacity || stats.Size != len(c.items) {
+		t.Errorf("size = %d with %d items, want at most %d", stats.Size, len(c.items), capacity)
+	}
+	if stats.Hits+stats.Misses != goroutines*1000 {
+		t.Errorf("hits + misses = %d, want %d", stats.Hits+stats.Misses, goroutines*1000)
+	}
+}
+
+// Keys from the most to the least recently used.
+func keysByRecency(c *Cache[string, int]) []string {
+	keys := []string{}
+	for elem := c.order.Front(); elem != nil; elem = elem.Next() {
+		keys = append(keys, elem.Value.(*entry[string, int]).key)
+	}
+	return keys
+}
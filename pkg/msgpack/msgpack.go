@@ -0,0 +1,452 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Implemented by types that are encoded as another value, e.g. the one they build their JSON from,
+// so that numbers in it keep their Go types.
+type Valuer interface {
+	MsgpackValue() interface{}
+}
+
+var (
+	valuerType        = reflect.TypeOf((*Valuer)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonNumberType    = reflect.TypeOf(json.Number(""))
+)
+
+// Encode a value as MessagePack. The value is encoded as it would be encoded to JSON, so json tags apply,
+// but integers are encoded as integers and floats as float64 whatever their values are.
+// Values of Valuer are encoded instead of the types implementing it. Other JSON marshalers are encoded from
+// their JSON, where numbers written with a fraction or an exponent are floats and other numbers are integers.
+// Map keys are sorted to make the encoding deterministic.
+func Marshal(value interface{}) (data []byte, err error) {
+	buf := &bytes.Buffer{}
+	if err = encodeValue(buf, reflect.ValueOf(value)); err != nil {
+		return
+	}
+	data = buf.Bytes()
+	return
+}
+
+// Encode a Go value following encoding/json rules.
+func encodeValue(buf *bytes.Buffer, v reflect.Value) (err error) {
+	if !v.IsValid() {
+		buf.WriteByte(0xc0)
+		return
+	}
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		buf.WriteByte(0xc0)
+		return
+	}
+	if m, ok := asInterface(v, valuerType); ok {
+		return encodeValue(buf, reflect.ValueOf(m.(Valuer).MsgpackValue()))
+	}
+	if v.Type() == jsonNumberType {
+		return encodeNumber(buf, v.Interface().(json.Number))
+	}
+	if m, ok := asInterface(v, jsonMarshalerType); ok {
+		var encoded []byte
+		if encoded, err = m.(json.Marshaler).MarshalJSON(); err != nil {
+			return
+		}
+		return encodeJSON(buf, encoded)
+	}
+	if m, ok := asInterface(v, textMarshalerType); ok {
+		var text []byte
+		if text, err = m.(encoding.TextMarshaler).MarshalText(); err != nil {
+			return
+		}
+		encodeString(buf, string(text))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		encodeInt(buf, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		encodeUint(buf, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		err = encodeFloat(buf, v.Float())
+	case reflect.String:
+		encodeString(buf, v.String())
+	case reflect.Pointer, reflect.Interface:
+		err = encodeValue(buf, v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			buf.WriteByte(0xc0)
+			return
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			encodeString(buf, base64.StdEncoding.EncodeToString(v.Bytes()))
+			return
+		}
+		err = encodeArray(buf, v)
+	case reflect.Array:
+		err = encodeArray(buf, v)
+	case reflect.Map:
+		err = encodeMap(buf, v)
+	case reflect.Struct:
+		err = encodeStruct(buf, v)
+	default:
+		err = fmt.Errorf("msgpack: unsupported type %s", v.Type())
+	}
+	return
+}
+
+// Get a value as an interface it or, if it is addressable, its pointer implements.
+func asInterface(v reflect.Value, iface reflect.Type) (value interface{}, ok bool) {
+	if v.Type().Implements(iface) {
+		return v.Interface(), true
+	}
+	if v.Kind() != reflect.Pointer && v.CanAddr() && v.Addr().Type().Implements(iface) {
+		return v.Addr().Interface(), true
+	}
+	return
+}
+
+func encodeArray(buf *bytes.Buffer, v reflect.Value) (err error) {
+	encodeLength(buf, v.Len(), 0x90, 0xdc)
+	for i := 0; i < v.Len(); i++ {
+		if err = encodeValue(buf, v.Index(i)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Encode a map with keys converted to strings like encoding/json does.
+func encodeMap(buf *bytes.Buffer, v reflect.Value) (err error) {
+	if v.IsNil() {
+		buf.WriteByte(0xc0)
+		return
+	}
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		var key string
+		if key, err = mapKey(iter.Key()); err != nil {
+			return
+		}
+		entries = append(entries, entry{key, iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+	encodeLength(buf, len(entries), 0x80, 0xde)
+	for _, e := range entries {
+		encodeString(buf, e.key)
+		if err = encodeValue(buf, e.value); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func mapKey(key reflect.Value) (name string, err error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+	if m, ok := asInterface(key, textMarshalerType); ok {
+		text, err := m.(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("msgpack: unsupported map key type %s", key.Type())
+}
+
+func encodeStruct(buf *bytes.Buffer, v reflect.Value) (err error) {
+	fields := structFields(v.Type())
+	values := make([]reflect.Value, 0, len(fields))
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		fieldValue, ok := fieldByIndex(v, field.index)
+		if !ok || (field.omitEmpty && isEmptyValue(fieldValue)) {
+			continue
+		}
+		names = append(names, field.name)
+		values = append(values, fieldValue)
+	}
+	encodeLength(buf, len(values), 0x80, 0xde)
+	for i, value := range values {
+		encodeString(buf, names[i])
+		if err = encodeValue(buf, value); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Get a field of a struct by its index path, ok is false if it is in a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (field reflect.Value, ok bool) {
+	field = v
+	for i, fieldIndex := range index {
+		if i > 0 && field.Kind() == reflect.Pointer {
+			if field.IsNil() {
+				return
+			}
+			field = field.Elem()
+		}
+		field = field.Field(fieldIndex)
+	}
+	return field, true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// Struct field encoded under name.
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	tagged    bool
+}
+
+// Fields of struct types by type.
+var structFieldsCache sync.Map
+
+// Get encoded fields of a struct type in order of declaration. Fields of embedded structs are promoted
+// and, as in encoding/json, a name used by several fields goes to the least nested one,
+// then to the tagged one, and is dropped if that is still ambiguous.
+func structFields(typ reflect.Type) []structField {
+	if fields, ok := structFieldsCache.Load(typ); ok {
+		return fields.([]structField)
+	}
+	var all []structField
+	collectStructFields(typ, nil, map[reflect.Type]bool{}, &all)
+
+	byName := map[string][]structField{}
+	for _, field := range all {
+		byName[field.name] = append(byName[field.name], field)
+	}
+	var fields []structField
+	for _, field := range all {
+		candidates := byName[field.name]
+		dominant, ok := dominantField(candidates)
+		if ok && len(dominant.index) == len(field.index) && equalIndex(dominant.index, field.index) {
+			fields = append(fields, field)
+		}
+	}
+	structFieldsCache.Store(typ, fields)
+	return fields
+}
+
+func collectStructFields(typ reflect.Type, index []int, visited map[reflect.Type]bool, fields *[]structField) {
+	if visited[typ] {
+		return
+	}
+	visited[typ] = true
+	defer delete(visited, typ)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		fieldIndex := append(append([]int(nil), index...), i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			collectStructFields(fieldType, fieldIndex, visited, fields)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		tagged := name != ""
+		if !tagged {
+			name = field.Name
+		}
+		omitEmpty := false
+		for _, option := range strings.Split(options, ",") {
+			omitEmpty = omitEmpty || option == "omitempty"
+		}
+		*fields = append(*fields, structField{name: name, index: fieldIndex, omitEmpty: omitEmpty, tagged: tagged})
+	}
+}
+
+// Choose the field that gets a name used by several fields.
+func dominantField(fields []structField) (dominant structField, ok bool) {
+	depth := len(fields[0].index)
+	for _, field := range fields[1:] {
+		depth = min(depth, len(field.index))
+	}
+	var shallowest, tagged []structField
+	for _, field := range fields {
+		if len(field.index) == depth {
+			shallowest = append(shallowest, field)
+			if field.tagged {
+				tagged = append(tagged, field)
+			}
+		}
+	}
+	switch {
+	case len(shallowest) == 1:
+		return shallowest[0], true
+	case len(tagged) == 1:
+		return tagged[0], true
+	}
+	return
+}
+
+func equalIndex(a, b []int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Encode JSON produced by a marshaler.
+func encodeJSON(buf *bytes.Buffer, encoded []byte) (err error) {
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var generic interface{}
+	if err = decoder.Decode(&generic); err != nil {
+		return
+	}
+	return encodeValue(buf, reflect.ValueOf(generic))
+}
+
+// Encode a JSON number as an integer if it has no fraction or exponent, otherwise as a float.
+func encodeNumber(buf *bytes.Buffer, number json.Number) (err error) {
+	if !strings.ContainsAny(string(number), ".eE") {
+		if i, err := strconv.ParseInt(string(number), 10, 64); err == nil {
+			encodeInt(buf, i)
+			return nil
+		}
+		if u, err := strconv.ParseUint(string(number), 10, 64); err == nil {
+			encodeUint(buf, u)
+			return nil
+		}
+	}
+	f, err := number.Float64()
+	if err != nil {
+		return
+	}
+	return encodeFloat(buf, f)
+}
+
+// Encode a float as float64. Like JSON, MessagePack responses have no NaN and infinities.
+func encodeFloat(buf *bytes.Buffer, f float64) (err error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("msgpack: unsupported value %v", f)
+	}
+	buf.WriteByte(0xcb)
+	binary.Write(buf, binary.BigEndian, math.Float64bits(f))
+	return
+}
+
+// Encode an integer in the shortest format.
+func encodeInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= math.MaxInt8:
+		buf.WriteByte(byte(i))
+	case i < 0 && i >= -32:
+		buf.WriteByte(byte(int8(i)))
+	case i >= 0 && i <= math.MaxUint8:
+		buf.WriteByte(0xcc)
+		buf.WriteByte(byte(i))
+	case i >= 0 && i <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		binary.Write(buf, binary.BigEndian, uint16(i))
+	case i >= 0 && i <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		binary.Write(buf, binary.BigEndian, uint32(i))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		buf.WriteByte(0xd1)
+		binary.Write(buf, binary.BigEndian, int16(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, i)
+	}
+}
+
+// Encode an unsigned integer in the shortest format, those above int64 as uint64.
+func encodeUint(buf *bytes.Buffer, u uint64) {
+	if u <= math.MaxInt64 {
+		encodeInt(buf, int64(u))
+		return
+	}
+	buf.WriteByte(0xcf)
+	binary.Write(buf, binary.BigEndian, u)
+}
+
+func encodeString(buf *bytes.Buffer, s string) {
+	switch n := len(s); {
+	case n <= 31:
+		buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(0xd9)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xda)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdb)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+	buf.WriteString(s)
+}
+
+// Encode length of an array or a map, fix formats hold up to 15 elements, then 16 and 32 bit lengths follow.
+func encodeLength(buf *bytes.Buffer, n int, fixPrefix, prefix16 byte) {
+	switch {
+	case n <= 15:
+		buf.WriteByte(fixPrefix | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(prefix16)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(prefix16 + 1)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
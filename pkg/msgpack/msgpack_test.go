@@ -0,0 +1,445 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Decode data as MessagePack spec describes it. Signed integers are decoded as int64, unsigned ones as uint64,
+// floats as float64, strings as string, binaries as []byte, arrays as []interface{} and maps as map[string]interface{}.
+func decode(t *testing.T, data []byte) interface{} {
+	t.Helper()
+	reader := bytes.NewReader(data)
+	value, err := decodeValue(reader)
+	if err != nil {
+		t.Fatalf("decode error = %v", err)
+	}
+	if reader.Len() != 0 {
+		t.Fatalf("decode left %d trailing bytes", reader.Len())
+	}
+	return value
+}
+
+func decodeValue(reader *bytes.Reader) (value interface{}, err error) {
+	code, err := reader.ReadByte()
+	if err != nil {
+		return
+	}
+	switch {
+	case code <= 0x7f:
+		return int64(code), nil
+	case code >= 0xe0:
+		return int64(int8(code)), nil
+	case code >= 0xa0 && code <= 0xbf:
+		return decodeString(reader, int(code&0x1f))
+	case code >= 0x90 && code <= 0x9f:
+		return decodeArray(reader, int(code&0x0f))
+	case code >= 0x80 && code <= 0x8f:
+		return decodeMap(reader, int(code&0x0f))
+	}
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := readUint(reader, 1<<(code-0xcc))
+		return n, err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (code - 0xd0)
+		n, err := readUint(reader, size)
+		shift := 64 - 8*size
+		return int64(n<<shift) >> shift, err
+	case 0xca:
+		n, err := readUint(reader, 4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err := readUint(reader, 8)
+		return math.Float64frombits(n), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := readUint(reader, 1<<(code-0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return decodeString(reader, int(n))
+	case 0xc4, 0xc5, 0xc6:
+		n, err := readUint(reader, 1<<(code-0xc4))
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, n)
+		_, err = io.ReadFull(reader, data)
+		return data, err
+	case 0xdc, 0xdd:
+		n, err := readUint(reader, 2<<(code-0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return decodeArray(reader, int(n))
+	case 0xde, 0xdf:
+		n, err := readUint(reader, 2<<(code-0xde))
+		if err != nil {
+			return nil, err
+		}
+		return decodeMap(reader, int(n))
+	}
+	return nil, fmt.Errorf("unexpected code %#x", code)
+}
+
+// Read a big-endian unsigned integer of size bytes.
+func readUint(reader *bytes.Reader, size int) (n uint64, err error) {
+	data := make([]byte, size)
+	if _, err = io.ReadFull(reader, data); err != nil {
+		return
+	}
+	for _, b := range data {
+		n = n<<8 | uint64(b)
+	}
+	return
+}
+
+func decodeString(reader *bytes.Reader, n int) (value interface{}, err error) {
+	data := make([]byte, n)
+	_, err = io.ReadFull(reader, data)
+	return string(data), err
+}
+
+func decodeArray(reader *bytes.Reader, n int) (value interface{}, err error) {
+	items := make([]interface{}, n)
+	for i := range items {
+		if items[i], err = decodeValue(reader); err != nil {
+			return
+		}
+	}
+	return items, nil
+}
+
+func decodeMap(reader *bytes.Reader, n int) (value interface{}, err error) {
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		var key, item interface{}
+		if key, err = decodeValue(reader); err != nil {
+			return
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("map key %#v is not a string", key)
+		}
+		if _, ok := m[name]; ok {
+			return nil, fmt.Errorf("duplicate map key %s", name)
+		}
+		if item, err = decodeValue(reader); err != nil {
+			return
+		}
+		m[name] = item
+	}
+	return m, nil
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		wantCode byte
+		wantLen  int
+		want     interface{}
+	}{
+		{"nil", nil, 0xc0, 1, nil},
+		{"false", false, 0xc2, 1, false},
+		{"true", true, 0xc3, 1, true},
+
+		{"zero", 0, 0x00, 1, int64(0)},
+		{"max positive fixint", 127, 0x7f, 1, int64(127)},
+		{"min uint8", 128, 0xcc, 2, uint64(128)},
+		{"max uint8", 255, 0xcc, 2, uint64(255)},
+		{"min uint16", 256, 0xcd, 3, uint64(256)},
+		{"max uint16", math.MaxUint16, 0xcd, 3, uint64(math.MaxUint16)},
+		{"min uint32", math.MaxUint16 + 1, 0xce, 5, uint64(math.MaxUint16 + 1)},
+		{"max uint32", uint32(math.MaxUint32), 0xce, 5, uint64(math.MaxUint32)},
+		{"above uint32", int64(math.MaxUint32 + 1), 0xd3, 9, int64(math.MaxUint32 + 1)},
+		{"max int64", int64(math.MaxInt64), 0xd3, 9, int64(math.MaxInt64)},
+		{"max uint64", uint64(math.MaxUint64), 0xcf, 9, uint64(math.MaxUint64)},
+		{"minus one", -1, 0xff, 1, int64(-1)},
+		{"min negative fixint", -32, 0xe0, 1, int64(-32)},
+		{"below negative fixint", -33, 0xd0, 2, int64(-33)},
+		{"min int8", math.MinInt8, 0xd0, 2, int64(math.MinInt8)},
+		{"below int8", math.MinInt8 - 1, 0xd1, 3, int64(math.MinInt8 - 1)},
+		{"min int16", math.MinInt16, 0xd1, 3, int64(math.MinInt16)},
+		{"below int16", math.MinInt16 - 1, 0xd2, 5, int64(math.MinInt16 - 1)},
+		{"min int32", math.MinInt32, 0xd2, 5, int64(math.MinInt32)},
+		{"below int32", int64(math.MinInt32 - 1), 0xd3, 9, int64(math.MinInt32 - 1)},
+		{"min int64", int64(math.MinInt64), 0xd3, 9, int64(math.MinInt64)},
+
+		{"float", 7.25, 0xcb, 9, 7.25},
+		{"whole float", 8.0, 0xcb, 9, 8.0},
+		{"zero float", 0.0, 0xcb, 9, 0.0},
+		{"negative float", -0.5, 0xcb, 9, -0.5},
+		{"float above uint64", 1e20, 0xcb, 9, 1e20},
+		{"float32", float32(1.5), 0xcb, 9, 1.5},
+		{"uint8", uint8(200), 0xcc, 2, uint64(200)},
+		{"json integer", json.Number("12"), 0x0c, 1, int64(12)},
+		{"json float", json.Number("12.0"), 0xcb, 9, 12.0},
+
+		{"empty string", "", 0xa0, 1, ""},
+		{"max fixstr", strings.Repeat("a", 31), 0xbf, 32, strings.Repeat("a", 31)},
+		{"min str8", strings.Repeat("a", 32), 0xd9, 34, strings.Repeat("a", 32)},
+		{"max str8", strings.Repeat("a", math.MaxUint8), 0xd9, 2 + math.MaxUint8, strings.Repeat("a", math.MaxUint8)},
+		{"min str16", strings.Repeat("a", math.MaxUint8+1), 0xda, 3 + math.MaxUint8 + 1, strings.Repeat("a", math.MaxUint8+1)},
+		{"max str16", strings.Repeat("a", math.MaxUint16), 0xda, 3 + math.MaxUint16, strings.Repeat("a", math.MaxUint16)},
+		{"min str32", strings.Repeat("a", math.MaxUint16+1), 0xdb, 5 + math.MaxUint16 + 1, strings.Repeat("a", math.MaxUint16+1)},
+		{"multibyte string", "фильм", 0xaa, 11, "фильм"},
+		{"escaped string", "<a & b>\n", 0xa8, 9, "<a & b>\n"},
+
+		{"empty array", []int{}, 0x90, 1, []interface{}{}},
+		{"max fixarray", make([]*int, 15), 0x9f, 16, make([]interface{}, 15)},
+		{"min array16", make([]*int, 16), 0xdc, 19, make([]interface{}, 16)},
+		{"min array32", make([]*int, math.MaxUint16+1), 0xdd, 5 + math.MaxUint16 + 1, make([]interface{}, math.MaxUint16+1)},
+		{"nil slice", []int(nil), 0xc0, 1, nil},
+
+		{"empty map", map[string]int{}, 0x80, 1, map[string]interface{}{}},
+		{"max fixmap", intMap(15), 0x8f, 1 + 15*3, decodedIntMap(15)},
+		{"min map16", intMap(16), 0xde, 3 + 16*3, decodedIntMap(16)},
+		{"integer keys", map[int]bool{10: true, 9: false}, 0x82, 8, map[string]interface{}{"10": true, "9": false}},
+		{"nil map", map[string]int(nil), 0xc0, 1, nil},
+
+		{"time", time.Date(2024, 3, 20, 15, 4, 5, 123000000, time.UTC), 0xb8, 25, "2024-03-20T15:04:05.123Z"},
+		{"time with zone", time.Date(2024, 3, 20, 15, 4, 5, 0, time.FixedZone("", 3*60*60)), 0xb9, 26, "2024-03-20T15:04:05+03:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if data[0] != tt.wantCode || len(data) != tt.wantLen {
+				t.Errorf("Marshal() = code %#x, %d bytes, want code %#x, %d bytes", data[0], len(data), tt.wantCode, tt.wantLen)
+			}
+			if got := decode(t, data); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decoded %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+type testActor struct {
+	ID        int               `json:"id"`
+	Name      string            `json:"name"`
+	DeathDate *time.Time        `json:"death_date"`
+	Aliases   []string          `json:"aliases,omitempty"`
+	Links     map[string]string `json:"links"`
+	Hidden    string            `json:"-"`
+}
+
+type testFilm struct {
+	ID        int                    `json:"id"`
+	Title     string                 `json:"title"`
+	Rating    float64                `json:"rating"`
+	Released  time.Time              `json:"released"`
+	Cast      []*testActor           `json:"cast"`
+	Extra     map[string]interface{} `json:"extra"`
+	Sequel    *testFilm              `json:"sequel"`
+	Signature []byte                 `json:"signature"`
+}
+
+func TestMarshalNested(t *testing.T) {
+	film := &testFilm{
+		ID:       1,
+		Title:    "Up",
+		Rating:   8,
+		Released: time.Date(2009, 5, 29, 0, 0, 0, 0, time.UTC),
+		Cast: []*testActor{
+			{ID: 2, Name: "Ed Asner", Links: map[string]string{"imdb": "nm0039142"}, Hidden: "x"},
+			{ID: 3, Name: "Jordan Nagai", Aliases: []string{"J. Nagai"}},
+		},
+		Extra: map[string]interface{}{
+			"budget": 175000000,
+			"awards": map[string]interface{}{"oscar": []interface{}{"Animated Feature", "Score"}, "count": 2},
+			"notes":  nil,
+		},
+		Signature: []byte{1, 2, 3},
+	}
+	want := map[string]interface{}{
+		"id":       int64(1),
+		"title":    "Up",
+		"rating":   float64(8),
+		"released": "2009-05-29T00:00:00Z",
+		"cast": []interface{}{
+			map[string]interface{}{"id": int64(2), "name": "Ed Asner", "death_date": nil, "links": map[string]interface{}{"imdb": "nm0039142"}},
+			map[string]interface{}{"id": int64(3), "name": "Jordan Nagai", "death_date": nil, "aliases": []interface{}{"J. Nagai"}, "links": nil},
+		},
+		"extra": map[string]interface{}{
+			"budget": uint64(175000000),
+			"awards": map[string]interface{}{"oscar": []interface{}{"Animated Feature", "Score"}, "count": int64(2)},
+			"notes":  nil,
+		},
+		"sequel":    nil,
+		"signature": "AQID",
+	}
+
+	data, err := Marshal(film)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if got := decode(t, data); !reflect.DeepEqual(got, want) {
+		t.Errorf("decoded %#v, want %#v", got, want)
+	}
+	again, err := Marshal(film)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Errorf("Marshal() is not deterministic")
+	}
+}
+
+type testBase struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Comment string
+}
+
+type testTagged struct {
+	Name string `json:"Name"`
+}
+
+type testNote struct {
+	Comment string
+}
+
+type testUntagged struct {
+	Name string
+}
+
+type testPoint struct{ x, y int }
+
+func (p testPoint) MsgpackValue() interface{} { return []float64{float64(p.x), float64(p.y)} }
+
+type testLabel string
+
+func (l *testLabel) MarshalJSON() ([]byte, error) { return json.Marshal(strings.ToUpper(string(*l))) }
+
+func TestMarshalStructs(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{
+			name: "embedded fields are promoted and shadowed",
+			value: struct {
+				testBase
+				Name string `json:"name"`
+			}{testBase{ID: 1, Name: "base", Comment: "c"}, "outer"},
+			want: map[string]interface{}{"id": int64(1), "name": "outer", "Comment": "c"},
+		},
+		{
+			name: "tagged field wins at the same depth",
+			value: struct {
+				testTagged
+				testUntagged
+				*testBase
+			}{testTagged{"tagged"}, testUntagged{"untagged"}, nil},
+			want: map[string]interface{}{"Name": "tagged"},
+		},
+		{
+			name: "ambiguous fields are dropped",
+			value: struct {
+				A testTagged `json:"a"`
+				testBase
+				testNote
+			}{A: testTagged{"a"}},
+			want: map[string]interface{}{"a": map[string]interface{}{"Name": "a"}, "id": int64(0), "name": ""},
+		},
+		{
+			name: "omitempty and unexported fields",
+			value: struct {
+				Empty    []int          `json:"empty,omitempty"`
+				Zero     float64        `json:"zero,omitempty"`
+				Nil      *int           `json:"nil,omitempty"`
+				Map      map[string]int `json:"map,omitempty"`
+				Kept     float64        `json:"kept,omitempty"`
+				internal int
+			}{Kept: 2, internal: 1},
+			want: map[string]interface{}{"kept": 2.0},
+		},
+		{
+			name: "valuers and marshalers",
+			value: struct {
+				Point testPoint       `json:"point"`
+				Label testLabel       `json:"label"`
+				Raw   json.RawMessage `json:"raw"`
+				Empty json.RawMessage `json:"empty"`
+			}{testPoint{1, 2}, "up", json.RawMessage(`{"rating":7,"avg":7.0}`), nil},
+			want: map[string]interface{}{
+				"point": []interface{}{1.0, 2.0},
+				"label": "up",
+				"raw":   map[string]interface{}{"rating": int64(7), "avg": 7.0},
+				"empty": nil,
+			},
+		},
+		{
+			name: "addressable values use pointer marshalers",
+			value: &struct {
+				Label testLabel `json:"label"`
+			}{"up"},
+			want: map[string]interface{}{"label": "UP"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if got := decode(t, data); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decoded %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalSortsKeys(t *testing.T) {
+	data, err := Marshal(map[string]int{"b": 2, "c": 3, "a": 1})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := []byte{0x83, 0xa1, 'a', 0x01, 0xa1, 'b', 0x02, 0xa1, 'c', 0x03}
+	if !bytes.Equal(data, want) {
+		t.Errorf("Marshal() = %x, want %x", data, want)
+	}
+}
+
+func TestMarshalJSONErrors(t *testing.T) {
+	if _, err := Marshal(math.Inf(1)); err == nil {
+		t.Errorf("Marshal(+Inf) error = nil, want an error")
+	}
+	if _, err := Marshal(make(chan int)); err == nil {
+		t.Errorf("Marshal(chan) error = nil, want an error")
+	}
+}
+
+// Map of n keys "a", "b"... to their numbers.
+func intMap(n int) map[string]int {
+	m := make(map[string]int, n)
+	for i := 0; i < n; i++ {
+		m[string(rune('a'+i))] = i
+	}
+	return m
+}
+
+func decodedIntMap(n int) map[string]interface{} {
+	m := make(map[string]interface{}, n)
+	for key, value := range intMap(n) {
+		m[key] = int64(value)
+	}
+	return m
+}